@@ -0,0 +1,95 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// builtinAliasesJSON holds the built-in license name rules; users can copy it as the
+// starting point of their own -license-aliases file
+//
+//go:embed license_aliases.json
+var builtinAliasesJSON []byte
+
+// licenseAlias maps a vendor license string to an SPDX identifier, either by exact
+// match or by regular expression
+type licenseAlias struct {
+	Match   string `json:"match,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	License string `json:"license"`
+
+	re *regexp.Regexp
+}
+
+// matches reports whether the rule applies to a license name
+func (a licenseAlias) matches(name string) bool {
+	if a.re != nil {
+		return a.re.MatchString(name)
+	}
+	return a.Match == name
+}
+
+// User rules from the -license-aliases file and the built-in rules, each tried in order
+// with the first match winning
+var (
+	userAliases    []licenseAlias
+	builtinAliases = mustParseAliases(builtinAliasesJSON)
+)
+
+// parseAliases decodes a JSON list of alias rules and compiles their patterns
+func parseAliases(data []byte) ([]licenseAlias, error) {
+	var aliases []licenseAlias
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, err
+	}
+	for i, a := range aliases {
+		if a.License == "" || (a.Match == "") == (a.Pattern == "") {
+			return nil, fmt.Errorf("rule %d needs a license and either match or pattern", i+1)
+		}
+		if a.Pattern != "" {
+			re, err := regexp.Compile(a.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			aliases[i].re = re
+		}
+	}
+	return aliases, nil
+}
+
+// mustParseAliases parses the built-in rules, which are known to be valid
+func mustParseAliases(data []byte) []licenseAlias {
+	aliases, err := parseAliases(data)
+	if err != nil {
+		panic("license_aliases.json: " + err.Error())
+	}
+	return aliases
+}
+
+// loadLicenseAliases reads a user mapping file whose rules take precedence over the
+// built-in ones
+func loadLicenseAliases(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	aliases, err := parseAliases(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	userAliases = aliases
+	return nil
+}
+
+// resolveAlias returns the SPDX identifier of the first rule matching name
+func resolveAlias(aliases []licenseAlias, name string) (string, bool) {
+	for _, a := range aliases {
+		if a.matches(name) {
+			return a.License, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// changeColumnHeader is the column update mode adds to tell what changed in each row
+const changeColumnHeader = "Change"
+
+// Values of the Change column
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+)
+
+// annotator fills gaps in an existing report without touching cells people edited
+type annotator struct {
+	f       *excelize.File
+	sheet   string
+	header  []string       // header of the existing report
+	input   []string       // header of the rows passed to write
+	columns map[string]int // header name -> 0-based column
+	rows    map[string]int // row key -> 1-based sheet row
+	next    int            // next free 1-based sheet row
+
+	// In update mode rows are matched by package name, so new versions and licenses
+	// replace the old ones, and rows of packages no longer used are marked removed
+	update   bool
+	packages map[string][]int // package key -> 1-based sheet rows, in sheet order
+	keys     map[int]string   // 1-based sheet row -> row key
+	written  map[int]bool     // sheet rows written by this run
+}
+
+// annotateRowKey identifies a package row by its name, or else its first column, and
+// its version
+func annotateRowKey(header []string, values []string) string {
+	key := annotatePackageKey(header, values)
+	for i, name := range header {
+		if (name == "PackageVersion" || name == "Version") && i < len(values) {
+			key += "\x00" + values[i]
+			break
+		}
+	}
+	return key
+}
+
+// annotatePackageKey identifies the package of a row whatever its version: its name, or
+// else its first column, within its ecosystem
+func annotatePackageKey(header []string, values []string) string {
+	key := ""
+	if i := slices.Index(header, "Name"); i >= 0 && i < len(values) {
+		key = values[i]
+	} else if len(values) > 0 {
+		key = values[0]
+	}
+	if i := slices.Index(header, "Ecosystem"); i >= 0 && i < len(values) {
+		key = values[i] + "\x00" + key
+	}
+	return key
+}
+
+// openAnnotator opens an existing report and indexes its rows, adding any columns of
+// header the old report does not have yet, and in update mode the Change column
+func openAnnotator(filename string, header []string, update bool) (*annotator, error) {
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &annotator{
+		f:        f,
+		sheet:    f.GetSheetName(0),
+		columns:  make(map[string]int),
+		rows:     make(map[string]int),
+		update:   update,
+		packages: make(map[string][]int),
+		keys:     make(map[int]string),
+		written:  make(map[int]bool),
+	}
+
+	rows, err := f.GetRows(a.sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	// Columns renamed by the -columns file are matched by the column they stand for
+	var existing []string
+	if len(rows) > 0 {
+		for _, label := range rows[0] {
+			existing = append(existing, columnName(label))
+		}
+	}
+	for i, name := range existing {
+		a.columns[name] = i
+	}
+	added := header
+	if update {
+		added = append(slices.Clone(header), changeColumnHeader)
+	}
+	for _, name := range added {
+		if _, ok := a.columns[name]; !ok {
+			col := len(existing)
+			existing = append(existing, name)
+			a.columns[name] = col
+			cell, err := excelize.CoordinatesToCellName(col+1, 1)
+			if err != nil {
+				return nil, err
+			}
+			f.SetCellValue(a.sheet, cell, headerLabel(name))
+		}
+	}
+
+	a.header = existing
+	a.input = header
+
+	for i, row := range rows {
+		if i == 0 {
+			continue
+		}
+		key := annotateRowKey(existing, row)
+		a.rows[key] = i + 1
+		a.keys[i+1] = key
+		packageKey := annotatePackageKey(existing, row)
+		a.packages[packageKey] = append(a.packages[packageKey], i+1)
+	}
+	a.next = max(len(rows), 1) + 1
+
+	return a, nil
+}
+
+// write fills the empty cells of the package's existing row, or appends a new row. In
+// update mode a row of the package at another version or under another license is
+// rewritten with the new values, and the Change column tells what changed.
+func (a *annotator) write(values []interface{}) error {
+	header := a.input
+
+	texts := make([]string, len(values))
+	for i, val := range values {
+		texts[i] = fmt.Sprint(val)
+	}
+
+	// Build the key in the old report's column order
+	existing := make([]string, len(a.header))
+	for i, name := range header {
+		existing[a.columns[name]] = texts[i]
+	}
+	key := annotateRowKey(a.header, existing)
+
+	rowNum, found := a.rows[key]
+	change := ""
+	if a.update {
+		rowNum, found = a.unwrittenRow(annotatePackageKey(a.header, existing), key)
+	}
+	if a.update && found {
+		var err error
+		if change, err = a.changes(rowNum, header, texts); err != nil {
+			return err
+		}
+	}
+	if !found {
+		rowNum = a.next
+		a.next++
+		a.rows[key] = rowNum
+		change = changeAdded
+	}
+	a.written[rowNum] = true
+
+	for i, name := range header {
+		cell, err := excelize.CoordinatesToCellName(a.columns[name]+1, rowNum)
+		if err != nil {
+			return err
+		}
+		if found && change == "" {
+			current, err := a.f.GetCellValue(a.sheet, cell)
+			if err != nil {
+				return err
+			}
+			if current != "" {
+				continue
+			}
+		}
+		if texts[i] != "" {
+			a.f.SetCellValue(a.sheet, cell, values[i])
+		}
+	}
+	if a.update {
+		return a.setChange(rowNum, change)
+	}
+	return nil
+}
+
+// unwrittenRow returns the row of a package this run has not written yet, preferring
+// one of the same version, so each version listed keeps its row
+func (a *annotator) unwrittenRow(packageKey string, key string) (int, bool) {
+	candidates := slices.DeleteFunc(slices.Clone(a.packages[packageKey]), func(rowNum int) bool {
+		return a.written[rowNum]
+	})
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	if i := slices.IndexFunc(candidates, func(rowNum int) bool { return a.keys[rowNum] == key }); i >= 0 {
+		return candidates[i], true
+	}
+	return candidates[0], true
+}
+
+// changes describes how the version and license of a row differ from the new values,
+// e.g. "version 1.0.0 → 1.1.0; license MIT → ISC", or returns "" when neither changed.
+// A license the run could not find does not count as a change.
+func (a *annotator) changes(rowNum int, header []string, texts []string) (string, error) {
+	var changes []string
+	for i, name := range header {
+		var field string
+		switch name {
+		case "Version", "PackageVersion":
+			field = "version"
+		case "License":
+			field = "license"
+		default:
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(a.columns[name]+1, rowNum)
+		if err != nil {
+			return "", err
+		}
+		current, err := a.f.GetCellValue(a.sheet, cell)
+		if err != nil {
+			return "", err
+		}
+		if texts[i] != "" && strings.TrimSpace(current) != texts[i] {
+			changes = append(changes, fmt.Sprintf("%s %s → %s", field, current, texts[i]))
+		}
+	}
+	return strings.Join(changes, "; "), nil
+}
+
+// setChange writes the Change cell of a row
+func (a *annotator) setChange(rowNum int, change string) error {
+	cell, err := excelize.CoordinatesToCellName(a.columns[changeColumnHeader]+1, rowNum)
+	if err != nil {
+		return err
+	}
+	return a.f.SetCellValue(a.sheet, cell, change)
+}
+
+// markRemoved marks the rows of packages the manifest no longer uses in update mode,
+// returning how many there are. The rows stay, with any notes people added to them.
+func (a *annotator) markRemoved() (int, error) {
+	if !a.update {
+		return 0, nil
+	}
+	removed := 0
+	for rowNum := 2; rowNum < a.next; rowNum++ {
+		if a.written[rowNum] {
+			continue
+		}
+		if err := a.setChange(rowNum, changeRemoved); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Approval states tracked for every package+version+license combination
+const (
+	approvalPending  = "pending"
+	approvalApproved = "approved"
+	approvalRejected = "rejected"
+)
+
+// approvalColumns are the review columns appended to every report
+var approvalColumns = []reportColumn{
+	{"Approval Status", func(e *reportEntry) interface{} { return e.Review.Status }},
+	{"Reviewer", func(e *reportEntry) interface{} { return e.Review.Reviewer }},
+	{"Review Date", func(e *reportEntry) interface{} { return e.Review.Date }},
+}
+
+// approval records legal's review decision for one package version under one license
+type approval struct {
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	License  string `json:"license"`
+	Status   string `json:"status"`
+	Reviewer string `json:"reviewer,omitempty"`
+	Date     string `json:"date,omitempty"`
+}
+
+// approvalStore maps approvalKey to the recorded decision
+type approvalStore map[string]approval
+
+// approvalKey identifies a review decision; a license change invalidates the approval
+func approvalKey(name string, version string, license string) string {
+	return name + "\x00" + version + "\x00" + license
+}
+
+// loadApprovals reads the approvals store, returning an empty store if it does not exist yet
+func loadApprovals(filename string) (approvalStore, error) {
+	store := make(approvalStore)
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var approvals []approval
+	if err := json.Unmarshal(data, &approvals); err != nil {
+		return nil, err
+	}
+	for _, a := range approvals {
+		store[approvalKey(a.Package, a.Version, a.License)] = a
+	}
+	return store, nil
+}
+
+// save writes the store sorted by package so diffs stay readable
+func (s approvalStore) save(filename string) error {
+	approvals := make([]approval, 0, len(s))
+	for _, a := range s {
+		approvals = append(approvals, a)
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		if approvals[i].Package != approvals[j].Package {
+			return approvals[i].Package < approvals[j].Package
+		}
+		return approvals[i].Version < approvals[j].Version
+	})
+
+	data, err := json.MarshalIndent(approvals, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// lookup returns the decision recorded for info, or a pending entry if there is none
+func (s approvalStore) lookup(info PackageInfo) approval {
+	if a, ok := s[approvalKey(info.Name, info.Version, info.License)]; ok {
+		return a
+	}
+	return approval{
+		Package: info.Name,
+		Version: info.Version,
+		License: info.License,
+		Status:  approvalPending,
+	}
+}
+
+// record stores a decision, stamping today's date when a reviewer set a new status
+func (s approvalStore) record(a approval) {
+	key := approvalKey(a.Package, a.Version, a.License)
+	previous, ok := s[key]
+	if ok && previous.Status == a.Status {
+		if a.Date == "" {
+			a.Date = previous.Date
+		}
+		if a.Reviewer == "" {
+			a.Reviewer = previous.Reviewer
+		}
+	} else if a.Date == "" && a.Status != approvalPending {
+		a.Date = time.Now().Format("2006-01-02")
+	}
+	s[key] = a
+}
+
+// importReport picks up review decisions entered directly into a previously generated
+// report, so edits made in the spreadsheet are not lost when it is regenerated
+func (s approvalStore) importReport(filename string) error {
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rows, err := readDependencyRows(f)
+	if err != nil || len(rows) < 2 {
+		return err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[columnName(name)] = i
+	}
+	statusCol, ok := columns["Approval Status"]
+	if !ok {
+		return nil
+	}
+
+	cell := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	for _, row := range rows[1:] {
+		if statusCol >= len(row) {
+			continue
+		}
+
+		// npm reports show name@version in the first column
+		name := cell(row, "Module Name (No Version)")
+		if name == "" {
+			name = cell(row, "Name")
+		}
+		if name == "" && len(row) > 0 {
+			name = row[0]
+		}
+		version := cell(row, "Version")
+		if version == "" {
+			version = cell(row, "PackageVersion")
+		}
+
+		// Only decisions are imported, a pending cell must not undo a stored decision
+		status := strings.ToLower(strings.TrimSpace(row[statusCol]))
+		if status != approvalApproved && status != approvalRejected {
+			continue
+		}
+
+		s.record(approval{
+			Package:  name,
+			Version:  version,
+			License:  cell(row, "License"),
+			Status:   status,
+			Reviewer: cell(row, "Reviewer"),
+			Date:     cell(row, "Review Date"),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+	"github.com/ncruces/zenity"
+	"github.com/xuri/excelize/v2"
+)
+
+// auditHeaderLines is how many lines from the top of a file are searched for a header
+const auditHeaderLines = 30
+
+// auditMaxLineLength is the longest line read while looking for a header
+const auditMaxLineLength = 16 << 20
+
+// spdxMarker introduces a license header tag in a source file
+const spdxMarker = "SPDX-License-Identifier:"
+
+// auditSourceExtensions are the file types checked for license headers
+var auditSourceExtensions = map[string]bool{
+	".go": true, ".js": true, ".mjs": true, ".cjs": true, ".jsx": true, ".ts": true, ".tsx": true,
+	".vue": true, ".py": true, ".c": true, ".h": true, ".cc": true, ".cpp": true, ".hpp": true,
+	".java": true, ".kt": true, ".scala": true, ".rs": true, ".cs": true, ".rb": true, ".php": true,
+	".swift": true, ".m": true, ".sh": true, ".css": true, ".scss": true,
+}
+
+// auditSkipDirs are directories holding third-party or generated code
+var auditSkipDirs = map[string]bool{
+	".git": true, "vendor": true, "node_modules": true, "third_party": true, "dist": true,
+	"build": true, "target": true, ".venv": true, "venv": true, "__pycache__": true,
+}
+
+// headerAuditResult is the audit outcome for a single source file
+type headerAuditResult struct {
+	File       string
+	Identifier string
+	Status     string
+}
+
+// readSPDXHeader returns the SPDX-License-Identifier declared near the top of a file,
+// or an error when the file cannot be read
+func readSPDXHeader(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	// Minified and generated files can have lines far longer than the default 64 KB
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), auditMaxLineLength)
+	for i := 0; i < auditHeaderLines && scanner.Scan(); i++ {
+		line := scanner.Text()
+		idx := strings.Index(line, spdxMarker)
+		if idx < 0 {
+			continue
+		}
+		identifier := line[idx+len(spdxMarker):]
+		// Drop trailing block comment closers such as "*/" or "-->"
+		for _, closer := range []string{"*/", "-->", "*)"} {
+			identifier, _, _ = strings.Cut(identifier, closer)
+		}
+		return strings.TrimSpace(identifier), nil
+	}
+	return "", scanner.Err()
+}
+
+// declaredProjectLicense finds the project's own license from its manifests or LICENSE file
+func declaredProjectLicense(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var packageJSON struct {
+			License string `json:"license"`
+		}
+		if json.Unmarshal(data, &packageJSON) == nil && packageJSON.License != "" {
+			return packageJSON.License
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml")); err == nil {
+		var pyProject struct {
+			Project struct {
+				License any `toml:"license"`
+			} `toml:"project"`
+			Tool struct {
+				Poetry struct {
+					License string `toml:"license"`
+				} `toml:"poetry"`
+			} `toml:"tool"`
+		}
+		if toml.Unmarshal(data, &pyProject) == nil {
+			if license, ok := pyProject.Project.License.(string); ok && license != "" {
+				return license
+			}
+			if pyProject.Tool.Poetry.License != "" {
+				return pyProject.Tool.Poetry.License
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !parser.IsLicenseFile(entry.Name()) {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, entry.Name())); err == nil {
+			if license := classifyLicenseText(string(data)); license != "" {
+				return license
+			}
+		}
+	}
+	return ""
+}
+
+// auditHeaders walks the source tree and compares every file's header with the project license
+func auditHeaders(dir string, projectLicense string) ([]headerAuditResult, error) {
+	var results []headerAuditResult
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (auditSkipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !auditSourceExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		identifier, readErr := readSPDXHeader(path)
+		result := headerAuditResult{
+			File:       filepath.ToSlash(rel),
+			Identifier: identifier,
+		}
+		switch {
+		case readErr != nil:
+			result.Status = "unreadable"
+		case result.Identifier == "":
+			result.Status = "missing"
+		case projectLicense != "" && !sameLicenseExpression(result.Identifier, projectLicense):
+			result.Status = "mismatch"
+		default:
+			result.Status = "ok"
+		}
+		results = append(results, result)
+		return nil
+	})
+
+	return results, err
+}
+
+// runHeaderAudit implements the "audit" subcommand: scan the project's own sources
+// for SPDX-License-Identifier headers and write the findings to an Excel report
+func runHeaderAudit(args []string) {
+	flags := flag.NewFlagSet("audit", flag.ExitOnError)
+	license := flags.String("license", "", "expected project license (default: read from package.json, pyproject.toml or LICENSE)")
+	flags.Parse(args)
+
+	// A directory given on the command line runs without any dialog
+	dir := flags.Arg(0)
+	headless = dir != ""
+	if dir == "" {
+		wd, _ := os.Getwd()
+		selected, err := zenity.SelectFile(zenity.Filename(wd), zenity.Directory(), zenity.Title("Select project folder"))
+		if err != nil {
+			// User cancelled
+			os.Exit(1)
+		}
+		dir = selected
+	}
+
+	projectLicense := *license
+	if projectLicense == "" {
+		projectLicense = declaredProjectLicense(dir)
+	}
+
+	results, err := auditHeaders(dir, projectLicense)
+	if err != nil {
+		fatal("Failed to scan source tree: " + err.Error())
+	}
+
+	f := excelize.NewFile()
+	sheetName := f.GetSheetName(0)
+
+	header := []string{"File", "SPDX-License-Identifier", "Expected License", "Status"}
+	f.SetSheetRow(sheetName, "A1", &header)
+
+	missing, mismatched, unreadable := 0, 0, 0
+	for i, result := range results {
+		row := []interface{}{result.File, result.Identifier, projectLicense, result.Status}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		f.SetSheetRow(sheetName, cell, &row)
+		switch result.Status {
+		case "missing":
+			missing++
+		case "mismatch":
+			mismatched++
+		case "unreadable":
+			unreadable++
+		}
+	}
+
+	outName := filepath.Base(filepath.Clean(dir)) + "_headers.xlsx"
+	outName, err = saveWorkbook(f, outName, false)
+	if err != nil {
+		fatal("Failed to save Excel file: " + err.Error())
+	}
+
+	summary := fmt.Sprintf("Header audit written to %s\n%d files checked, %d missing, %d mismatching %q, %d unreadable",
+		outName, len(results), missing, mismatched, projectLicense, unreadable)
+	showInfo("Success", summary)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadSPDXHeaderAfterLongLine(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "bundle.min.js")
+	content := strings.Repeat("x", 200*1024) + "\n// SPDX-License-Identifier: MIT\n"
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	identifier, err := readSPDXHeader(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identifier != "MIT" {
+		t.Errorf("identifier = %q, want MIT", identifier)
+	}
+}
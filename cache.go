@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// cacheDirName is the directory next to the manifest holding the per-dependency cache,
+// meant to be committed so reports are reproducible and changes show up in code review
+const cacheDirName = ".license_fetcher"
+
+// cacheEntry is the content of one cache file
+type cacheEntry struct {
+	Package string      `json:"package"`
+	Version string      `json:"version"`
+	Fetched string      `json:"fetched"`
+	Deep    bool        `json:"deep,omitempty"`
+	Info    PackageInfo `json:"info"`
+}
+
+// userCacheDirName is the directory below the user cache directory (~/.cache on Linux)
+// holding metadata shared by all projects
+const userCacheDirName = "license_fetcher"
+
+// metadataCache stores fetched metadata as one JSON file per dependency:
+// <dir>/<repository type>/<package path>@<version>.json
+type metadataCache struct {
+	dir            string
+	repositoryType string
+	// ttl is how long entries stay valid; zero keeps them forever
+	ttl time.Duration
+}
+
+// newMetadataCache returns the cache kept in dir, the directory of the manifest or
+// the scanned folder
+func newMetadataCache(dir string, repositoryType string) *metadataCache {
+	return &metadataCache{
+		dir:            filepath.Join(dir, cacheDirName),
+		repositoryType: repositoryType,
+	}
+}
+
+// newUserCache returns the cache shared by all projects of the user, whose entries
+// expire after ttl
+func newUserCache(repositoryType string, ttl time.Duration) (*metadataCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &metadataCache{
+		dir:            filepath.Join(dir, userCacheDirName),
+		repositoryType: repositoryType,
+		ttl:            ttl,
+	}, nil
+}
+
+// cacheFileSegment makes a name or version safe to use as a path element on every OS
+func cacheFileSegment(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" || s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
+
+// path returns the cache file of a package; scoped npm names and Go module paths
+// become nested directories
+func (c *metadataCache) path(pkg Package) string {
+	segments := strings.Split(pkg.Path, "/")
+	for i, segment := range segments {
+		segments[i] = cacheFileSegment(segment)
+	}
+	last := len(segments) - 1
+	segments[last] += "@" + cacheFileSegment(strings.ReplaceAll(pkg.Version, "/", "_")) + ".json"
+
+	return filepath.Join(append([]string{c.dir, packageRepositoryType(pkg, c.repositoryType)}, segments...)...)
+}
+
+// load returns the cached metadata of a package, or nil when it is not cached. Entries
+// written without deep scan results do not satisfy a deep run.
+func (c *metadataCache) load(pkg Package, deep bool) *PackageInfo {
+	filename := c.path(pkg)
+	miss := func(reason string) *PackageInfo {
+		slog.Debug("cache miss", "package", pkg.Path, "version", pkg.Version, "file", filename, "reason", reason)
+		return nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return miss("not cached")
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return miss("unreadable entry")
+	}
+	if deep && !entry.Deep {
+		return miss("no deep scan results")
+	}
+	if c.ttl > 0 {
+		fetched, err := time.Parse(time.RFC3339, entry.Fetched)
+		if err != nil || time.Since(fetched) > c.ttl {
+			return miss("expired")
+		}
+	}
+	slog.Debug("cache hit", "package", pkg.Path, "version", pkg.Version, "file", filename, "fetched", entry.Fetched)
+	relinkLicense(&entry.Info)
+	canonicalizeRepositories(&entry.Info)
+	return &entry.Info
+}
+
+// store writes the metadata of a package. Packages whose license could not be
+// determined are not cached so they are retried on the next run.
+func (c *metadataCache) store(pkg Package, info PackageInfo, deep bool) error {
+	if info.License == "" {
+		slog.Debug("not cached", "package", pkg.Path, "version", pkg.Version, "reason", "no license")
+		return nil
+	}
+
+	filename := c.path(pkg)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	entry := cacheEntry{
+		Package: pkg.Path,
+		Version: pkg.Version,
+		Fetched: time.Now().Format("2006-01-02"),
+		Deep:    deep,
+		Info:    info,
+	}
+	// Expiring entries need the time of day; committed ones only change when the
+	// metadata does, so a re-fetch that yields the same metadata keeps the old date
+	if c.ttl > 0 {
+		entry.Fetched = time.Now().Format(time.RFC3339)
+	} else if data, err := os.ReadFile(filename); err == nil {
+		var previous cacheEntry
+		if json.Unmarshal(data, &previous) == nil && reflect.DeepEqual(previous.Info, info) && previous.Deep == deep {
+			return nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
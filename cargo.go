@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// crateVersion is a release in the crates.io API
+type crateVersion struct {
+	Num         string `json:"num"`
+	License     string `json:"license"`
+	PublishedBy *struct {
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	} `json:"published_by"`
+}
+
+// getCratesMetadata fetches license, repository, description and the publishing account,
+// reported as the author, of a crate from the crates.io API. crates.io no longer serves
+// the authors of Cargo.toml.
+func getCratesMetadata(ctx context.Context, pkg *Package) PackageInfo {
+	info := PackageInfo{
+		Name:            pkg.Path,
+		Version:         pkg.Version,
+		ModuleNameNoVer: pkg.Path,
+		RepositoryType:  "cargo",
+		Repository:      pkg.Homepage,
+	}
+	if strings.Contains(pkg.Homepage, "github.com") {
+		info.GitHubURL = pkg.Homepage
+	}
+
+	client := createHTTPClient()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://crates.io/api/v1/crates/"+pkg.Path, nil)
+	if err != nil {
+		return info
+	}
+	// crates.io rejects requests without a User-Agent
+	req.Header.Set("User-Agent", "license_fetcher (https://github.com/jsfaint/license_fetcher)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return info
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return info
+	}
+
+	var crate struct {
+		Crate struct {
+			Description      string `json:"description"`
+			Homepage         string `json:"homepage"`
+			Repository       string `json:"repository"`
+			MaxStableVersion string `json:"max_stable_version"`
+		} `json:"crate"`
+		Versions []crateVersion `json:"versions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&crate); err != nil {
+		return info
+	}
+
+	info.Description = strings.TrimSpace(crate.Crate.Description)
+	if crate.Crate.Repository != "" {
+		info.Repository = crate.Crate.Repository
+	} else if crate.Crate.Homepage != "" {
+		info.Repository = crate.Crate.Homepage
+	}
+	if strings.Contains(info.Repository, "github.com") {
+		info.GitHubURL = info.Repository
+	}
+
+	// Cargo.toml requirements are ranges; without the exact version use the newest release
+	version := cleanVersionString(pkg.Version)
+	selected := slices.IndexFunc(crate.Versions, func(v crateVersion) bool { return v.Num == version })
+	found := selected >= 0
+	if !found {
+		selected = slices.IndexFunc(crate.Versions, func(v crateVersion) bool { return v.Num == crate.Crate.MaxStableVersion })
+	}
+	if selected >= 0 {
+		v := crate.Versions[selected]
+		if v.License != "" {
+			info.License = v.License
+			info.LicenseURL = licenseURL(v.License)
+			info.setLicenseSource("crates.io license field", declaredConfidence(v.License))
+		}
+		if v.PublishedBy != nil {
+			info.Author = v.PublishedBy.Name
+			if info.Author == "" {
+				info.Author = v.PublishedBy.Login
+			}
+		}
+	}
+	info.LatestVersion = crate.Crate.MaxStableVersion
+	info.VersionStatus = versionFound
+	if isPinnedVersion(pkg.Version) && !found {
+		info.VersionStatus = versionMissing
+	}
+	if version == "" {
+		info.Version = crate.Crate.MaxStableVersion
+	}
+
+	info.Copyright = setCopyrightFromLicense(info.License)
+	return info
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Exit codes of -check mode. They are bits, so a run with several kinds of problems
+// exits with their sum, e.g. 6 for policy violations and unknown licenses. Any other
+// error exits with 1.
+const (
+	exitPolicyViolation = 2
+	exitUnknownLicense  = 4
+	exitFetchFailure    = 8
+)
+
+// checkResult collects the problems a -check run gates on
+type checkResult struct {
+	violations []string // incompatible with the project license, or rejected by legal
+	unknown    []string // no recognized license and not approved
+	failures   []string // metadata could not be fetched
+}
+
+// add records the problems of a package: its compatibility verdict and review decision
+func (r *checkResult) add(info PackageInfo, verdict compatibilityVerdict, status string) {
+	name := info.Name + "@" + info.Version
+	switch {
+	case verdict.Verdict == compatibilityFail:
+		r.violations = append(r.violations, name+": "+verdict.Reason)
+	case status == approvalRejected:
+		r.violations = append(r.violations, name+": rejected in review")
+	}
+	if licenseRisk(info.License) == riskUnknown && status != approvalApproved {
+		license := info.License
+		if license == "" {
+			license = "no license found"
+		}
+		r.unknown = append(r.unknown, name+": "+license)
+	}
+}
+
+// exitCode returns the exit code for the problems found, 0 when there are none
+func (r *checkResult) exitCode() int {
+	code := 0
+	if len(r.violations) > 0 {
+		code |= exitPolicyViolation
+	}
+	if len(r.unknown) > 0 {
+		code |= exitUnknownLicense
+	}
+	if len(r.failures) > 0 {
+		code |= exitFetchFailure
+	}
+	return code
+}
+
+// exit prints the problems found to stderr and exits with their code
+func (r *checkResult) exit() {
+	for _, section := range []struct {
+		title    string
+		packages []string
+	}{
+		{"policy violations", r.violations},
+		{"unknown licenses", r.unknown},
+		{"fetch failures", r.failures},
+	} {
+		if len(section.packages) > 0 {
+			fmt.Fprintf(os.Stderr, "check: %d %s:\n  %s\n", len(section.packages), section.title, strings.Join(section.packages, "\n  "))
+		}
+	}
+	code := r.exitCode()
+	if code == 0 {
+		fmt.Fprintln(os.Stderr, "check: passed")
+	}
+	os.Exit(code)
+}
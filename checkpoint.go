@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// checkpointInterval is how often the metadata fetched so far is saved during a run
+const checkpointInterval = 30 * time.Second
+
+// checkpointEntry is one package whose metadata was fetched before the run ended
+type checkpointEntry struct {
+	Ecosystem string         `json:"ecosystem"`
+	Package   string         `json:"package"`
+	Version   string         `json:"version"`
+	Origin    string         `json:"origin"`
+	Info      PackageInfo    `json:"info"`
+	Failures  []fetchFailure `json:"failures,omitempty"`
+}
+
+// checkpoint keeps the metadata fetched by a run in a JSON file next to the report, so
+// a run that is cancelled or crashes can be resumed without fetching it again. The file
+// is removed once the reports are written.
+type checkpoint struct {
+	filename string
+	saved    time.Time
+	// dirty is set when entries were added since the last save
+	dirty bool
+
+	Input    string            `json:"input"`
+	Deep     bool              `json:"deep,omitempty"`
+	Packages []checkpointEntry `json:"packages"`
+}
+
+// checkpointName returns the checkpoint file of a report
+func checkpointName(outName string) string {
+	return strings.TrimSuffix(outName, filepath.Ext(outName)) + ".checkpoint.json"
+}
+
+// newCheckpoint starts an empty checkpoint for a run
+func newCheckpoint(filename string, input string, deep bool) *checkpoint {
+	return &checkpoint{filename: filename, saved: time.Now(), Input: input, Deep: deep}
+}
+
+// loadCheckpoint reads the checkpoint left by an interrupted run, or returns nil when
+// there is none
+func loadCheckpoint(filename string) (*checkpoint, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	cp := &checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	cp.filename = filename
+	cp.saved = time.Now()
+	return cp, nil
+}
+
+// entries returns the saved packages by packageKey
+func (cp *checkpoint) entries() map[string]checkpointEntry {
+	entries := make(map[string]checkpointEntry)
+	for _, entry := range cp.Packages {
+		pkg := Package{Path: entry.Package, Version: entry.Version, RepositoryType: entry.Ecosystem}
+		entries[packageKey(pkg, "")] = entry
+	}
+	return entries
+}
+
+// add records the metadata fetched for a package
+func (cp *checkpoint) add(pkg Package, repositoryType string, origin string, info PackageInfo, failures []fetchFailure) {
+	cp.Packages = append(cp.Packages, checkpointEntry{
+		Ecosystem: packageRepositoryType(pkg, repositoryType),
+		Package:   pkg.Path,
+		Version:   pkg.Version,
+		Origin:    origin,
+		Info:      info,
+		Failures:  failures,
+	})
+	cp.dirty = true
+}
+
+// due reports whether checkpointInterval has passed since the last save
+func (cp *checkpoint) due() bool {
+	return cp.dirty && time.Since(cp.saved) >= checkpointInterval
+}
+
+// save writes the checkpoint through a temporary file, so a crash while saving keeps
+// the previous one
+func (cp *checkpoint) save() error {
+	if !cp.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := createTempFor(cp.filename)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), cp.filename); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	cp.saved = time.Now()
+	cp.dirty = false
+	return nil
+}
+
+// remove deletes the checkpoint of a run that completed
+func (cp *checkpoint) remove() error {
+	if err := os.Remove(cp.filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
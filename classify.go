@@ -0,0 +1,201 @@
+package main
+
+import (
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// customLicense is reported for license texts that do not match a known license closely enough
+const customLicense = "Custom/Other"
+
+// customLicenseThreshold is the confidence below which a license text needs legal review
+const customLicenseThreshold = 0.75
+
+// licenseModifiers are phrases no standard license contains; they usually mean a known
+// license was amended with extra restrictions. They are whole restrictions rather than
+// single words, which standard licenses use too: the GPL lets copies be conveyed
+// "occasionally and noncommercially".
+var licenseModifiers = []string{
+	"commons clause",
+	"shall be used for good, not evil",
+	"for non-commercial purposes",
+	"for noncommercial purposes",
+	"non-commercial use only",
+	"noncommercial use only",
+	"may not be used for commercial",
+	"not be used for commercial",
+	"commercial use is prohibited",
+	"you may not sell",
+	"may not be sold",
+	"must obtain a commercial license",
+	"express written permission of",
+}
+
+// licenseSignature identifies a license by its title or by phrases that must all appear
+// in its text
+type licenseSignature struct {
+	ID string
+	// Titles are the heading of the license and the notices that apply it, one of which
+	// must appear near the start of the text: licenses name others further down, as the
+	// GPL does the AGPL and the MPL every GNU license
+	Titles  []string
+	Phrases []string
+	// Excludes rules out look-alike licenses sharing the same phrases
+	Excludes []string
+	// Markers appear in the complete license text; missing ones mean the file is an
+	// excerpt or a reference to the license rather than the license itself
+	Markers []string
+}
+
+// Marker phrases shared by several licenses
+var (
+	bsdMarkers = []string{
+		"redistributions of source code must retain the above copyright notice",
+		"redistributions in binary form must reproduce the above copyright notice",
+		"this software is provided by the copyright holders and contributors \"as is\"",
+	}
+	gplMarkers = []string{
+		"terms and conditions",
+		"end of terms and conditions",
+		"how to apply these terms to your new",
+	}
+)
+
+// gnuTitles returns the heading of a GNU license version and the notices of files
+// licensed under it, e.g. "gnu general public license" and "3"
+func gnuTitles(name string, version string) []string {
+	return []string{
+		name + " version " + version,
+		name + " as published by the free software foundation, either version " + version,
+		name + " as published by the free software foundation; either version " + version,
+		name + " as published by the free software foundation, version " + version,
+	}
+}
+
+// titleWindow is how far into a normalized license text its title is looked for,
+// leaving room for copyright lines and a short preface above it
+const titleWindow = 1000
+
+// licenseSignatures is ordered from most to least specific: signatures matching titles
+// first, then those matching phrases that other licenses may quote
+var licenseSignatures = []licenseSignature{
+	{ID: "AGPL-3.0", Titles: gnuTitles("gnu affero general public license", "3")},
+	{ID: "LGPL-3.0", Titles: gnuTitles("gnu lesser general public license", "3")},
+	{ID: "LGPL-2.1", Titles: gnuTitles("gnu lesser general public license", "2.1")},
+	{ID: "LGPL-2.0", Titles: gnuTitles("gnu library general public license", "2")},
+	{ID: "GPL-3.0", Titles: gnuTitles("gnu general public license", "3"), Markers: gplMarkers},
+	{ID: "GPL-2.0", Titles: gnuTitles("gnu general public license", "2"), Markers: gplMarkers},
+	{ID: "MPL-2.0", Titles: []string{"mozilla public license version 2.0", "mozilla public license, v. 2.0"}, Markers: []string{"1. definitions", "\"executable form\" means", "exhibit a - source code form license notice"}},
+	{ID: "EPL-2.0", Titles: []string{"eclipse public license - v 2.0", "eclipse public license v 2.0", "eclipse public license v. 2.0", "eclipse public license v2.0"}},
+	{ID: "EPL-1.0", Titles: []string{"eclipse public license - v 1.0", "eclipse public license v 1.0", "eclipse public license v1.0"}},
+	{ID: "Apache-2.0", Titles: []string{"apache license version 2.0"}, Markers: []string{"terms and conditions for use, reproduction, and distribution", "grant of copyright license", "grant of patent license", "end of terms and conditions"}},
+	{ID: "Apache-2.0", Phrases: []string{"licensed under the apache license, version 2.0"}},
+	{ID: "Python-2.0", Phrases: []string{"python software foundation license"}},
+	{ID: "Unlicense", Phrases: []string{"this is free and unencumbered software released into the public domain"}},
+	{ID: "CC0-1.0", Phrases: []string{"cc0 1.0 universal"}},
+	{ID: "BSL-1.0", Phrases: []string{"boost software license"}},
+	{ID: "Zlib", Phrases: []string{"altered source versions must be plainly marked as such"}},
+	{ID: "0BSD", Phrases: []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted"}, Excludes: []string{"above copyright notice"}},
+	{ID: "ISC", Phrases: []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted"}, Markers: []string{"the above copyright notice and this permission notice appear in all copies", "the software is provided \"as is\" and the author disclaims all warranties"}},
+	{ID: "BSD-3-Clause", Phrases: []string{"redistribution and use in source and binary forms", "neither the name"}, Markers: bsdMarkers},
+	{ID: "BSD-2-Clause", Phrases: []string{"redistribution and use in source and binary forms"}, Markers: bsdMarkers},
+	{ID: "MIT", Phrases: []string{"permission is hereby granted, free of charge, to any person obtaining a copy"}, Markers: []string{"the above copyright notice and this permission notice shall be included in all copies or substantial portions of the software", "the software is provided \"as is\", without warranty of any kind"}},
+}
+
+// normalizeLicenseText lowercases text and collapses whitespace and comment markers
+// so phrases match regardless of line wrapping
+func normalizeLicenseText(text string) string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer("#", " ", "*", " ", "//", " ", "’", "'", "“", "\"", "”", "\"").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// classifyLicenseText returns the SPDX identifier of a license text, or "" if unknown
+func classifyLicenseText(text string) string {
+	signature := matchLicenseSignature(normalizeLicenseText(text))
+	if signature == nil {
+		return ""
+	}
+	return signature.ID
+}
+
+// matchLicenseSignature returns the first signature a normalized license text matches
+func matchLicenseSignature(normalized string) *licenseSignature {
+	head := normalized[:min(len(normalized), titleWindow)]
+	for i, signature := range licenseSignatures {
+		matched := len(signature.Titles) == 0
+		for _, title := range signature.Titles {
+			if strings.Contains(head, title) {
+				matched = true
+				break
+			}
+		}
+		for _, phrase := range signature.Phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		for _, exclude := range signature.Excludes {
+			if strings.Contains(normalized, exclude) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return &licenseSignatures[i]
+		}
+	}
+
+	return nil
+}
+
+// classifyLicenseConfidence classifies a license text and tells how confident the match
+// is: 0 when no known license matches, lower for excerpts missing the marker phrases of
+// the complete text and reduced further for every amendment found in the text
+func classifyLicenseConfidence(text string) (string, float64) {
+	normalized := normalizeLicenseText(text)
+	signature := matchLicenseSignature(normalized)
+	if signature == nil {
+		return "", 0
+	}
+
+	// A match on the identifying phrases alone stays above customLicenseThreshold;
+	// the markers only grade how complete the text is
+	confidence := 1.0
+	if len(signature.Markers) > 0 {
+		found := 0
+		for _, marker := range signature.Markers {
+			if strings.Contains(normalized, marker) {
+				found++
+			}
+		}
+		confidence = 0.8 + 0.2*float64(found)/float64(len(signature.Markers))
+	}
+	for _, modifier := range licenseModifiers {
+		if strings.Contains(normalized, modifier) {
+			confidence -= 0.5
+		}
+	}
+	return signature.ID, max(confidence, 0)
+}
+
+// isMainLicenseFile reports whether name is a package's primary license file
+// (LICENSE, LICENCE or COPYING with an optional extension) rather than a notice or
+// a collection of third-party licenses
+func isMainLicenseFile(name string) bool {
+	base := strings.ToUpper(path.Base(name))
+	base = strings.TrimSuffix(base, path.Ext(base))
+	return base == "LICENSE" || base == "LICENCE" || base == "COPYING"
+}
+
+// detectionConfidence renders the confidence of a detected license as a percentage,
+// or "" when deep mode detected nothing
+func detectionConfidence(info PackageInfo) string {
+	if info.DetectedLicense == "" || info.DetectionConfidence == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(math.Round(info.DetectionConfidence*100))) + "%"
+}
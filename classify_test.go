@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestClassifyStandardLicenseTexts classifies the full texts in testdata/licenses,
+// each named after the SPDX identifier it must be recognized as
+func TestClassifyStandardLicenseTexts(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "licenses", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no license texts in testdata/licenses")
+	}
+
+	for _, file := range files {
+		want := strings.TrimSuffix(filepath.Base(file), ".txt")
+		t.Run(want, func(t *testing.T) {
+			text, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, confidence := classifyLicenseConfidence(string(text))
+			if id != want {
+				t.Fatalf("classified as %q (%.2f), want %q", id, confidence, want)
+			}
+			if confidence < customLicenseThreshold {
+				t.Errorf("confidence %.2f is below the threshold %.2f", confidence, customLicenseThreshold)
+			}
+		})
+	}
+}
+
+func TestClassifyLicenseNotices(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "AGPL heading",
+			text: "GNU AFFERO GENERAL PUBLIC LICENSE\nVersion 3, 19 November 2007\n\nCopyright (C) 2007 Free Software Foundation, Inc. <https://fsf.org/>",
+			want: "AGPL-3.0",
+		},
+		{
+			name: "AGPL notice",
+			text: "This program is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or (at your option) any later version.",
+			want: "AGPL-3.0",
+		},
+		{
+			name: "GPL-2.0 notice",
+			text: "This program is free software; you can redistribute it and/or modify it under the terms of the GNU General Public License as published by the Free Software Foundation; either version 2 of the License, or (at your option) any later version.",
+			want: "GPL-2.0",
+		},
+		{
+			name: "LGPL-2.1 notice",
+			text: "This library is free software; you can redistribute it and/or modify it under the terms of the GNU Lesser General Public License as published by the Free Software Foundation; either version 2.1 of the License, or (at your option) any later version.",
+			want: "LGPL-2.1",
+		},
+		{
+			name: "MPL-2.0 notice",
+			text: "This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this file, You can obtain one at http://mozilla.org/MPL/2.0/.",
+			want: "MPL-2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if id, confidence := classifyLicenseConfidence(tt.text); id != tt.want {
+				t.Errorf("classified as %q (%.2f), want %q", id, confidence, tt.want)
+			}
+		})
+	}
+}
+
+// TestClassifyNoncommercialRestriction checks that only a whole restriction lowers the
+// confidence, not the GPL's own "occasionally and noncommercially"
+func TestClassifyNoncommercialRestriction(t *testing.T) {
+	mit, err := os.ReadFile(filepath.Join("testdata", "licenses", "MIT.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	restricted := strings.Replace(string(mit), "to deal\nin the Software", "to deal\nin the Software for non-commercial purposes", 1)
+	if id, confidence := classifyLicenseConfidence(restricted); id == "MIT" && confidence >= customLicenseThreshold {
+		t.Errorf("restricted MIT text classified as %q with confidence %.2f", id, confidence)
+	}
+}
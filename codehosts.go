@@ -0,0 +1,349 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Code hosts other than GitHub whose repositories are read for license files
+const (
+	hostGitLab    = "GitLab"
+	hostBitbucket = "Bitbucket"
+	hostGitee     = "Gitee"
+)
+
+// bitbucketAPIURL is the Bitbucket Cloud REST API endpoint
+const bitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// giteeAPIURL is the Gitee REST API endpoint
+const giteeAPIURL = "https://gitee.com/api/v5"
+
+// repositoryLicenseNames are the license files looked for at the root of a repository
+var repositoryLicenseNames = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+// hostedRepo is a repository on GitLab (gitlab.com or a self-managed gitlab.* instance),
+// Bitbucket Cloud or Gitee
+type hostedRepo struct {
+	host string // hostGitLab, hostBitbucket or hostGitee
+	base string // scheme and host, e.g. https://gitlab.com
+	path string // group/subgroup/project on GitLab, workspace/repo or owner/repo elsewhere
+}
+
+// parseHostedRepo recognizes the GitLab, Bitbucket and Gitee repository of a URL, in any
+// of the forms canonicalRepositoryURL accepts and with pages such as /-/tree/main after it
+func parseHostedRepo(repoURL string) (hostedRepo, bool) {
+	u, err := url.Parse(canonicalRepositoryURL(repoURL))
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return hostedRepo{}, false
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.Trim(u.Path, "/")
+	switch {
+	case host == "bitbucket.org" || host == "www.bitbucket.org" || host == "gitee.com" || host == "www.gitee.com":
+		parts := strings.Split(path, "/")
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return hostedRepo{}, false
+		}
+		repo := parts[0] + "/" + strings.TrimSuffix(parts[1], ".git")
+		if strings.HasSuffix(host, "gitee.com") {
+			return hostedRepo{hostGitee, "https://gitee.com", repo}, true
+		}
+		return hostedRepo{hostBitbucket, "https://bitbucket.org", repo}, true
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		// Projects can be nested in subgroups, so the project path ends where its pages
+		// start: /-/tree/main, or /tree/main and /blob/main on older instances
+		path += "/"
+		for _, page := range []string{"/-/", "/tree/", "/blob/"} {
+			if i := strings.Index(path, page); i >= 0 {
+				path = path[:i+1]
+			}
+		}
+		path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+		if !strings.Contains(path, "/") {
+			return hostedRepo{}, false
+		}
+		return hostedRepo{hostGitLab, u.Scheme + "://" + u.Host, path}, true
+	}
+	return hostedRepo{}, false
+}
+
+// infoHostedRepo returns the GitLab, Bitbucket or Gitee repository of a package
+func infoHostedRepo(info PackageInfo) (hostedRepo, bool) {
+	if repo, ok := parseHostedRepo(info.Repository); ok {
+		return repo, true
+	}
+	return parseHostedRepo(info.GitHubURL)
+}
+
+// isCodeHostURL reports whether a URL is a repository on GitHub, GitLab, Bitbucket or
+// Gitee
+func isCodeHostURL(repoURL string) bool {
+	_, hosted := parseHostedRepo(repoURL)
+	return hosted || githubOwnerRepo(canonicalRepositoryURL(repoURL)) != ""
+}
+
+// rawURL returns the URL of a file of the repository at a branch, tag or commit; HEAD
+// is the default branch
+func (r hostedRepo) rawURL(ref string, name string) string {
+	if r.host == hostGitLab {
+		return r.base + "/" + r.path + "/-/raw/" + ref + "/" + name
+	}
+	return r.base + "/" + r.path + "/raw/" + ref + "/" + name
+}
+
+// fileURL returns the page showing a file of the repository at a branch, tag or commit
+func (r hostedRepo) fileURL(ref string, name string) string {
+	switch r.host {
+	case hostGitLab:
+		return r.base + "/" + r.path + "/-/blob/" + ref + "/" + name
+	case hostGitee:
+		return r.base + "/" + r.path + "/blob/" + ref + "/" + name
+	}
+	return r.base + "/" + r.path + "/src/" + ref + "/" + name
+}
+
+// fetchLicenseFile downloads the first license file found at the root of the
+// repository at ref, returning its name and text, or "" when there is none
+func (r hostedRepo) fetchLicenseFile(ctx context.Context, ref string) (string, string) {
+	for _, name := range repositoryLicenseNames {
+		if text := fetchText(ctx, r.rawURL(ref, name)); text != "" {
+			return name, text
+		}
+	}
+	return "", ""
+}
+
+// gitlabProject is the part of the response of GET /projects/{path}?license=true read
+type gitlabProject struct {
+	LicenseURL string `json:"license_url"`
+	License    *struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"license"`
+}
+
+// fetchGitLabProject asks the GitLab REST API of the repository's instance for the
+// license it detected and the page of its license file. It returns nil without an error when
+// the project is not found or not visible, as private projects are without a token.
+func fetchGitLabProject(ctx context.Context, token string, repo hostedRepo) (*gitlabProject, error) {
+	client := createHTTPClient()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", repo.base+"/api/v4/projects/"+url.PathEscape(repo.path)+"?license=true", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case 200:
+	case 401, 403, 404:
+		return nil, nil
+	case 429:
+		return nil, fmt.Errorf("GitLab API rate limit reached (HTTP %d); set a token with -gitlab-token", resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("GitLab project %s: HTTP %d", repo.path, resp.StatusCode)
+	}
+
+	var project gitlabProject
+	if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// fetchBitbucketMainBranch asks the Bitbucket API for the main branch of a repository,
+// returning "" when the repository is not found or not visible
+func fetchBitbucketMainBranch(ctx context.Context, repo hostedRepo) (string, error) {
+	client := createHTTPClient()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", bitbucketAPIURL+"/repositories/"+repo.path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case 200:
+	case 401, 403, 404:
+		return "", nil
+	default:
+		return "", fmt.Errorf("Bitbucket repository %s: HTTP %d", repo.path, resp.StatusCode)
+	}
+
+	var repository struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+		return "", err
+	}
+	return repository.MainBranch.Name, nil
+}
+
+// giteeRepo is the part of the response of GET /repos/{owner}/{repo} read
+type giteeRepo struct {
+	License       string `json:"license"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// fetchGiteeRepo asks the Gitee REST API for the license chosen for a repository and
+// its default branch. It returns nil without an error when the repository is not found
+// or not visible. Without a token the API allows 60 requests per hour.
+func fetchGiteeRepo(ctx context.Context, token string, repo hostedRepo) (*giteeRepo, error) {
+	client := createHTTPClient()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	requestURL := giteeAPIURL + "/repos/" + repo.path
+	if token != "" {
+		requestURL += "?access_token=" + url.QueryEscape(token)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case 200:
+	case 401, 404:
+		return nil, nil
+	case 403, 429:
+		return nil, fmt.Errorf("Gitee API rate limit reached (HTTP %d); set a token with -gitee-token", resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("Gitee repository %s: HTTP %d", repo.path, resp.StatusCode)
+	}
+
+	var repository giteeRepo
+	if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+		return nil, err
+	}
+	return &repository, nil
+}
+
+// gitlabLicenseID turns the license GitLab detected, a lowercase key such as
+// "apache-2.0" with its name, into an SPDX identifier, or "" when it is none GitLab
+// could tell ("other")
+func gitlabLicenseID(key string, name string) string {
+	for _, signature := range licenseSignatures {
+		if strings.EqualFold(signature.ID, key) {
+			return signature.ID
+		}
+	}
+	if license := standardizeLicense(name); license != name {
+		return license
+	}
+	return ""
+}
+
+// applyHostedLicenses sets the license of every package hosted on GitLab, Bitbucket or
+// Gitee, once per repository, as applyGitHubLicenses does for GitHub: GitLab tells the
+// license it detected in the project and where its license file is, Gitee the license
+// chosen for the repository; Bitbucket has no license detection, so the license file of
+// the main branch is classified locally.
+func applyHostedLicenses(ctx context.Context, gitlabToken string, giteeToken string, infos []PackageInfo, progress func(repo string)) error {
+	byRepo := make(map[hostedRepo][]int)
+	var repos []hostedRepo
+	for i, info := range infos {
+		if infoGitHubRepo(info) != "" {
+			continue
+		}
+		repo, ok := infoHostedRepo(info)
+		if !ok {
+			continue
+		}
+		if _, ok := byRepo[repo]; !ok {
+			repos = append(repos, repo)
+		}
+		byRepo[repo] = append(byRepo[repo], i)
+	}
+
+	for _, repo := range repos {
+		if progress != nil {
+			progress(repo.path)
+		}
+		var spdxID, fileURL, text string
+		switch repo.host {
+		case hostGitLab:
+			project, err := fetchGitLabProject(ctx, gitlabToken, repo)
+			if err != nil {
+				return err
+			}
+			if project == nil || project.LicenseURL == "" {
+				continue
+			}
+			if project.License != nil {
+				spdxID = gitlabLicenseID(project.License.Key, project.License.Name)
+			}
+			fileURL = project.LicenseURL
+			text = fetchText(ctx, strings.Replace(fileURL, "/-/blob/", "/-/raw/", 1))
+		case hostBitbucket:
+			branch, err := fetchBitbucketMainBranch(ctx, repo)
+			if err != nil {
+				return err
+			}
+			if branch == "" {
+				continue
+			}
+			var name string
+			if name, text = repo.fetchLicenseFile(ctx, branch); name == "" {
+				continue
+			}
+			fileURL = repo.fileURL(branch, name)
+		case hostGitee:
+			repository, err := fetchGiteeRepo(ctx, giteeToken, repo)
+			if err != nil {
+				return err
+			}
+			if repository == nil {
+				continue
+			}
+			if repository.License != "" {
+				spdxID = standardizeLicense(repository.License)
+			}
+			branch := cmp.Or(repository.DefaultBranch, "HEAD")
+			var name string
+			if name, text = repo.fetchLicenseFile(ctx, branch); name != "" {
+				fileURL = repo.fileURL(branch, name)
+			}
+		}
+
+		source := repo.host + " license API"
+		if repo.host == hostBitbucket {
+			source = "Bitbucket repository"
+		}
+		for _, i := range byRepo[repo] {
+			applyRepositoryLicense(&infos[i], spdxID, text, fileURL, source, "license file on "+repo.host)
+		}
+	}
+	return nil
+}
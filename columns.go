@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// columnSetting picks one report column by its header, optionally showing it under
+// another header
+type columnSetting struct {
+	Column string `json:"column"`
+	Header string `json:"header,omitempty"`
+}
+
+// headerLabels maps the header of a renamed column to the header shown in the report
+var headerLabels = map[string]string{}
+
+// loadColumnConfig reads a -columns file: a JSON list of the columns to show, in order.
+// Renamed columns are registered at once, so reports written with the file are read
+// back by the column each header stands for.
+func loadColumnConfig(filename string) ([]columnSetting, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var settings []columnSetting
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("no columns listed")
+	}
+	for i, s := range settings {
+		if s.Column == "" {
+			return nil, fmt.Errorf("entry %d needs a column", i+1)
+		}
+		if s.Header != "" && s.Header != s.Column {
+			headerLabels[s.Column] = s.Header
+		}
+	}
+	return settings, nil
+}
+
+// applyColumnConfig keeps the columns the settings list, in their order. It returns
+// them and the listed columns this report does not have, such as those a flag adds.
+func applyColumnConfig(columns []reportColumn, settings []columnSetting) ([]reportColumn, []string) {
+	byHeader := make(map[string]reportColumn, len(columns))
+	for _, col := range columns {
+		byHeader[col.Header] = col
+	}
+
+	var selected []reportColumn
+	var missing []string
+	seen := make(map[string]bool)
+	for _, s := range settings {
+		col, ok := byHeader[s.Column]
+		if !ok {
+			missing = append(missing, s.Column)
+			continue
+		}
+		if seen[s.Column] {
+			continue
+		}
+		seen[s.Column] = true
+		selected = append(selected, col)
+	}
+	return selected, missing
+}
+
+// displayHeader returns the header row as written to the report, with renamed columns
+// under their new headers
+func displayHeader(header []string) []string {
+	labels := make([]string, len(header))
+	for i, name := range header {
+		labels[i] = headerLabel(name)
+	}
+	return labels
+}
+
+// headerLabel returns the header a column is shown under
+func headerLabel(name string) string {
+	if label, ok := headerLabels[name]; ok {
+		return label
+	}
+	return name
+}
+
+// columnName returns the column a header read back from a report stands for, undoing
+// the renaming of the -columns file
+func columnName(label string) string {
+	for name, l := range headerLabels {
+		if l == label {
+			return name
+		}
+	}
+	return label
+}
@@ -0,0 +1,168 @@
+package main
+
+import "strings"
+
+// Compatibility verdicts of a dependency license with the project license, from best
+// to worst. An OR expression takes the best verdict of its choices, an AND expression
+// the worst of its parts.
+const (
+	compatibilityOK      = "compatible"
+	compatibilityReview  = "review"
+	compatibilityUnknown = "unknown"
+	compatibilityFail    = "incompatible"
+)
+
+// compatibilityOrder lists the verdicts from best to worst
+var compatibilityOrder = []string{compatibilityOK, compatibilityReview, compatibilityUnknown, compatibilityFail}
+
+// compatibilityVerdict is the verdict for one license with the reason shown in the report
+type compatibilityVerdict struct {
+	Verdict string
+	Reason  string
+}
+
+// String renders the verdict for the Compatibility column
+func (v compatibilityVerdict) String() string {
+	if v.Reason == "" {
+		return v.Verdict
+	}
+	return v.Verdict + ": " + v.Reason
+}
+
+// compatibilityRank returns the position of a verdict in compatibilityOrder
+func compatibilityRank(verdict string) int {
+	for i, v := range compatibilityOrder {
+		if v == verdict {
+			return i
+		}
+	}
+	return len(compatibilityOrder) - 1
+}
+
+// gplFamily splits a GNU license identifier such as GPL-2.0-or-later, GPL-3.0+ or
+// AGPL-3.0-only into its family (GPL, LGPL or AGPL), major version and whether later
+// versions may be chosen. ok is false for other licenses.
+func gplFamily(license string) (family string, version int, orLater bool, ok bool) {
+	upper := strings.ToUpper(license)
+	for _, f := range []string{"AGPL", "LGPL", "GPL"} {
+		if !strings.HasPrefix(upper, f+"-") {
+			continue
+		}
+		rest := strings.TrimPrefix(upper, f+"-")
+		orLater = strings.HasSuffix(rest, "+") || strings.HasSuffix(rest, "-OR-LATER")
+		switch {
+		case strings.HasPrefix(rest, "2"):
+			version = 2
+		case strings.HasPrefix(rest, "3"):
+			version = 3
+		default:
+			return "", 0, false, false
+		}
+		return f, version, orLater, true
+	}
+	return "", 0, false, false
+}
+
+// gplAllows reports whether code under a GPL or AGPL license of the given version may
+// be combined into a project distributed under project
+func gplAllows(version int, orLater bool, project string) bool {
+	projectFamily, projectVersion, _, ok := gplFamily(project)
+	if !ok || projectFamily == "LGPL" {
+		return false
+	}
+	// GPL-3.0 and AGPL-3.0 section 13 allow combining code under either license
+	return projectVersion == version || (orLater && projectVersion > version)
+}
+
+// singleLicenseCompatibility judges one license of a dependency against the project license
+func singleLicenseCompatibility(license string, project string) compatibilityVerdict {
+	risk := singleLicenseRisk(license)
+	_, projectVersion, projectOrLater, projectIsGPL := gplFamily(project)
+	projectGPL2Only := projectIsGPL && projectVersion == 2 && !projectOrLater && !strings.HasPrefix(strings.ToUpper(project), "LGPL")
+
+	switch risk {
+	case riskUnknown:
+		return compatibilityVerdict{compatibilityUnknown, license + " is not recognized"}
+
+	case riskPermissive:
+		// The patent clauses of Apache-2.0 conflict with GPL-2.0-only
+		if strings.HasPrefix(strings.ToUpper(license), "APACHE-2") && projectGPL2Only {
+			return compatibilityVerdict{compatibilityFail, license + " cannot be combined with GPL-2.0-only"}
+		}
+		return compatibilityVerdict{Verdict: compatibilityOK}
+
+	case riskWeakCopyleft:
+		upper := strings.ToUpper(license)
+		if strings.HasPrefix(upper, "EPL") && projectIsGPL {
+			return compatibilityVerdict{compatibilityFail, license + " is not GPL compatible"}
+		}
+		if family, version, orLater, ok := gplFamily(license); ok && family == "LGPL" && projectIsGPL {
+			// LGPL code may always be relicensed under the corresponding GPL
+			if gplAllows(version, orLater || version == 2, project) {
+				return compatibilityVerdict{Verdict: compatibilityOK}
+			}
+		}
+		if strings.EqualFold(license, project) {
+			return compatibilityVerdict{Verdict: compatibilityOK}
+		}
+		return compatibilityVerdict{compatibilityReview, license + " requires changes to the dependency itself to be shared"}
+	}
+
+	// Strong copyleft: the project must be distributed under a compatible license
+	if family, version, orLater, ok := gplFamily(license); ok {
+		if gplAllows(version, orLater, project) {
+			return compatibilityVerdict{Verdict: compatibilityOK}
+		}
+		return compatibilityVerdict{compatibilityFail, license + " requires the project to be distributed under the " + family}
+	}
+	if strings.EqualFold(license, project) {
+		return compatibilityVerdict{Verdict: compatibilityOK}
+	}
+	return compatibilityVerdict{compatibilityFail, license + " requires the project to be distributed under the same license"}
+}
+
+// evaluateCompatibility judges a parsed license expression
+func evaluateCompatibility(expr *licenseExpression, project string) compatibilityVerdict {
+	if expr.Op == "" {
+		verdict := singleLicenseCompatibility(expr.License, project)
+		// Exceptions such as Classpath-exception-2.0 or GCC-exception-3.1 exist to
+		// allow linking without the copyleft extending to the project
+		if expr.Exception != "" && verdict.Verdict == compatibilityFail {
+			return compatibilityVerdict{compatibilityReview, expr.String() + " may allow linking, check the exception"}
+		}
+		return verdict
+	}
+
+	var result compatibilityVerdict
+	for i, operand := range expr.Operands {
+		verdict := evaluateCompatibility(operand, project)
+		rank, current := compatibilityRank(verdict.Verdict), compatibilityRank(result.Verdict)
+		if i == 0 || (expr.Op == "OR" && rank < current) || (expr.Op == "AND" && rank > current) {
+			result = verdict
+		}
+	}
+	return result
+}
+
+// licenseCompatibility judges whether a dependency license may be used in a project
+// distributed under project, e.g. a GPL-3.0 dependency in an MIT project is not
+func licenseCompatibility(license string, project string) compatibilityVerdict {
+	license = strings.TrimSpace(license)
+	if license == "" {
+		return compatibilityVerdict{compatibilityUnknown, "no license found"}
+	}
+	if strings.Contains(license, customLicense) {
+		return compatibilityVerdict{compatibilityReview, "custom license needs legal review"}
+	}
+	expr, err := parseLicenseExpression(license)
+	if err != nil {
+		return singleLicenseCompatibility(license, project)
+	}
+	return evaluateCompatibility(expr, project)
+}
+
+// isProjectLicense reports whether license can be checked against: a single license
+// identifier the risk table knows
+func isProjectLicense(license string) bool {
+	return spdxIdentifierPattern.MatchString(license) && singleLicenseRisk(license) != riskUnknown
+}
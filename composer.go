@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+)
+
+// getPackagistMetadata fetches license, authors, description and source repository
+// of a Composer package from Packagist, keeping what composer.lock already recorded
+func getPackagistMetadata(ctx context.Context, pkg *Package) PackageInfo {
+	info := getDeclaredMetadata(pkg, "composer")
+	if info.License != "" && info.Repository != "" {
+		return info
+	}
+
+	client := createHTTPClient()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://repo.packagist.org/p2/"+pkg.Path+".json", nil)
+	if err != nil {
+		return info
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return info
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return info
+	}
+
+	var metadata struct {
+		Packages map[string][]map[string]json.RawMessage `json:"packages"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return info
+	}
+	versions := parser.ExpandComposerVersions(metadata.Packages[pkg.Path])
+	if len(versions) == 0 {
+		return info
+	}
+
+	// Versions are listed newest first; requirements fall back to the newest release
+	info.LatestVersion = versions[0].Version
+	selected := versions[0]
+	info.VersionStatus = versionFound
+	if isPinnedVersion(pkg.Version) {
+		info.VersionStatus = versionMissing
+		for _, version := range versions {
+			if strings.TrimPrefix(version.Version, "v") == strings.TrimPrefix(pkg.Version, "v") {
+				selected = version
+				info.VersionStatus = versionFound
+				break
+			}
+		}
+	}
+
+	if info.License == "" && len(selected.License) > 0 {
+		info.License = strings.Join(selected.License, " OR ")
+		info.LicenseURL = licenseURL(info.License)
+		info.setLicenseSource("Packagist license field", declaredConfidence(info.License))
+		info.Copyright = setCopyrightFromLicense(info.License)
+	}
+	if info.Author == "" && len(selected.Authors) > 0 {
+		info.Author = selected.Authors[0].Name
+	}
+	if info.Description == "" {
+		info.Description = selected.Description
+	}
+	if info.Repository == "" {
+		info.Repository = selected.Source.URL
+		if info.Repository == "" {
+			info.Repository = selected.Homepage
+		}
+	}
+	if info.GitHubURL == "" && strings.Contains(info.Repository, "github.com") {
+		info.GitHubURL = info.Repository
+	}
+	if pkg.Version == "" {
+		info.Version = selected.Version
+	}
+	return info
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// dependencyFilesPattern returns where a dependency conventionally lives once
+// vendored or installed, used as the Files/path pattern in copyright files
+func dependencyFilesPattern(info PackageInfo, wildcard string) string {
+	switch info.RepositoryType {
+	case "go":
+		return "vendor/" + info.Name + "/" + wildcard
+	case "npm":
+		return "node_modules/" + info.Name + "/" + wildcard
+	case "pypi":
+		return "site-packages/" + strings.ReplaceAll(strings.ToLower(info.Name), "-", "_") + "/" + wildcard
+	case "upm":
+		return "Packages/" + info.Name + "/" + wildcard
+	default:
+		return info.Name + "/" + wildcard
+	}
+}
+
+// copyrightHolder returns the copyright statement without its "Copyright (c)" prefix,
+// falling back to the author when only the synthetic license placeholder is known
+func copyrightHolder(info PackageInfo) string {
+	copyright := strings.TrimSpace(info.Copyright)
+	lower := strings.ToLower(copyright)
+	if strings.HasPrefix(lower, "copyright") || strings.HasPrefix(lower, "©") || strings.HasPrefix(lower, "(c)") {
+		for _, prefix := range []string{"copyright", "(c)", "©"} {
+			if strings.HasPrefix(strings.ToLower(copyright), prefix) {
+				copyright = strings.TrimSpace(copyright[len(prefix):])
+			}
+		}
+		if copyright != "" {
+			return copyright
+		}
+	}
+	if info.Author != "" {
+		return info.Author
+	}
+	return "unknown"
+}
+
+// dep5License converts an SPDX expression to DEP-5 license syntax
+func dep5License(license string) string {
+	if license == "" {
+		return "UNKNOWN"
+	}
+	license = strings.ReplaceAll(license, " AND ", " and ")
+	license = strings.ReplaceAll(license, " OR ", " or ")
+	return license
+}
+
+// writeDEP5 writes a machine-readable debian/copyright (DEP-5) file covering all dependencies
+func writeDEP5(filename string, upstreamName string, infos []PackageInfo) error {
+	var b strings.Builder
+
+	b.WriteString("Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/\n")
+	b.WriteString("Upstream-Name: " + upstreamName + "\n")
+	b.WriteString("Comment: Third-party dependency licenses generated by license_fetcher\n")
+
+	licenseURLs := make(map[string]string)
+	for _, info := range infos {
+		license := dep5License(info.License)
+		if _, ok := licenseURLs[license]; !ok || licenseURLs[license] == "" {
+			// The paragraph is shared by every package under the license, so it links
+			// the license page rather than one package's license file
+			licenseURLs[license] = licenseURL(info.License)
+		}
+
+		b.WriteString("\n")
+		b.WriteString("Files: " + dependencyFilesPattern(info, "*") + "\n")
+		b.WriteString("Copyright: " + copyrightHolder(info) + "\n")
+		b.WriteString("License: " + license + "\n")
+
+		comment := info.Name
+		if info.Version != "" {
+			comment += " " + info.Version
+		}
+		if info.Repository != "" {
+			comment += ", " + info.Repository
+		} else if info.GitHubURL != "" {
+			comment += ", " + info.GitHubURL
+		}
+		b.WriteString("Comment: " + comment + "\n")
+	}
+
+	// Every license short name used above needs a standalone License paragraph
+	licenses := make([]string, 0, len(licenseURLs))
+	for license := range licenseURLs {
+		licenses = append(licenses, license)
+	}
+	sort.Strings(licenses)
+
+	for _, license := range licenses {
+		b.WriteString("\nLicense: " + license + "\n")
+		if license == "UNKNOWN" {
+			b.WriteString(" The license of these files could not be determined automatically\n")
+			b.WriteString(" and must be reviewed manually.\n")
+		} else if url := licenseURLs[license]; url != "" {
+			b.WriteString(" The full text of this license is available at\n")
+			b.WriteString(" " + url + "\n")
+		} else {
+			b.WriteString(" The full text of this license is shipped with the package.\n")
+		}
+	}
+
+	return os.WriteFile(filename, []byte(b.String()), 0644)
+}
+
+// reuseAnnotation is a single [[annotations]] table of a REUSE.toml file
+type reuseAnnotation struct {
+	Path       string `toml:"path"`
+	Precedence string `toml:"precedence"`
+	Copyright  string `toml:"SPDX-FileCopyrightText"`
+	License    string `toml:"SPDX-License-Identifier"`
+}
+
+// writeREUSE writes a REUSE.toml with one annotation per dependency
+func writeREUSE(filename string, infos []PackageInfo) error {
+	document := struct {
+		Version     int               `toml:"version"`
+		Annotations []reuseAnnotation `toml:"annotations"`
+	}{Version: 1}
+
+	for _, info := range infos {
+		license := info.License
+		if license == "" {
+			license = "NOASSERTION"
+		}
+		document.Annotations = append(document.Annotations, reuseAnnotation{
+			Path:       dependencyFilesPattern(info, "**"),
+			Precedence: "aggregate",
+			Copyright:  copyrightHolder(info),
+			License:    license,
+		})
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := toml.NewEncoder(file).Encode(document); err != nil {
+		return fmt.Errorf("encode %s: %w", filename, err)
+	}
+	return nil
+}
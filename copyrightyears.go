@@ -0,0 +1,118 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// copyrightLinePattern matches copyright statements in license texts, capturing what
+// follows the "Copyright (c)" marker
+var copyrightLinePattern = regexp.MustCompile(`(?im)^[\s#*/;-]*(?:copyright\s*(?:\(c\)|©)?|\(c\)|©)[\s:]*(.*)$`)
+
+// copyrightYearsPattern matches a year or a year range such as 2015-2024 or 2015 - present
+var copyrightYearsPattern = regexp.MustCompile(`(?i)\b((?:19|20)\d{2})(?:\s*[-–—]\s*((?:19|20)\d{2}|present))?\b`)
+
+// copyrightTailPattern matches boilerplate after the holder's name
+var copyrightTailPattern = regexp.MustCompile(`(?i)[.,]?\s*all rights reserved\.?.*$`)
+
+// copyrightPlaceholderHolders are template leftovers that are not real holders
+var copyrightPlaceholderHolders = map[string]bool{
+	"":                                 true,
+	"<year> <copyright holders>":       true,
+	"[yyyy] [name of copyright owner]": true,
+	"{yyyy} {name of copyright owner}": true,
+	"the copyright holders":            true,
+}
+
+// parseCopyrightLine splits a copyright statement into its years and holder
+func parseCopyrightLine(statement string) (int, int, string) {
+	first, last := 0, 0
+	for _, match := range copyrightYearsPattern.FindAllStringSubmatch(statement, -1) {
+		from, _ := strconv.Atoi(match[1])
+		to := from
+		if strings.EqualFold(match[2], "present") {
+			to = time.Now().Year()
+		} else if match[2] != "" {
+			to, _ = strconv.Atoi(match[2])
+		}
+		if first == 0 || from < first {
+			first = from
+		}
+		last = max(last, to)
+	}
+
+	// The holder is what remains once the years and their separators are removed
+	holder := copyrightYearsPattern.ReplaceAllString(statement, "")
+	holder = copyrightTailPattern.ReplaceAllString(holder, "")
+	holder = strings.Trim(strings.Join(strings.Fields(holder), " "), " ,;-–")
+	if !strings.HasSuffix(holder, "Inc.") && !strings.HasSuffix(holder, "Ltd.") && !strings.HasSuffix(holder, "Co.") && !strings.HasSuffix(holder, "Corp.") {
+		holder = strings.TrimRight(holder, ".")
+	}
+	holder = strings.TrimSpace(strings.TrimPrefix(holder, "by "))
+	return first, max(last, first), holder
+}
+
+// formatCopyright renders a normalized "Copyright (c) 2015–2024 Owner" statement
+func formatCopyright(first int, last int, holder string) string {
+	switch {
+	case first == 0:
+		return "Copyright (c) " + holder
+	case last <= first:
+		return "Copyright (c) " + strconv.Itoa(first) + " " + holder
+	default:
+		return "Copyright (c) " + strconv.Itoa(first) + "–" + strconv.Itoa(last) + " " + holder
+	}
+}
+
+// extractCopyright finds the copyright statements of a license text and returns them
+// normalized, merging the years of repeated holders. It returns "" when the text has
+// no statement beyond template placeholders.
+func extractCopyright(text string) string {
+	type span struct{ first, last int }
+	years := make(map[string]*span)
+	var holders []string
+
+	for _, match := range copyrightLinePattern.FindAllStringSubmatch(text, -1) {
+		first, last, holder := parseCopyrightLine(match[1])
+		lower := strings.ToLower(holder)
+		if copyrightPlaceholderHolders[lower] {
+			continue
+		}
+		// Wrapped license prose ("copyright owner or by an individual...") has neither
+		// a year nor a (c) marker
+		marker := strings.ToLower(match[0])
+		if first == 0 && !strings.Contains(marker, "(c)") && !strings.Contains(marker, "©") {
+			continue
+		}
+		// The GPL family carries the FSF's copyright on the license text itself
+		if strings.Contains(lower, "free software foundation") {
+			continue
+		}
+
+		s, ok := years[lower]
+		if !ok {
+			s = &span{}
+			years[lower] = s
+			holders = append(holders, holder)
+		}
+		if first != 0 && (s.first == 0 || first < s.first) {
+			s.first = first
+		}
+		s.last = max(s.last, last)
+	}
+
+	statements := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		s := years[strings.ToLower(holder)]
+		statements = append(statements, formatCopyright(s.first, s.last, holder))
+	}
+	return strings.Join(statements, "; ")
+}
+
+// isCopyrightPlaceholder reports whether info only has the synthetic copyright built
+// from its license, which a real statement should replace
+func isCopyrightPlaceholder(info PackageInfo) bool {
+	return info.Copyright == "" || info.Copyright == setCopyrightFromLicense(info.License)
+}
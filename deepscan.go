@@ -0,0 +1,427 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+	"golang.org/x/mod/module"
+)
+
+// maxArchiveSize caps how much of a package archive deep mode will download
+const maxArchiveSize = 64 << 20
+
+// thirdPartyDirs are directory names that conventionally hold vendored code
+var thirdPartyDirs = map[string]bool{
+	"vendor":       true,
+	"vendored":     true,
+	"third_party":  true,
+	"third-party":  true,
+	"thirdparty":   true,
+	"3rdparty":     true,
+	"external":     true,
+	"node_modules": true,
+	"_vendor":      true,
+}
+
+// archiveFile is a license-relevant file found inside a package archive
+type archiveFile struct {
+	Name string
+	Text string
+}
+
+// deepScanResult summarizes what was found inside a downloaded package archive
+type deepScanResult struct {
+	DetectedLicense string
+	Confidence      float64 // of the weakest license text DetectedLicense was classified from
+	LicenseText     string  // text of a custom license that needs legal review
+	LicenseFile     string  // the top-level license file, e.g. LICENSE.md
+	Copyright       string
+	Notices         []string
+	Vendored        []string
+}
+
+// downloadArchive fetches a package archive, limited to maxArchiveSize bytes
+func downloadArchive(ctx context.Context, archiveURL string) ([]byte, error) {
+	client := createHTTPClient()
+	client.Timeout = 2 * time.Minute
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", archiveURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("download %s: HTTP %d", archiveURL, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize))
+}
+
+// readArchiveFiles extracts LICENSE/COPYING/NOTICE files from a zip or tar.gz archive
+func readArchiveFiles(data []byte) []archiveFile {
+	var files []archiveFile
+
+	if zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		for _, file := range zr.File {
+			if file.FileInfo().IsDir() || !parser.IsLicenseFile(file.Name) {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				continue
+			}
+			text, err := io.ReadAll(io.LimitReader(rc, 1<<20))
+			rc.Close()
+			if err == nil {
+				files = append(files, archiveFile{Name: file.Name, Text: string(text)})
+			}
+		}
+		return files
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		if header.Typeflag != tar.TypeReg || !parser.IsLicenseFile(header.Name) {
+			continue
+		}
+		text, err := io.ReadAll(io.LimitReader(tr, 1<<20))
+		if err == nil {
+			files = append(files, archiveFile{Name: header.Name, Text: string(text)})
+		}
+	}
+
+	return files
+}
+
+// unwrappedArchive is passed as the root of archives that keep their content at the
+// top level, such as NuGet packages and Maven source jars
+const unwrappedArchive = "."
+
+// stripArchiveRoot removes the single top-level directory archives wrap their content in,
+// e.g. "package/" for npm, "<name>-<version>/" for sdists and "<module>@<version>/" for Go
+func stripArchiveRoot(name string, root string) string {
+	if root == unwrappedArchive {
+		return name
+	}
+	if root != "" && strings.HasPrefix(name, root+"/") {
+		return strings.TrimPrefix(name, root+"/")
+	}
+	if _, rest, ok := strings.Cut(name, "/"); ok {
+		return rest
+	}
+	return name
+}
+
+// scanArchive classifies the license texts in an archive, separating the package's own
+// license from those of code vendored into third-party folders
+func scanArchive(data []byte, root string) deepScanResult {
+	return scanLicenseFiles(readArchiveFiles(data), root)
+}
+
+// scanLicenseFiles classifies the license texts of a package, files being named as in
+// its archive
+func scanLicenseFiles(files []archiveFile, root string) deepScanResult {
+	var result deepScanResult
+	own := make(map[string]bool)
+	vendored := make(map[string]map[string]bool)
+
+	for _, file := range files {
+		name := stripArchiveRoot(file.Name, root)
+		dir := path.Dir(name)
+
+		// Locate the innermost third-party folder the file lives under
+		segments := strings.Split(dir, "/")
+		vendorIndex := -1
+		for i, segment := range segments {
+			if thirdPartyDirs[strings.ToLower(segment)] {
+				vendorIndex = i
+			}
+		}
+
+		// The package's own copyright statement is in its top-level LICENSE or NOTICE
+		if vendorIndex < 0 && dir == "." {
+			if copyright := extractCopyright(file.Text); copyright != "" && result.Copyright == "" {
+				result.Copyright = copyright
+			}
+		}
+
+		license, confidence := classifyLicenseConfidence(file.Text)
+		if strings.HasPrefix(strings.ToUpper(path.Base(name)), "NOTICE") {
+			result.Notices = append(result.Notices, name)
+			continue
+		}
+
+		if vendorIndex >= 0 {
+			// Name vendored components by the folder directly below the third-party folder
+			component := strings.Join(segments[:min(vendorIndex+2, len(segments))], "/")
+			if vendored[component] == nil {
+				vendored[component] = make(map[string]bool)
+			}
+			if license != "" {
+				vendored[component][license] = true
+			}
+			continue
+		}
+
+		// A top-level license text that matches no known license closely enough is
+		// custom and must be read by a lawyer
+		if dir == "." && isMainLicenseFile(name) && strings.TrimSpace(file.Text) != "" {
+			if result.LicenseFile == "" {
+				result.LicenseFile = name
+			}
+			if confidence < customLicenseThreshold {
+				license = customLicense
+				if result.LicenseText == "" {
+					result.LicenseText = file.Text
+				}
+			}
+		}
+
+		// Nested license files (e.g. in a sub-package) count only when the root has none
+		if license != "" && (dir == "." || len(own) == 0) {
+			own[license] = true
+			if license != customLicense && (result.Confidence == 0 || confidence < result.Confidence) {
+				result.Confidence = confidence
+			}
+		}
+	}
+
+	result.DetectedLicense = strings.Join(sortedKeys(own), " AND ")
+	for component, licenses := range vendored {
+		license := strings.Join(sortedKeys(licenses), " AND ")
+		if license == "" {
+			license = "Unknown"
+		}
+		result.Vendored = append(result.Vendored, component+": "+license)
+	}
+	sort.Strings(result.Vendored)
+	sort.Strings(result.Notices)
+
+	return result
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// npmTarballPath returns the path below the registry of the tarball of an npm package
+// version
+func npmTarballPath(name string, version string) string {
+	base := name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		base = name[i+1:]
+	}
+	return name + "/-/" + base + "-" + version + ".tgz"
+}
+
+// npmTarballURL builds the registry tarball URL for an npm package version
+func npmTarballURL(name string, version string) string {
+	return registries.npmRegistryURL(name) + npmTarballPath(name, version)
+}
+
+// downloadNPMTarball fetches the tarball of an npm package version from its registries
+// in turn, limited to maxArchiveSize bytes
+func downloadNPMTarball(ctx context.Context, name string, version string) ([]byte, error) {
+	client := createHTTPClient()
+	client.Timeout = 2 * time.Minute
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	resp, err := registryGet(ctx, client, registries.npmRegistryURLs(name), npmTarballPath(name, version))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("download %s@%s: HTTP %d", name, version, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize))
+}
+
+// pypiSdistURL looks up the source distribution (or first wheel) URL of a PyPI release
+func pypiSdistURL(ctx context.Context, name string, version string) string {
+	client := createHTTPClient()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := registryGet(ctx, client, registries.pypiJSONURLs(), name+"/"+version+"/json")
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return ""
+	}
+
+	var release struct {
+		URLs []struct {
+			Packagetype string `json:"packagetype"`
+			URL         string `json:"url"`
+		} `json:"urls"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return ""
+	}
+
+	fallback := ""
+	for _, u := range release.URLs {
+		if u.Packagetype == "sdist" {
+			return u.URL
+		}
+		if fallback == "" {
+			fallback = u.URL
+		}
+	}
+	return fallback
+}
+
+// goModuleZipURL builds the module proxy zip URL for a Go module version
+func goModuleZipURL(modulePath string, version string) string {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return ""
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return ""
+	}
+	proxy := registries.goProxyURL(modulePath)
+	if proxy == "" {
+		return ""
+	}
+	return proxy + "/" + escapedPath + "/@v/" + escapedVersion + ".zip"
+}
+
+// downloadGoModuleZip fetches the zip of a Go module version from its module proxies,
+// limited to maxArchiveSize bytes
+func downloadGoModuleZip(ctx context.Context, modulePath string, version string) ([]byte, error) {
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	client := createHTTPClient()
+	client.Timeout = 2 * time.Minute
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	resp, err := goProxyGet(ctx, client, modulePath, "@v/"+escapedVersion+".zip")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("download %s@%s: HTTP %d", modulePath, version, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize))
+}
+
+// deepScanPackage downloads the package archive and records the locally classified
+// license and vendored third-party code on info
+func deepScanPackage(ctx context.Context, info *PackageInfo) {
+	version := cleanVersionString(exactVersion(*info))
+	var archiveURL, root string
+
+	switch info.RepositoryType {
+	case "npm":
+		archiveURL = npmTarballURL(info.Name, version)
+		root = "package"
+	case "pypi":
+		archiveURL = pypiSdistURL(ctx, info.Name, version)
+	case "go":
+		archiveURL = goModuleZipURL(info.Name, version)
+		root = info.Name + "@" + version
+	case "cargo":
+		archiveURL = "https://static.crates.io/crates/" + info.Name + "/" + info.Name + "-" + version + ".crate"
+		root = info.Name + "-" + version
+	case "nuget":
+		id, lower := strings.ToLower(info.Name), strings.ToLower(version)
+		archiveURL = "https://api.nuget.org/v3-flatcontainer/" + id + "/" + lower + "/" + id + "." + lower + ".nupkg"
+		root = unwrappedArchive
+	case "maven":
+		if groupID, artifactID, ok := strings.Cut(info.Name, ":"); ok {
+			archiveURL = strings.TrimSuffix(mavenPOMURL(groupID, artifactID, version), ".pom") + "-sources.jar"
+			root = unwrappedArchive
+		}
+	}
+	if archiveURL == "" {
+		return
+	}
+
+	// Go modules fall back through the proxies of GOPROXY, npm packages from a mirror
+	// to the public registry
+	var data []byte
+	var err error
+	switch info.RepositoryType {
+	case "go":
+		data, err = downloadGoModuleZip(ctx, info.Name, version)
+	case "npm":
+		data, err = downloadNPMTarball(ctx, info.Name, version)
+	default:
+		data, err = downloadArchive(ctx, archiveURL)
+	}
+	if err != nil {
+		return
+	}
+
+	result := scanArchive(data, root)
+	info.DetectedLicense = result.DetectedLicense
+	info.DetectionConfidence = result.Confidence
+	info.LicenseText = result.LicenseText
+	info.Notices = strings.Join(result.Notices, "; ")
+	info.Vendored = strings.Join(result.Vendored, "; ")
+
+	// Prefer the locally detected license when the registry had none
+	if info.License == "" && result.DetectedLicense != "" {
+		info.License = result.DetectedLicense
+		info.LicenseURL = licenseURL(info.License)
+		info.setLicenseSource("license file in package archive", detectedConfidence(result.Confidence))
+		info.Copyright = setCopyrightFromLicense(info.License)
+		info.setSource("License", "package archive")
+		delete(info.Sources, "Copyright")
+	}
+	if isCopyrightPlaceholder(*info) && result.Copyright != "" {
+		info.Copyright = result.Copyright
+		info.setSource("Copyright", "package archive")
+	}
+}
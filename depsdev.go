@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// depsDevBatchURL is the deps.dev endpoint resolving many package versions per request
+const depsDevBatchURL = "https://api.deps.dev/v3alpha/versionbatch"
+
+// depsDevBatchSize is the maximum number of versions deps.dev accepts per request
+const depsDevBatchSize = 5000
+
+// depsDevSystems maps our repository types to deps.dev package systems
+var depsDevSystems = map[string]string{
+	"go":    "GO",
+	"npm":   "NPM",
+	"pypi":  "PYPI",
+	"maven": "MAVEN",
+	"cargo": "CARGO",
+	"nuget": "NUGET",
+}
+
+// depsDevVersionKey identifies a package version on deps.dev
+type depsDevVersionKey struct {
+	System  string `json:"system"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// depsDevVersion is the part of a deps.dev version record we report on
+type depsDevVersion struct {
+	VersionKey depsDevVersionKey `json:"versionKey"`
+	Licenses   []string          `json:"licenses"`
+	Links      []struct {
+		Label string `json:"label"`
+		URL   string `json:"url"`
+	} `json:"links"`
+}
+
+// depsDevVersionKeyFor builds the lookup key for a package, normalizing the version
+// the way each registry expects
+func depsDevVersionKeyFor(pkg Package, repositoryType string) (depsDevVersionKey, bool) {
+	system, ok := depsDevSystems[repositoryType]
+	if !ok || pkg.Version == "" {
+		return depsDevVersionKey{}, false
+	}
+	// Private modules are unknown to deps.dev, and their names must not leak to it
+	if repositoryType == "go" && registries.isPrivateGoModule(pkg.Path) {
+		return depsDevVersionKey{}, false
+	}
+
+	version := pkg.Version
+	if repositoryType != "go" {
+		version = cleanVersionString(version)
+	}
+	name := pkg.Path
+	if repositoryType == "pypi" {
+		// PyPI names are case and separator insensitive, deps.dev uses the normalized form
+		name = strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+	}
+
+	return depsDevVersionKey{System: system, Name: name, Version: version}, true
+}
+
+// queryDepsDevBatch resolves one batch of version keys, following result pages
+func queryDepsDevBatch(ctx context.Context, keys []depsDevVersionKey) (map[depsDevVersionKey]depsDevVersion, error) {
+	type request struct {
+		VersionKey depsDevVersionKey `json:"versionKey"`
+	}
+	requests := make([]request, len(keys))
+	for i, key := range keys {
+		requests[i] = request{VersionKey: key}
+	}
+
+	client := createHTTPClient()
+	client.Timeout = time.Minute
+
+	found := make(map[depsDevVersionKey]depsDevVersion)
+	pageToken := ""
+	for {
+		body, err := json.Marshal(map[string]any{"requests": requests, "pageToken": pageToken})
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		req, err := http.NewRequestWithContext(ctx, "POST", depsDevBatchURL, bytes.NewReader(body))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		var result struct {
+			Responses []struct {
+				Request struct {
+					VersionKey depsDevVersionKey `json:"versionKey"`
+				} `json:"request"`
+				Version *depsDevVersion `json:"version"`
+			} `json:"responses"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if resp.StatusCode != 200 {
+			err = fmt.Errorf("deps.dev: HTTP %d", resp.StatusCode)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&result)
+		}
+		resp.Body.Close()
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range result.Responses {
+			if r.Version != nil {
+				found[r.Request.VersionKey] = *r.Version
+			}
+		}
+
+		if result.NextPageToken == "" {
+			return found, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
+// depsDevPackageInfo converts a deps.dev version record into report data
+func depsDevPackageInfo(pkg Package, repositoryType string, version depsDevVersion) PackageInfo {
+	info := PackageInfo{
+		Name:            pkg.Path,
+		Version:         version.VersionKey.Version,
+		ModuleNameNoVer: pkg.Path,
+		RepositoryType:  repositoryType,
+		VersionStatus:   versionFound,
+	}
+	if repositoryType == "go" {
+		info.PackageURL = goPackageURL(pkg.Path, info.Version)
+	}
+
+	var licenses []string
+	for _, license := range version.Licenses {
+		if license != "" && license != "non-standard" {
+			licenses = append(licenses, license)
+		}
+	}
+	if len(licenses) > 0 {
+		info.License = strings.Join(licenses, " AND ")
+		info.LicenseURL = licenseURL(info.License)
+		info.setLicenseSource("deps.dev", declaredConfidence(info.License))
+	}
+
+	for _, link := range version.Links {
+		switch link.Label {
+		case "SOURCE_REPO":
+			info.Repository = link.URL
+		case "HOMEPAGE":
+			if info.Repository == "" {
+				info.Repository = link.URL
+			}
+		}
+	}
+	if strings.Contains(strings.ToLower(info.Repository), "github") {
+		info.GitHubURL = info.Repository
+	}
+
+	info.Copyright = setCopyrightFromLicense(info.License)
+
+	return info
+}
+
+// fetchDepsDevBatch resolves all packages through deps.dev in as few requests as possible.
+// The result is indexed like packages; entries deps.dev could not resolve are nil so the
+// caller can fall back to the per-package registry fetchers.
+func fetchDepsDevBatch(ctx context.Context, packages []Package, repositoryType string) ([]*PackageInfo, error) {
+	results := make([]*PackageInfo, len(packages))
+
+	keyIndex := make(map[depsDevVersionKey][]int)
+	var keys []depsDevVersionKey
+	for i, pkg := range packages {
+		key, ok := depsDevVersionKeyFor(pkg, packageRepositoryType(pkg, repositoryType))
+		if !ok {
+			continue
+		}
+		if _, seen := keyIndex[key]; !seen {
+			keys = append(keys, key)
+		}
+		keyIndex[key] = append(keyIndex[key], i)
+	}
+
+	for start := 0; start < len(keys); start += depsDevBatchSize {
+		found, err := queryDepsDevBatch(ctx, keys[start:min(start+depsDevBatchSize, len(keys))])
+		if err != nil {
+			return results, err
+		}
+		for key, version := range found {
+			for _, i := range keyIndex[key] {
+				info := depsDevPackageInfo(packages[i], packageRepositoryType(packages[i], repositoryType), version)
+				results[i] = &info
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// depsDevAPIURL is the deps.dev REST API answering one package or version per request
+const depsDevAPIURL = "https://api.deps.dev/v3"
+
+// getDepsDevJSON decodes the deps.dev resource at path into v. It reports false when
+// the resource does not exist or cannot be read.
+func getDepsDevJSON(ctx context.Context, path string, v any) bool {
+	found, err := getJSON(ctx, createHTTPClient(), depsDevAPIURL+path, v)
+	return err == nil && found
+}
+
+// depsDevPackagePath is the API path of a package; names such as Go module paths and
+// Maven coordinates are escaped into a single path segment
+func depsDevPackagePath(key depsDevVersionKey) string {
+	return "/systems/" + strings.ToLower(key.System) + "/packages/" + url.PathEscape(key.Name)
+}
+
+// getDepsDevVersion looks up a single package version on deps.dev
+func getDepsDevVersion(ctx context.Context, pkg Package, repositoryType string) (PackageInfo, bool) {
+	key, ok := depsDevVersionKeyFor(pkg, repositoryType)
+	if !ok {
+		return PackageInfo{}, false
+	}
+
+	var version depsDevVersion
+	if !getDepsDevJSON(ctx, depsDevPackagePath(key)+"/versions/"+url.PathEscape(key.Version), &version) {
+		return PackageInfo{}, false
+	}
+	return depsDevPackageInfo(pkg, repositoryType, version), true
+}
+
+// depsDevLatestVersion returns the version deps.dev marks as the default of a package,
+// which is the newest release, or "" when the package is unknown
+func depsDevLatestVersion(ctx context.Context, pkg Package, repositoryType string) string {
+	system, ok := depsDevSystems[repositoryType]
+	if !ok {
+		return ""
+	}
+	// The version does not matter for the package lookup, only the normalized name
+	pkg.Version = "0"
+	key, ok := depsDevVersionKeyFor(pkg, repositoryType)
+	if !ok {
+		return ""
+	}
+	key.System = system
+
+	var result struct {
+		Versions []struct {
+			VersionKey depsDevVersionKey `json:"versionKey"`
+			IsDefault  bool              `json:"isDefault"`
+		} `json:"versions"`
+	}
+	if !getDepsDevJSON(ctx, depsDevPackagePath(key), &result) {
+		return ""
+	}
+	for _, version := range result.Versions {
+		if version.IsDefault {
+			return version.VersionKey.Version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// dryRunHeader are the columns of the dependency list -dry-run prints or exports
+var dryRunHeader = []string{"Name", "Version", "Ecosystem", "Scope", "Dependency", "Group", "Workspace", "Project"}
+
+// dryRunPackage is a dependency in the JSON list of -dry-run
+type dryRunPackage struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Ecosystem  string `json:"ecosystem"`
+	Scope      string `json:"scope,omitempty"`
+	Dependency string `json:"dependency"`
+	Group      string `json:"group,omitempty"`
+	Workspace  string `json:"workspace,omitempty"`
+	Project    string `json:"project,omitempty"`
+}
+
+// dryRunRecord describes a parsed package without any fetched metadata
+func dryRunRecord(pkg Package, repositoryType string) dryRunPackage {
+	return dryRunPackage{
+		Name:       pkg.Path,
+		Version:    pkg.Version,
+		Ecosystem:  packageRepositoryType(pkg, repositoryType),
+		Scope:      pkg.Scope,
+		Dependency: dependencyKind(pkg),
+		Group:      pkg.Group,
+		Workspace:  pkg.Workspace,
+		Project:    pkg.Project,
+	}
+}
+
+// values returns the record in the order of dryRunHeader
+func (p dryRunPackage) values() []interface{} {
+	return []interface{}{p.Name, p.Version, p.Ecosystem, p.Scope, p.Dependency, p.Group, p.Workspace, p.Project}
+}
+
+// printDependencies writes the parsed packages to w as an aligned table
+func printDependencies(w io.Writer, packages []Package, repositoryType string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(dryRunHeader, "\t"))
+	for _, pkg := range packages {
+		values := dryRunRecord(pkg, repositoryType).values()
+		cells := make([]string, len(values))
+		for i, value := range values {
+			cells[i] = fmt.Sprint(value)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeDependencies exports the parsed packages to filename, as a JSON array when it
+// ends in .json and as CSV otherwise, returning the name written
+func writeDependencies(filename string, packages []Package, repositoryType string) (string, error) {
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		records := make([]dryRunPackage, 0, len(packages))
+		for _, pkg := range packages {
+			records = append(records, dryRunRecord(pkg, repositoryType))
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return filename, os.WriteFile(filename, append(data, '\n'), 0644)
+	}
+
+	out, err := newCSVExporter(filename, dryRunHeader)
+	if err != nil {
+		return "", err
+	}
+	for _, pkg := range packages {
+		if err := out.write(dryRunRecord(pkg, repositoryType).values()); err != nil {
+			out.abort()
+			return "", err
+		}
+	}
+	return out.close()
+}
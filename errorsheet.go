@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/url"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// errorsSheetName is the sheet listing packages whose metadata could not be fetched
+const errorsSheetName = "Errors"
+
+// noLicenseFound explains a package whose metadata was fetched but declares no license
+const noLicenseFound = "metadata has no license"
+
+// fetchError is one row of the Errors sheet: a failed request made for a package
+type fetchError struct {
+	Package string
+	Version string
+	fetchFailure
+}
+
+// packageFetchErrors explains why no license was found for a package: every failed
+// request made for it, or that its metadata simply declares none
+func packageFetchErrors(pkg Package, failures []fetchFailure) []fetchError {
+	if len(failures) == 0 {
+		return []fetchError{{Package: pkg.Path, Version: pkg.Version, fetchFailure: fetchFailure{Err: noLicenseFound}}}
+	}
+	errs := make([]fetchError, len(failures))
+	for i, failure := range failures {
+		errs[i] = fetchError{Package: pkg.Path, Version: pkg.Version, fetchFailure: failure}
+	}
+	return errs
+}
+
+// failureSource is the host a failed request was sent to, e.g. registry.npmjs.org
+func failureSource(failure fetchFailure) string {
+	if u, err := url.Parse(failure.URL); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+// writeErrorsSheet (re)creates the sheet listing every package left without a license
+// with the source queried, HTTP status and error of each failed request. No sheet is
+// created when there are none.
+func writeErrorsSheet(f *excelize.File, errs []fetchError) error {
+	if idx, _ := f.GetSheetIndex(errorsSheetName); idx >= 0 {
+		if err := f.DeleteSheet(errorsSheetName); err != nil {
+			return err
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	rows := [][]interface{}{{"Package Name", "Version", "Source", "URL", "HTTP Status", "Attempts", "Error"}}
+	for _, e := range errs {
+		var status, attempts interface{}
+		if e.Status != 0 {
+			status = e.Status
+		}
+		if e.Attempts != 0 {
+			attempts = e.Attempts
+		}
+		rows = append(rows, []interface{}{e.Package, e.Version, failureSource(e.fetchFailure), e.URL, status, attempts, e.Err})
+	}
+
+	if _, err := f.NewSheet(errorsSheetName); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(errorsSheetName, cell, &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
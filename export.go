@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/ncruces/zenity"
+)
+
+// Report formats selectable with -format or in the output format dialog
+const (
+	formatXLSX = "xlsx"
+	formatCSV  = "csv"
+	formatBoth = "both"
+)
+
+// exportFormats are the accepted -format values, each with its dialog label
+var exportFormats = []struct {
+	Name  string
+	Label string
+}{
+	{formatXLSX, "Excel workbook (.xlsx)"},
+	{formatCSV, "CSV (.csv)"},
+	{formatBoth, "Excel workbook and CSV"},
+}
+
+// exporter receives the report rows, in the column order of the report header
+type exporter interface {
+	write(values []interface{}) error
+}
+
+// selectExportFormat validates the -format flag, asking for the format when it is not
+// given and dialogs are available; headless runs default to Excel
+func selectExportFormat(format string) (string, error) {
+	if format != "" {
+		for _, f := range exportFormats {
+			if f.Name == format {
+				return format, nil
+			}
+		}
+		return "", fmt.Errorf("unknown report format %q, use xlsx, csv or both", format)
+	}
+	if headless {
+		return formatXLSX, nil
+	}
+
+	labels := make([]string, len(exportFormats))
+	for i, f := range exportFormats {
+		labels[i] = f.Label
+	}
+	choice, err := zenity.List("Report format:", labels, zenity.Title("Output format"), zenity.DefaultItems(labels[0]))
+	if err != nil {
+		return "", err
+	}
+	if i := slices.Index(labels, choice); i >= 0 {
+		return exportFormats[i].Name, nil
+	}
+	return formatXLSX, nil
+}
+
+// csvExporter writes the report rows as comma separated values. The file is written
+// to a temporary name and only replaces filename on close.
+type csvExporter struct {
+	filename string
+	tmp      *os.File
+	w        *csv.Writer
+}
+
+// newCSVExporter starts a CSV report and writes its header row
+func newCSVExporter(filename string, header []string) (*csvExporter, error) {
+	tmp, err := createTempFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	e := &csvExporter{filename: filename, tmp: tmp, w: csv.NewWriter(tmp)}
+	if err := e.w.Write(displayHeader(header)); err != nil {
+		e.abort()
+		return nil, err
+	}
+	return e, nil
+}
+
+// write appends one row
+func (e *csvExporter) write(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, val := range values {
+		record[i] = fmt.Sprint(val)
+	}
+	return e.w.Write(record)
+}
+
+// close flushes the rows and moves the file into place, returning the name written
+func (e *csvExporter) close() (string, error) {
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		e.abort()
+		return "", err
+	}
+	if err := e.tmp.Close(); err != nil {
+		os.Remove(e.tmp.Name())
+		return "", err
+	}
+	return replaceFile(e.tmp.Name(), e.filename, true)
+}
+
+// abort discards the partially written file
+func (e *csvExporter) abort() {
+	e.tmp.Close()
+	os.Remove(e.tmp.Name())
+}
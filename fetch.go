@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Kinds of metadata source, which -fetchers puts in order
+const (
+	fetchRegistry = "registry" // the registry API of the ecosystem
+	fetchScrape   = "scrape"   // web pages of a package index, e.g. pkg.go.dev
+	fetchDepsDev  = "depsdev"  // the deps.dev API
+	fetchDeclared = "declared" // the manifest itself; always tried first
+)
+
+// fetchOrder is the order metadata sources are tried in; kinds left out are skipped
+var fetchOrder = []string{fetchRegistry, fetchScrape, fetchDepsDev}
+
+// parseFetchOrder reads the comma-separated source kinds of -fetchers
+func parseFetchOrder(value string) ([]string, error) {
+	var order []string
+	for _, kind := range strings.Split(value, ",") {
+		kind = strings.TrimSpace(kind)
+		switch kind {
+		case fetchRegistry, fetchScrape, fetchDepsDev:
+			if !slices.Contains(order, kind) {
+				order = append(order, kind)
+			}
+		default:
+			return nil, fmt.Errorf("unknown metadata source %q (expected registry, scrape or depsdev)", kind)
+		}
+	}
+	return order, nil
+}
+
+// MetadataFetcher looks up the metadata of packages from one source
+type MetadataFetcher interface {
+	// Source names the source in the report, e.g. "npm registry"
+	Source() string
+	// Kind is the kind of source -fetchers orders
+	Kind() string
+	// Fetch returns what the source knows about pkg, leaving unknown fields empty; it
+	// gives up on its requests once ctx is cancelled
+	Fetch(ctx context.Context, pkg *Package) PackageInfo
+}
+
+// sourceFetcher adapts a metadata function to MetadataFetcher
+type sourceFetcher struct {
+	source string
+	kind   string
+	fetch  func(context.Context, *Package) PackageInfo
+}
+
+func (f sourceFetcher) Source() string { return f.source }
+func (f sourceFetcher) Kind() string   { return f.kind }
+func (f sourceFetcher) Fetch(ctx context.Context, pkg *Package) PackageInfo {
+	return f.fetch(ctx, pkg)
+}
+
+// localFetch adapts a source that needs no network, such as the manifest itself
+func localFetch(fetch func(*Package) PackageInfo) func(context.Context, *Package) PackageInfo {
+	return func(_ context.Context, pkg *Package) PackageInfo { return fetch(pkg) }
+}
+
+// depsDevFetcher looks up packages of an ecosystem on deps.dev
+func depsDevFetcher(repositoryType string) MetadataFetcher {
+	return sourceFetcher{"deps.dev", fetchDepsDev, func(ctx context.Context, pkg *Package) PackageInfo {
+		info, _ := getDepsDevVersion(ctx, *pkg, repositoryType)
+		return info
+	}}
+}
+
+// fetcherChain tries the sources of an ecosystem in order until one knows the license,
+// each filling the fields the ones before it left empty
+type fetcherChain struct {
+	repositoryType string
+	fetchers       []MetadataFetcher
+}
+
+// ecosystemFetchers are the metadata sources of each ecosystem
+var ecosystemFetchers = map[string]fetcherChain{
+	"go": {"go", []MetadataFetcher{
+		sourceFetcher{"Go proxy", fetchRegistry, getGoModMetadata},
+		sourceFetcher{"pkg.go.dev", fetchScrape, getPkgGoDevMetadata},
+		// Ranked with pkg.go.dev but after it: the classifier reading the zip is the
+		// fallback for modules pkg.go.dev does not know
+		sourceFetcher{"Go module zip", fetchScrape, getGoModuleZipMetadata},
+		depsDevFetcher("go"),
+	}},
+	"npm":      {"npm", []MetadataFetcher{sourceFetcher{"npm registry", fetchRegistry, getNPMMetadata}, depsDevFetcher("npm")}},
+	"pypi":     {"pypi", []MetadataFetcher{sourceFetcher{"PyPI", fetchRegistry, getPyPI_Metadata}, depsDevFetcher("pypi")}},
+	"cargo":    {"cargo", []MetadataFetcher{sourceFetcher{"crates.io", fetchRegistry, getCratesMetadata}, depsDevFetcher("cargo")}},
+	"maven":    {"maven", []MetadataFetcher{sourceFetcher{"Maven Central", fetchRegistry, getMavenMetadata}, depsDevFetcher("maven")}},
+	"nuget":    {"nuget", []MetadataFetcher{sourceFetcher{"NuGet", fetchRegistry, getNuGetMetadata}, depsDevFetcher("nuget")}},
+	"rubygems": {"rubygems", []MetadataFetcher{sourceFetcher{"RubyGems", fetchRegistry, getRubyGemsMetadata}, depsDevFetcher("rubygems")}},
+	"composer": {"composer", []MetadataFetcher{sourceFetcher{"Packagist", fetchRegistry, getPackagistMetadata}}},
+	"upm":      {"upm", []MetadataFetcher{sourceFetcher{"Unity registry", fetchRegistry, getUPMMetadata}}},
+	"yocto":    {"yocto", []MetadataFetcher{sourceFetcher{"manifest", fetchDeclared, localFetch(getYoctoMetadata)}}},
+	"deb":      {"deb", []MetadataFetcher{sourceFetcher{"dpkg status", fetchDeclared, localFetch(getDpkgMetadata)}}},
+	"apk":      {"apk", []MetadataFetcher{sourceFetcher{"apk database", fetchDeclared, localFetch(getApkMetadata)}}},
+}
+
+// fetchersFor returns the chain of an ecosystem; ecosystems without a registry, such as
+// those of parsers registered by library users, report what their manifests declare
+func fetchersFor(repositoryType string) fetcherChain {
+	if chain, ok := ecosystemFetchers[repositoryType]; ok {
+		return chain
+	}
+	return fetcherChain{repositoryType, []MetadataFetcher{sourceFetcher{"manifest", fetchDeclared, localFetch(func(pkg *Package) PackageInfo {
+		return getDeclaredMetadata(pkg, repositoryType)
+	})}}}
+}
+
+// ordered returns the fetchers in the order of fetchOrder, leaving out the kinds it skips
+func (c fetcherChain) ordered() []MetadataFetcher {
+	rank := func(f MetadataFetcher) int {
+		if f.Kind() == fetchDeclared {
+			return -1
+		}
+		return slices.Index(fetchOrder, f.Kind())
+	}
+	// The copy installed in the project, if any, comes before every other source
+	fetchers := []MetadataFetcher{installedFetcher(c.repositoryType)}
+	for _, f := range c.fetchers {
+		if f.Kind() == fetchDeclared || rank(f) >= 0 {
+			fetchers = append(fetchers, f)
+		}
+	}
+	slices.SortStableFunc(fetchers, func(a, b MetadataFetcher) int { return rank(a) - rank(b) })
+	return fetchers
+}
+
+// fetch looks pkg up in each source until one knows its license
+func (c fetcherChain) fetch(ctx context.Context, pkg *Package) PackageInfo {
+	var info PackageInfo
+	var tried []string
+	for _, f := range c.ordered() {
+		if ctx.Err() != nil {
+			break
+		}
+		found := f.Fetch(ctx, pkg)
+		tried = append(tried, f.Source())
+		slog.Debug("fetched metadata", "package", pkg.Path, "version", pkg.Version, "source", f.Source(), "license", found.License)
+		mergeInfo(&info, found, f.Source())
+		// The installed copy takes precedence but does not end the chain: only the
+		// registry tells whether the version is published, deprecated or outdated
+		if info.License != "" && f.Source() != installedSource {
+			break
+		}
+	}
+	if info.License == "" {
+		slog.Info("no license found", "package", pkg.Path, "version", pkg.Version, "sources", strings.Join(tried, ", "))
+	}
+	if info.Name == "" {
+		info.Name, info.Version = pkg.Path, pkg.Version
+		info.RepositoryType = c.repositoryType
+	}
+	if info.ModuleNameNoVer == "" {
+		info.ModuleNameNoVer = pkg.Path
+	}
+	return info
+}
+
+// sourcedFields are the report columns whose source is recorded, with their value
+var sourcedFields = []struct {
+	name  string
+	value func(info *PackageInfo) *string
+}{
+	{"License", func(info *PackageInfo) *string { return &info.License }},
+	{"Author", func(info *PackageInfo) *string { return &info.Author }},
+	{"Description", func(info *PackageInfo) *string { return &info.Description }},
+	{"Copyright", func(info *PackageInfo) *string { return &info.Copyright }},
+	{"Repository", func(info *PackageInfo) *string { return &info.Repository }},
+	{"GitHub URL", func(info *PackageInfo) *string { return &info.GitHubURL }},
+	{"Package URL", func(info *PackageInfo) *string { return &info.PackageURL }},
+}
+
+// setSource records that source supplied a field of the report
+func (info *PackageInfo) setSource(field string, source string) {
+	if info.Sources == nil {
+		info.Sources = make(map[string]string)
+	}
+	info.Sources[field] = source
+}
+
+// mergeInfo fills the fields of dst that are still empty from what source returned. A
+// copyright made up from the license only counts as empty, and follows a license filled in.
+func mergeInfo(dst *PackageInfo, src PackageInfo, source string) {
+	canonicalizeRepositories(&src)
+	if dst.Name == "" && src.Name != "" {
+		sources := dst.Sources
+		*dst = src
+		dst.Sources = sources
+		for _, field := range sourcedFields {
+			if *field.value(dst) != "" && (field.name != "Copyright" || !isCopyrightPlaceholder(*dst)) {
+				dst.setSource(field.name, source)
+			}
+		}
+		return
+	}
+
+	placeholder := isCopyrightPlaceholder(*dst)
+	for _, field := range sourcedFields {
+		if field.name == "Copyright" {
+			continue
+		}
+		if value := *field.value(&src); *field.value(dst) == "" && value != "" {
+			*field.value(dst) = value
+			dst.setSource(field.name, source)
+			if field.name == "License" {
+				dst.LicenseURL = src.LicenseURL
+				dst.setLicenseSource(src.LicenseSource, src.LicenseConfidence)
+			}
+		}
+	}
+	if placeholder {
+		if !isCopyrightPlaceholder(src) {
+			dst.Copyright = src.Copyright
+			dst.setSource("Copyright", source)
+		} else {
+			dst.Copyright = setCopyrightFromLicense(dst.License)
+		}
+	}
+
+	if dst.VersionStatus == "" {
+		dst.VersionStatus = src.VersionStatus
+	}
+	if dst.LatestVersion == "" {
+		dst.LatestVersion = src.LatestVersion
+	}
+	if dst.ResolvedVersion == "" {
+		dst.ResolvedVersion = src.ResolvedVersion
+	}
+	if dst.Deprecation == "" {
+		dst.Deprecation = src.Deprecation
+	}
+	if dst.LicenseText == "" {
+		dst.LicenseText = src.LicenseText
+	}
+}
+
+// sourcesSummary lists the sources of a package's fields, e.g.
+// "npm registry: License, Author; deps.dev: Repository"
+func sourcesSummary(info PackageInfo) string {
+	var sources []string
+	fields := make(map[string][]string)
+	for _, field := range sourcedFields {
+		source, ok := info.Sources[field.name]
+		if !ok {
+			continue
+		}
+		if _, seen := fields[source]; !seen {
+			sources = append(sources, source)
+		}
+		fields[source] = append(fields[source], field.name)
+	}
+	parts := make([]string, len(sources))
+	for i, source := range sources {
+		parts[i] = source + ": " + strings.Join(fields[source], ", ")
+	}
+	return strings.Join(parts, "; ")
+}
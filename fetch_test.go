@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetcherChainMergesInstalledCopyWithRegistry(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"name": "left-pad", "version": "1.3.0", "license": "WTFPL"}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := sourceFetcher{"npm registry", fetchRegistry, func(_ context.Context, pkg *Package) PackageInfo {
+		return PackageInfo{
+			Name:           pkg.Path,
+			Version:        pkg.Version,
+			License:        "MIT",
+			RepositoryType: "npm",
+			VersionStatus:  versionFound,
+			Deprecation:    "use String.prototype.padStart()",
+		}
+	}}
+	chain := fetcherChain{"npm", []MetadataFetcher{registry}}
+
+	pkg := Package{Path: "left-pad", Version: "1.3.0", Installed: dir}
+	info := chain.fetch(context.Background(), &pkg)
+	if info.License != "WTFPL" || info.Sources["License"] != installedSource {
+		t.Errorf("License = %q from %q, want WTFPL from the installed copy", info.License, info.Sources["License"])
+	}
+	if info.VersionStatus != versionFound {
+		t.Errorf("VersionStatus = %q, want %q from the registry", info.VersionStatus, versionFound)
+	}
+	if info.Deprecation == "" {
+		t.Error("Deprecation of the registry is missing")
+	}
+}
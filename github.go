@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// githubGraphQLURL is the GitHub GraphQL API endpoint
+const githubGraphQLURL = "https://api.github.com/graphql"
+
+// githubBatchSize is how many repositories are looked up per GraphQL request
+const githubBatchSize = 50
+
+// githubRepoInfo is the repository metadata returned by the GraphQL API
+type githubRepoInfo struct {
+	URL         string `json:"url"`
+	IsArchived  bool   `json:"isArchived"`
+	CreatedAt   string `json:"createdAt"`
+	PushedAt    string `json:"pushedAt"`
+	LicenseInfo *struct {
+		SpdxID string `json:"spdxId"`
+		Name   string `json:"name"`
+	} `json:"licenseInfo"`
+	Owner struct {
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	} `json:"owner"`
+}
+
+// githubRepoQuery is the selection made for every aliased repository in a batch
+const githubRepoQuery = `{ url isArchived createdAt pushedAt licenseInfo { spdxId name } owner { login ... on Organization { name } ... on User { name } } }`
+
+// buildGitHubBatchQuery builds one GraphQL query that looks up all repos via aliases r0, r1, ...
+func buildGitHubBatchQuery(repos []string) string {
+	var b strings.Builder
+	b.WriteString("query {")
+	for i, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		fmt.Fprintf(&b, " r%d: repository(owner: %s, name: %s) %s", i, strconv.Quote(owner), strconv.Quote(name), githubRepoQuery)
+	}
+	b.WriteString(" }")
+	return b.String()
+}
+
+// queryGitHubBatch looks up a batch of "owner/repo" names in a single GraphQL request.
+// Repositories that do not exist (or are not visible to the token) are omitted.
+func queryGitHubBatch(ctx context.Context, token string, repos []string) (map[string]githubRepoInfo, error) {
+	body, err := json.Marshal(map[string]string{"query": buildGitHubBatchQuery(repos)})
+	if err != nil {
+		return nil, err
+	}
+
+	client := createHTTPClient()
+	client.Timeout = 30 * time.Second
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", githubGraphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub GraphQL: HTTP %d", resp.StatusCode)
+	}
+
+	// Missing repositories come back as null data plus an entry in errors, which is fine
+	var result struct {
+		Data map[string]*githubRepoInfo `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]githubRepoInfo)
+	for i, repo := range repos {
+		if info := result.Data["r"+strconv.Itoa(i)]; info != nil {
+			found[repo] = *info
+		}
+	}
+	return found, nil
+}
+
+// enrichFromGitHub fills missing license, author and copyright information and the
+// archived flag for every package hosted on GitHub, using one GraphQL request per githubBatchSize repos
+func enrichFromGitHub(ctx context.Context, token string, infos []PackageInfo, progress func(done int, total int)) error {
+	byRepo := make(map[string][]int)
+	var repos []string
+	for i, info := range infos {
+		repo := infoGitHubRepo(info)
+		if repo == "" {
+			continue
+		}
+		if _, ok := byRepo[repo]; !ok {
+			repos = append(repos, repo)
+		}
+		byRepo[repo] = append(byRepo[repo], i)
+	}
+
+	for start := 0; start < len(repos); start += githubBatchSize {
+		batch := repos[start:min(start+githubBatchSize, len(repos))]
+		if progress != nil {
+			progress(start, len(repos))
+		}
+
+		found, err := queryGitHubBatch(ctx, token, batch)
+		if err != nil {
+			return err
+		}
+
+		for repo, meta := range found {
+			for _, i := range byRepo[repo] {
+				info := &infos[i]
+				info.Archived = meta.IsArchived
+				if info.GitHubURL == "" {
+					info.GitHubURL = meta.URL
+					info.setSource("GitHub URL", "GitHub")
+				}
+				if info.License == "" && meta.LicenseInfo != nil &&
+					meta.LicenseInfo.SpdxID != "" && meta.LicenseInfo.SpdxID != "NOASSERTION" {
+					info.License = meta.LicenseInfo.SpdxID
+					info.LicenseURL = licenseURL(info.License)
+					info.setLicenseSource("GitHub license detection", confidenceHigh)
+					info.Copyright = setCopyrightFromLicense(info.License)
+					info.setSource("License", "GitHub")
+					delete(info.Sources, "Copyright")
+				}
+				owner := meta.Owner.Name
+				if owner == "" {
+					owner = meta.Owner.Login
+				}
+				if info.Author == "" && owner != "" {
+					info.Author = owner
+					info.setSource("Author", "GitHub")
+				}
+				// Without a statement from the license text, the years of the first and
+				// latest commits are the best approximation of the copyright period
+				if isCopyrightPlaceholder(*info) && info.License != "" && len(meta.CreatedAt) >= 4 && len(meta.PushedAt) >= 4 {
+					first, _ := strconv.Atoi(meta.CreatedAt[:4])
+					last, _ := strconv.Atoi(meta.PushedAt[:4])
+					info.Copyright = formatCopyright(first, last, owner)
+					info.setSource("Copyright", "GitHub")
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// githubAPIURL is the GitHub REST API endpoint
+const githubAPIURL = "https://api.github.com"
+
+// githubLicenseFile is the response of GET /repos/{owner}/{repo}/license
+type githubLicenseFile struct {
+	HTMLURL  string `json:"html_url"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+	License  struct {
+		SpdxID string `json:"spdx_id"`
+		Name   string `json:"name"`
+	} `json:"license"`
+}
+
+// fetchGitHubLicense asks the GitHub REST API for the license GitHub detected in a
+// repository and the text of its license file. It returns nil without an error when the
+// repository has no license file. Without a token the API allows 60 requests per hour.
+func fetchGitHubLicense(ctx context.Context, token string, repo string) (*githubLicenseFile, string, error) {
+	client := createHTTPClient()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", githubAPIURL+"/repos/"+repo+"/license", nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case 200:
+	case 404:
+		return nil, "", nil
+	case 403, 429:
+		return nil, "", fmt.Errorf("GitHub API rate limit reached (HTTP %d); set a token with -github-token", resp.StatusCode)
+	default:
+		return nil, "", fmt.Errorf("GitHub license of %s: HTTP %d", repo, resp.StatusCode)
+	}
+
+	var file githubLicenseFile
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, "", err
+	}
+	text := file.Content
+	if file.Encoding == "base64" {
+		// The content is wrapped at 60 characters
+		data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+		if err != nil {
+			return nil, "", err
+		}
+		text = string(data)
+	}
+	return &file, text, nil
+}
+
+// applyGitHubLicenses sets the license of every GitHub hosted package from the license
+// GitHub detected in its repository, as applyRepositoryLicense describes
+func applyGitHubLicenses(ctx context.Context, token string, infos []PackageInfo, progress func(repo string)) error {
+	byRepo := make(map[string][]int)
+	var repos []string
+	for i, info := range infos {
+		repo := infoGitHubRepo(info)
+		if repo == "" {
+			continue
+		}
+		if _, ok := byRepo[repo]; !ok {
+			repos = append(repos, repo)
+		}
+		byRepo[repo] = append(byRepo[repo], i)
+	}
+
+	for _, repo := range repos {
+		if progress != nil {
+			progress(repo)
+		}
+		file, text, err := fetchGitHubLicense(ctx, token, repo)
+		if err != nil {
+			return err
+		}
+		if file == nil {
+			continue
+		}
+
+		for _, i := range byRepo[repo] {
+			applyRepositoryLicense(&infos[i], file.License.SpdxID, text, file.HTMLURL, "GitHub license API", "license file on GitHub")
+		}
+	}
+
+	return nil
+}
+
+// applyRepositoryLicense sets the license a code host detected in the repository of a
+// package (spdxID, "" or NOASSERTION when it could not tell) and the text of its license
+// file at fileURL. Go licenses, which come from scraping or from classifying license
+// files, are replaced; other packages keep the license their registry declares and
+// only gaps are filled. The license text supplies the copyright statement and, for
+// licenses the host does not recognize, the text for legal review. source names the
+// host's API in the Sources column and fileSource the license file in License Source.
+func applyRepositoryLicense(info *PackageInfo, spdxID string, text string, fileURL string, source string, fileSource string) {
+	switch {
+	case spdxID != "" && spdxID != "NOASSERTION":
+		if info.License == "" || info.RepositoryType == "go" {
+			info.License = spdxID
+			info.setLicenseSource(source, confidenceHigh)
+			info.Copyright = setCopyrightFromLicense(info.License)
+			info.setSource("License", source)
+			delete(info.Sources, "Copyright")
+		}
+	case info.License == "" && strings.TrimSpace(text) != "":
+		// The host found a license file it could not identify
+		if license, confidence := classifyLicenseConfidence(text); confidence >= customLicenseThreshold {
+			info.License = license
+			info.setLicenseSource(fileSource, detectedConfidence(confidence))
+		} else {
+			info.License = customLicense
+			info.LicenseText = text
+			info.setLicenseSource(fileSource, confidenceLow)
+		}
+		info.Copyright = setCopyrightFromLicense(info.License)
+		info.setSource("License", source)
+		delete(info.Sources, "Copyright")
+	}
+	// Link the license file itself when it is what the license was taken from or
+	// agrees with it
+	if info.License != "" && (info.Sources["License"] == source || info.License == spdxID) {
+		info.LicenseURL = fileURL
+		if info.LicenseURL == "" {
+			info.LicenseURL = licenseURL(info.License)
+		}
+	}
+	if isCopyrightPlaceholder(*info) {
+		if copyright := extractCopyright(text); copyright != "" {
+			info.Copyright = copyright
+			info.setSource("Copyright", source)
+		}
+	}
+}
+
+// fetchGitHubOwnerName asks the GitHub REST API for the display name of a user or
+// organization, which is "" when the account has none. Without a token the API allows
+// 60 requests per hour.
+func fetchGitHubOwnerName(ctx context.Context, token string, login string) (string, error) {
+	client := createHTTPClient()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", githubAPIURL+"/users/"+login, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case 200:
+	case 404:
+		return "", nil
+	case 403, 429:
+		return "", fmt.Errorf("GitHub API rate limit reached (HTTP %d); set a token with -github-token", resp.StatusCode)
+	default:
+		return "", fmt.Errorf("GitHub user %s: HTTP %d", login, resp.StatusCode)
+	}
+
+	var user struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(user.Name), nil
+}
+
+// applyGitHubOwners fills the authors the registries left blank for GitHub hosted
+// packages with the display name of the user or organization owning the repository,
+// or its login when it has none. Each owner is looked up once.
+func applyGitHubOwners(ctx context.Context, token string, infos []PackageInfo, progress func(owner string)) error {
+	names := make(map[string]string)
+	for i := range infos {
+		info := &infos[i]
+		repo := infoGitHubRepo(*info)
+		if info.Author != "" || repo == "" {
+			continue
+		}
+		owner, _, _ := strings.Cut(repo, "/")
+		name, ok := names[owner]
+		if !ok {
+			if progress != nil {
+				progress(owner)
+			}
+			var err error
+			if name, err = fetchGitHubOwnerName(ctx, token, owner); err != nil {
+				return err
+			}
+			names[owner] = name
+		}
+		info.Author = cmp.Or(name, owner)
+		info.setSource("Author", "GitHub")
+	}
+	return nil
+}
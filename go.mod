@@ -1,4 +1,4 @@
-module license
+module github.com/jsfaint/license_fetcher
 
 go 1.24.0
 
@@ -8,6 +8,7 @@ require (
 	github.com/ncruces/zenity v0.10.14
 	github.com/xuri/excelize/v2 v2.10.0
 	golang.org/x/mod v0.30.0
+	golang.org/x/text v0.30.0
 )
 
 require (
@@ -26,5 +27,4 @@ require (
 	golang.org/x/image v0.25.0 // indirect
 	golang.org/x/net v0.46.0 // indirect
 	golang.org/x/sys v0.37.0 // indirect
-	golang.org/x/text v0.30.0 // indirect
 )
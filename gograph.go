@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Values of the Dependency column
+const (
+	dependencyDirect   = "direct"
+	dependencyIndirect = "indirect"
+)
+
+// dependencyKind returns the Dependency column value of a package
+func dependencyKind(pkg Package) string {
+	if pkg.Indirect {
+		return dependencyIndirect
+	}
+	return dependencyDirect
+}
+
+// resolveGoTransitive expands the requirements of a go.mod file to the full build list,
+// the modules `go list -m all` reports. The go command is used when it is installed;
+// otherwise the module graph is walked through the module proxy. Requirements marked
+// "// indirect" in go.mod and modules only reached through other modules are flagged
+// as indirect.
+func resolveGoTransitive(ctx context.Context, filename string, progress func(text string)) ([]Package, error) {
+	data, err := parser.ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := modfile.ParseLax(filepath.Base(filename), data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	direct := make(map[string]bool)
+	for _, req := range file.Require {
+		if !req.Indirect {
+			direct[req.Mod.Path] = true
+		}
+	}
+
+	var buildList []module.Version
+	if _, lookErr := exec.LookPath("go"); lookErr == nil {
+		progress("Running go list -m all...")
+		buildList, err = goListModules(ctx, filepath.Dir(filename))
+	}
+	if buildList == nil {
+		buildList, err = walkModuleGraph(ctx, file, progress)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	packages := make([]Package, 0, len(buildList))
+	for _, mod := range buildList {
+		packages = append(packages, Package{
+			Path:     mod.Path,
+			Version:  mod.Version,
+			GoMod:    true,
+			Indirect: !direct[mod.Path],
+		})
+	}
+	return packages, nil
+}
+
+// goListModules runs the go command in dir and returns the build list without the main module
+func goListModules(ctx context.Context, dir string) ([]module.Version, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "list", "-mod=readonly", "-m", "-f", "{{if not .Main}}{{.Path}} {{.Version}}{{end}}", "all")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var mods []module.Version
+	for line := range strings.SplitSeq(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			mods = append(mods, module.Version{Path: fields[0], Version: fields[1]})
+		}
+	}
+	return mods, nil
+}
+
+// fetchGoModFile downloads the go.mod of a module version from the module proxies
+func fetchGoModFile(ctx context.Context, client *http.Client, mod module.Version) (*modfile.File, error) {
+	escapedVersion, err := module.EscapeVersion(mod.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := goProxyGet(ctx, client, mod.Path, "@v/"+escapedVersion+".mod")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s: module proxy returned status %d", mod, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return modfile.ParseLax("go.mod", data, nil)
+}
+
+// walkModuleGraph re-implements minimal version selection over the module proxy: every
+// module version reachable from the main module's requirements is visited, and the
+// highest version of each module path is selected. Replacements in the main go.mod are
+// honoured for version replacements; local directory replacements are kept as required.
+func walkModuleGraph(ctx context.Context, file *modfile.File, progress func(text string)) ([]module.Version, error) {
+	replaced := make(map[module.Version]module.Version)
+	replacedAll := make(map[string]module.Version)
+	for _, rep := range file.Replace {
+		if rep.Old.Version == "" {
+			replacedAll[rep.Old.Path] = rep.New
+		} else {
+			replaced[rep.Old] = rep.New
+		}
+	}
+	excluded := make(map[module.Version]bool)
+	for _, exc := range file.Exclude {
+		excluded[exc.Mod] = true
+	}
+
+	selected := make(map[string]string)
+	visited := make(map[module.Version]bool)
+	var queue []module.Version
+	for _, req := range file.Require {
+		queue = append(queue, req.Mod)
+	}
+
+	client := createHTTPClient()
+	for len(queue) > 0 {
+		mod := queue[0]
+		queue = queue[1:]
+		if visited[mod] || excluded[mod] {
+			continue
+		}
+		visited[mod] = true
+		if current, ok := selected[mod.Path]; !ok || semver.Compare(mod.Version, current) > 0 {
+			selected[mod.Path] = mod.Version
+		}
+
+		// Requirements come from the replacement's go.mod, which is not on the proxy
+		// for a local directory
+		target := mod
+		if rep, ok := replaced[mod]; ok {
+			target = rep
+		} else if rep, ok := replacedAll[mod.Path]; ok {
+			target = rep
+		}
+		if target.Version == "" {
+			continue
+		}
+
+		progress(fmt.Sprintf("Resolving module graph (%d modules)... %s", len(visited), mod))
+		modFile, err := fetchGoModFile(ctx, client, target)
+		if err != nil {
+			return nil, err
+		}
+		for _, req := range modFile.Require {
+			if !visited[req.Mod] {
+				queue = append(queue, req.Mod)
+			}
+		}
+	}
+
+	mods := make([]module.Version, 0, len(selected))
+	for path, version := range selected {
+		mods = append(mods, module.Version{Path: path, Version: version})
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Path < mods[j].Path })
+	return mods, nil
+}
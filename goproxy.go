@@ -0,0 +1,150 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io"
+	"path"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// getGoModuleZipMetadata reads the license of a module from its zip on the module proxy,
+// for modules pkg.go.dev does not know, such as private ones
+func getGoModuleZipMetadata(ctx context.Context, pkg *Package) PackageInfo {
+	info := PackageInfo{Name: pkg.Path, Version: pkg.Version, RepositoryType: "go"}
+	getGoProxyMetadata(ctx, pkg, &info)
+	return info
+}
+
+// getGoProxyMetadata fills info from the module zip on the Go module proxy: the license
+// is detected from the module's own license files and the description taken from the
+// package comment of its root package. It reports false when the zip could not be
+// fetched or holds no recognizable license.
+func getGoProxyMetadata(ctx context.Context, pkg *Package, info *PackageInfo) bool {
+	data, err := downloadGoModuleZip(ctx, pkg.Path, pkg.Version)
+	if err != nil {
+		return false
+	}
+
+	root := pkg.Path + "@" + pkg.Version
+	result := scanArchive(data, root)
+	if result.DetectedLicense == "" {
+		return false
+	}
+
+	info.License = result.DetectedLicense
+	info.LicenseURL = goModuleFileURL(pkg.Path, pkg.Version, result.LicenseFile)
+	if info.LicenseURL == "" {
+		info.LicenseURL = licenseURL(info.License)
+	}
+	info.setLicenseSource("license file in module zip", detectedConfidence(result.Confidence))
+	info.LicenseText = result.LicenseText
+	info.Copyright = setCopyrightFromLicense(info.License)
+	info.Description = goModuleSynopsis(data, root)
+	info.GitHubURL, _ = goModuleRepository(pkg.Path)
+	return true
+}
+
+// goModuleSynopsis returns the first sentence of the package comment of the module's
+// root package, the text pkg.go.dev shows as its description
+func goModuleSynopsis(data []byte, root string) string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range zr.File {
+		name := strings.TrimPrefix(file.Name, root+"/")
+		if path.Dir(name) != "." || path.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			continue
+		}
+		src, err := io.ReadAll(io.LimitReader(rc, 1<<20))
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		// Only the package clause and the comment above it are needed
+		parsed, err := parser.ParseFile(fset, name, src, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || parsed.Doc == nil {
+			continue
+		}
+		if synopsis := new(doc.Package).Synopsis(parsed.Doc.Text()); synopsis != "" {
+			return synopsis
+		}
+	}
+	return ""
+}
+
+// goModuleAuthor infers the author of a module from its path: the user or organization
+// of hosted repositories, otherwise the host. It is the last resort for modules whose
+// repository owner could not be looked up on GitHub.
+func goModuleAuthor(modulePath string) string {
+	parts := strings.Split(modulePath, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	if strings.Contains(modulePath, "github.com/") || strings.HasPrefix(modulePath, "gitee.com/") {
+		return parts[1]
+	}
+	return parts[0]
+}
+
+// goModuleRepository splits the path of a module hosted on GitHub or Gitee into the URL
+// of its repository and the directory of the module within it. A major version suffix,
+// as in github.com/owner/repo/v3, is not a directory: such modules are taken to live at
+// the root of the repository on their major version branch, as most do.
+func goModuleRepository(modulePath string) (repoURL string, dir string) {
+	prefix, _, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		prefix = modulePath
+	}
+	parts := strings.Split(prefix, "/")
+	if len(parts) < 3 || (parts[0] != "github.com" && parts[0] != "gitee.com") {
+		return "", ""
+	}
+	return "https://" + strings.Join(parts[:3], "/"), strings.Join(parts[3:], "/")
+}
+
+// goModuleRef returns the git reference a module version was published from: the
+// commit of a pseudo-version, otherwise the tag, which modules in a subdirectory prefix
+// with the directory
+func goModuleRef(modulePath string, version string) string {
+	if version == "" {
+		return ""
+	}
+	if module.IsPseudoVersion(version) {
+		rev, err := module.PseudoVersionRev(version)
+		if err != nil {
+			return ""
+		}
+		return rev
+	}
+	tag := strings.TrimSuffix(version, "+incompatible")
+	if _, dir := goModuleRepository(modulePath); dir != "" {
+		tag = dir + "/" + tag
+	}
+	return tag
+}
+
+// goPackageURL links the pkg.go.dev page of a module version. Private modules are
+// unknown to pkg.go.dev and get the path of the version on their proxy instead.
+func goPackageURL(modulePath string, version string) string {
+	if registries.isPrivateGoModule(modulePath) {
+		return modulePath + "/@v/" + version + ".info"
+	}
+	if version == "" {
+		return "https://pkg.go.dev/" + modulePath
+	}
+	return "https://pkg.go.dev/" + modulePath + "@" + version
+}
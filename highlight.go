@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Cell colors of highlighted rows, Excel's own "bad" and "neutral" styles
+var (
+	problemFill   = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}}
+	problemFont   = excelize.Font{Color: "9C0006"}
+	attentionFill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFEB9C"}}
+	attentionFont = excelize.Font{Color: "9C5700"}
+)
+
+// Row highlights: red for problems, yellow for rows needing attention
+const (
+	highlightProblem   = "problem"
+	highlightAttention = "attention"
+)
+
+// attentionLicenses are license values that always need a reviewer, though not a
+// known copyleft license
+var attentionLicenses = []string{customLicense, spdxNoAssertion, "UNKNOWN", "SEE LICENSE", "LicenseRef-"}
+
+// riskPrefixes returns the license prefixes of a risk category from licensePrefixRisks
+func riskPrefixes(risk string) []string {
+	var prefixes []string
+	for _, entry := range licensePrefixRisks {
+		if entry.risk == risk {
+			prefixes = append(prefixes, strings.ToUpper(entry.prefix))
+		}
+	}
+	return prefixes
+}
+
+// containsAnyFormula builds an Excel formula testing whether text contains any of the
+// values, ignoring case
+func containsAnyFormula(text string, values []string) string {
+	tests := make([]string, len(values))
+	for i, value := range values {
+		tests[i] = fmt.Sprintf(`ISNUMBER(SEARCH("%s",%s))`, value, text)
+	}
+	return strings.Join(tests, ",")
+}
+
+// highlightFormulas returns the conditional formatting formulas for the first data row
+// marking a row red (empty or strong copyleft license, incompatible with the project
+// license) or yellow (weak copyleft or custom license, compatibility needing review).
+// license and compatibility are column names; compatibility is "" without that column.
+func highlightFormulas(license string, compatibility string) (problem string, attention string) {
+	cell := "$" + license + "2"
+	// LGPL would match GPL, so it is removed before looking for strong copyleft
+	withoutLGPL := fmt.Sprintf(`SUBSTITUTE(UPPER(%s),"LGPL","")`, cell)
+
+	problems := []string{fmt.Sprintf("LEN(TRIM(%s))=0", cell), containsAnyFormula(withoutLGPL, riskPrefixes(riskStrongCopyleft))}
+	attentions := []string{containsAnyFormula(cell, riskPrefixes(riskWeakCopyleft)), containsAnyFormula(cell, attentionLicenses)}
+	if compatibility != "" {
+		verdict := "$" + compatibility + "2"
+		problems = append(problems, fmt.Sprintf(`LEFT(%s,%d)="%s"`, verdict, len(compatibilityFail), compatibilityFail))
+		attentions = append(attentions,
+			fmt.Sprintf(`LEFT(%s,%d)="%s"`, verdict, len(compatibilityReview), compatibilityReview),
+			fmt.Sprintf(`LEFT(%s,%d)="%s"`, verdict, len(compatibilityUnknown), compatibilityUnknown))
+	}
+	return "OR(" + strings.Join(problems, ",") + ")", "OR(" + strings.Join(attentions, ",") + ")"
+}
+
+// highlightProblemRows adds conditional formatting to the data rows of a dependency
+// sheet, so rows stay highlighted correctly when reviewers edit the license
+func highlightProblemRows(f *excelize.File, sheet string, header []string, lastRow int) error {
+	licenseColumn := slices.Index(header, "License")
+	if licenseColumn < 0 || lastRow < 2 {
+		return nil
+	}
+	license, err := excelize.ColumnNumberToName(licenseColumn + 1)
+	if err != nil {
+		return err
+	}
+	compatibility := ""
+	if i := slices.Index(header, "Compatibility"); i >= 0 {
+		compatibility, _ = excelize.ColumnNumberToName(i + 1)
+	}
+	lastColumn, err := excelize.ColumnNumberToName(len(header))
+	if err != nil {
+		return err
+	}
+
+	problemStyle, err := f.NewConditionalStyle(&excelize.Style{Fill: problemFill, Font: &problemFont})
+	if err != nil {
+		return err
+	}
+	attentionStyle, err := f.NewConditionalStyle(&excelize.Style{Fill: attentionFill, Font: &attentionFont})
+	if err != nil {
+		return err
+	}
+
+	problem, attention := highlightFormulas(license, compatibility)
+	return f.SetConditionalFormat(sheet, fmt.Sprintf("A2:%s%d", lastColumn, lastRow), []excelize.ConditionalFormatOptions{
+		{Type: "formula", Criteria: problem, Format: &problemStyle, StopIfTrue: true},
+		{Type: "formula", Criteria: attention, Format: &attentionStyle},
+	})
+}
+
+// licenseHighlight decides the highlight of a row the way highlightFormulas does, for
+// streamed sheets that cannot carry conditional formatting
+func licenseHighlight(license string, verdict string) string {
+	upper := strings.ToUpper(license)
+	containsAny := func(text string, values []string) bool {
+		return slices.ContainsFunc(values, func(value string) bool {
+			return strings.Contains(text, strings.ToUpper(value))
+		})
+	}
+
+	if strings.TrimSpace(license) == "" || strings.HasPrefix(verdict, compatibilityFail) ||
+		containsAny(strings.ReplaceAll(upper, "LGPL", ""), riskPrefixes(riskStrongCopyleft)) {
+		return highlightProblem
+	}
+	if strings.HasPrefix(verdict, compatibilityReview) || strings.HasPrefix(verdict, compatibilityUnknown) ||
+		containsAny(upper, riskPrefixes(riskWeakCopyleft)) || containsAny(upper, attentionLicenses) {
+		return highlightAttention
+	}
+	return ""
+}
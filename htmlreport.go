@@ -0,0 +1,117 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"slices"
+	"time"
+)
+
+// htmlReportTemplate renders the standalone HTML report: no external scripts or styles,
+// so it can be published as a CI artifact or attached to release notes
+//
+//go:embed report.html
+var htmlReportTemplate string
+
+// htmlCell is a table cell of the HTML report
+type htmlCell struct {
+	Text string
+	Link string
+	Wrap bool
+}
+
+// htmlRow is a table row of the HTML report
+type htmlRow struct {
+	Cells     []htmlCell
+	Highlight string
+}
+
+// htmlLicense is a bar of the license chart
+type htmlLicense struct {
+	License   string
+	Packages  int
+	Percent   float64
+	Highlight string
+}
+
+// htmlExporter collects the report rows and writes them as an HTML page on close
+type htmlExporter struct {
+	filename string
+	header   []string
+	rows     []htmlRow
+}
+
+// newHTMLExporter starts an HTML report with the columns of header
+func newHTMLExporter(filename string, header []string) *htmlExporter {
+	return &htmlExporter{filename: filename, header: header}
+}
+
+// write appends one row
+func (e *htmlExporter) write(values []interface{}) error {
+	license, verdict := "", ""
+	row := htmlRow{Cells: make([]htmlCell, len(values))}
+	for i, val := range values {
+		text := fmt.Sprint(val)
+		cell := htmlCell{Text: text, Wrap: e.header[i] == "Description"}
+		if slices.Contains(linkColumns, e.header[i]) && isWebURL(text) {
+			cell.Link, cell.Text = text, linkDisplayText(text)
+		}
+		switch e.header[i] {
+		case "License":
+			license = text
+		case "Compatibility":
+			verdict = text
+		}
+		row.Cells[i] = cell
+	}
+	row.Highlight = licenseHighlight(license, verdict)
+	e.rows = append(e.rows, row)
+	return nil
+}
+
+// close renders the page with the compliance score and license chart and moves it into
+// place, returning the name written
+func (e *htmlExporter) close(title string, score string, infos []PackageInfo) (string, error) {
+	tmpl, err := template.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	licenses, counts := licenseDistribution(infos)
+	chart := make([]htmlLicense, len(licenses))
+	for i, license := range licenses {
+		highlight := licenseHighlight(license, "")
+		if license == "Unknown" {
+			highlight = highlightProblem
+		}
+		chart[i] = htmlLicense{
+			License:   license,
+			Packages:  counts[license],
+			Percent:   float64(counts[license]) * 100 / float64(counts[licenses[0]]),
+			Highlight: highlight,
+		}
+	}
+
+	tmp, err := createTempFor(e.filename)
+	if err != nil {
+		return "", err
+	}
+	err = tmpl.Execute(tmp, map[string]interface{}{
+		"Title":     title,
+		"Generated": time.Now().Format("2006-01-02 15:04"),
+		"Score":     score,
+		"Header":    displayHeader(e.header),
+		"Rows":      e.rows,
+		"Licenses":  chart,
+	})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return replaceFile(tmp.Name(), e.filename, true)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// linkColumns are the report columns holding URLs, written as clickable links
+var linkColumns = []string{"License URL", "LicenseURL", "Repository", "GitHub URL", "GitHubURL", "Package URL", "PackageURL"}
+
+// maxSheetHyperlinks is the most hyperlinks Excel allows on a worksheet; URLs beyond it
+// are written as plain text
+const maxSheetHyperlinks = 65530
+
+// hyperlinkFormulaPattern extracts the target of a HYPERLINK formula
+var hyperlinkFormulaPattern = regexp.MustCompile(`^=?HYPERLINK\("((?:[^"]|"")*)"`)
+
+// isWebURL reports whether a cell value is an http or https URL
+func isWebURL(value string) bool {
+	return strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://")
+}
+
+// linkDisplayText is the short text shown for a URL: owner/repo for GitHub, the license
+// identifier for license pages and the host and path otherwise
+func linkDisplayText(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+	host := strings.TrimPrefix(u.Host, "www.")
+	path := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+
+	switch host {
+	case "github.com":
+		if parts := strings.Split(strings.Trim(path, "/"), "/"); len(parts) >= 2 {
+			return parts[0] + "/" + parts[1]
+		}
+	case "licenses.nuget.org", "spdx.org", "opensource.org":
+		if i := strings.LastIndex(path, "/"); i >= 0 && i < len(path)-1 {
+			return strings.TrimSuffix(path[i+1:], ".html")
+		}
+	}
+	return host + path
+}
+
+// resolveLinks replaces the display text of linked cells in the URL columns of rows
+// read from a sheet with their link target, so readers see the full URL
+func resolveLinks(f *excelize.File, sheet string, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	for col, name := range rows[0] {
+		if !slices.Contains(linkColumns, name) {
+			continue
+		}
+		for i := 1; i < len(rows); i++ {
+			if col >= len(rows[i]) || rows[i][col] == "" {
+				continue
+			}
+			cell, err := excelize.CoordinatesToCellName(col+1, i+1)
+			if err != nil {
+				return err
+			}
+			ok, link, err := f.GetCellHyperLink(sheet, cell)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				// Streamed sheets link with the HYPERLINK function
+				formula, err := f.GetCellFormula(sheet, cell)
+				if err != nil {
+					return err
+				}
+				if m := hyperlinkFormulaPattern.FindStringSubmatch(formula); m != nil {
+					ok, link = true, strings.ReplaceAll(m[1], `""`, `"`)
+				}
+			}
+			if ok && isWebURL(link) {
+				rows[i][col] = link
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+)
+
+// installedSource names the copy of a package installed in the project, in vendor/,
+// node_modules/ or a virtualenv, in the Sources column
+const installedSource = "installed copy"
+
+// installedFetcher reads the copy of a package found by parser.FindInstalled, before
+// any registry is asked; what the copy declares wins over the registry's metadata
+func installedFetcher(repositoryType string) MetadataFetcher {
+	return sourceFetcher{installedSource, fetchDeclared, localFetch(func(pkg *Package) PackageInfo {
+		return getInstalledMetadata(pkg, repositoryType)
+	})}
+}
+
+// getInstalledMetadata reports what the installed copy of a package declares, and the
+// license and copyright of its license files when it declares none
+func getInstalledMetadata(pkg *Package, repositoryType string) PackageInfo {
+	if pkg.Installed == "" {
+		return PackageInfo{}
+	}
+	declared, ok := parser.ReadInstalled(pkg.Installed, repositoryType)
+	if !ok {
+		return PackageInfo{}
+	}
+	installedVersion := declared.Version
+	declared.Path, declared.Version = pkg.Path, pkg.Version
+	info := getDeclaredMetadata(&declared, repositoryType)
+	if installedVersion != "" && installedVersion != pkg.Version {
+		info.ResolvedVersion = installedVersion
+	}
+	if info.License != "" {
+		info.setLicenseSource("declared by installed copy", declaredConfidence(info.License))
+	}
+
+	// Python distributions keep their license files in the .dist-info folder, since
+	// PEP 639 in its licenses/ folder
+	root := unwrappedArchive
+	if repositoryType == "pypi" {
+		root = "licenses"
+	}
+	files := readFolderFiles(pkg.Installed)
+	if repositoryType == "pypi" && len(files) == 0 {
+		// Older distributions install their license inside the package's own folder
+		for _, recorded := range parser.RecordedLicenseFiles(pkg.Installed) {
+			if text, err := os.ReadFile(recorded); err == nil {
+				files = append(files, archiveFile{Name: filepath.Base(recorded), Text: string(text)})
+				break
+			}
+		}
+	}
+	result := scanLicenseFiles(files, root)
+	info.DetectedLicense = result.DetectedLicense
+	info.DetectionConfidence = result.Confidence
+	info.LicenseText = result.LicenseText
+	info.Notices = strings.Join(result.Notices, "; ")
+	info.Vendored = strings.Join(result.Vendored, "; ")
+
+	if info.License == "" && result.DetectedLicense != "" {
+		info.License = result.DetectedLicense
+		info.LicenseURL = licenseURL(info.License)
+		info.setLicenseSource("license file of installed copy", detectedConfidence(result.Confidence))
+		info.Copyright = setCopyrightFromLicense(info.License)
+	}
+	if isCopyrightPlaceholder(info) && result.Copyright != "" {
+		info.Copyright = result.Copyright
+	}
+	return info
+}
+
+// readFolderFiles reads the LICENSE/COPYING/NOTICE files below the folder of an
+// installed package, named relative to it with forward slashes. Nested node_modules
+// hold other packages and are left out.
+func readFolderFiles(dir string) []archiveFile {
+	var files []archiveFile
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !parser.IsLicenseFile(d.Name()) {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		text, err := io.ReadAll(io.LimitReader(f, 1<<20))
+		if err == nil {
+			files = append(files, archiveFile{Name: filepath.ToSlash(name), Text: string(text)})
+		}
+		return nil
+	})
+	return files
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Where the metadata of a package came from, for the source of the JSON report
+const (
+	originRegistry     = "registry"
+	originDepsDev      = "deps.dev"
+	originProjectCache = "project cache"
+	originUserCache    = "user cache"
+	originInstalled    = "installed copy" // vendor/, node_modules/ or a virtualenv
+	originNone         = "none"           // offline and never cached
+)
+
+// jsonSource records where a package was found and where its metadata came from
+type jsonSource struct {
+	Manifest string `json:"manifest,omitempty"`
+	Origin   string `json:"origin"`
+	Registry string `json:"registry,omitempty"`
+	// Fields maps report fields to the source that supplied them
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// jsonFetchError is a failed request made for a package
+type jsonFetchError struct {
+	URL      string `json:"url"`
+	Status   int    `json:"status,omitempty"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts,omitempty"`
+}
+
+// jsonPackage is one record of the JSON report
+type jsonPackage struct {
+	Name                string           `json:"name"`
+	Version             string           `json:"version"`
+	Ecosystem           string           `json:"ecosystem"`
+	License             string           `json:"license"`
+	LicenseComponents   []string         `json:"licenseComponents,omitempty"`
+	LicenseURL          string           `json:"licenseUrl,omitempty"`
+	LicenseSource       string           `json:"licenseSource,omitempty"`
+	LicenseConfidence   string           `json:"licenseConfidence,omitempty"`
+	Author              string           `json:"author,omitempty"`
+	Description         string           `json:"description,omitempty"`
+	Copyright           string           `json:"copyright,omitempty"`
+	Repository          string           `json:"repository,omitempty"`
+	GitHubURL           string           `json:"githubUrl,omitempty"`
+	PackageURL          string           `json:"packageUrl,omitempty"`
+	VersionStatus       string           `json:"versionStatus,omitempty"`
+	ResolvedVersion     string           `json:"resolvedVersion,omitempty"`
+	LatestVersion       string           `json:"latestVersion,omitempty"`
+	Outdated            string           `json:"outdated,omitempty"`
+	Dependency          string           `json:"dependency,omitempty"`
+	Group               string           `json:"group,omitempty"`
+	Scope               string           `json:"scope,omitempty"`
+	Workspace           string           `json:"workspace,omitempty"`
+	DetectedLicense     string           `json:"detectedLicense,omitempty"`
+	DetectionConfidence float64          `json:"detectionConfidence,omitempty"`
+	Notices             string           `json:"notices,omitempty"`
+	LicenseFile         string           `json:"licenseFile,omitempty"`
+	Vendored            string           `json:"vendored,omitempty"`
+	Archived            bool             `json:"archived,omitempty"`
+	Deprecation         string           `json:"deprecation,omitempty"`
+	Security            string           `json:"security,omitempty"`
+	Compatibility       string           `json:"compatibility,omitempty"`
+	ApprovalStatus      string           `json:"approvalStatus"`
+	Reviewer            string           `json:"reviewer,omitempty"`
+	ReviewDate          string           `json:"reviewDate,omitempty"`
+	Source              jsonSource       `json:"source"`
+	Errors              []jsonFetchError `json:"errors,omitempty"`
+}
+
+// jsonExporter collects the packages of the report and writes them as a JSON array
+type jsonExporter struct {
+	filename string
+	manifest string // input file, used when packages do not name their project
+	// licenseFiles records where -license-files saved the license file of each package
+	licenseFiles bool
+	packages     []jsonPackage
+}
+
+// newJSONExporter starts a JSON report of the packages found in manifest
+func newJSONExporter(filename string, manifest string) *jsonExporter {
+	return &jsonExporter{filename: filename, manifest: manifest, packages: []jsonPackage{}}
+}
+
+// add appends the record of a package
+func (e *jsonExporter) add(entry *reportEntry) {
+	info := entry.Info
+	record := jsonPackage{
+		Name:                info.Name,
+		Version:             info.Version,
+		Ecosystem:           info.RepositoryType,
+		License:             info.License,
+		LicenseComponents:   licenseComponents(info.License),
+		LicenseURL:          info.LicenseURL,
+		LicenseSource:       info.LicenseSource,
+		LicenseConfidence:   info.LicenseConfidence,
+		Author:              info.Author,
+		Description:         info.Description,
+		Copyright:           info.Copyright,
+		Repository:          info.Repository,
+		GitHubURL:           info.GitHubURL,
+		PackageURL:          info.PackageURL,
+		VersionStatus:       info.VersionStatus,
+		ResolvedVersion:     info.ResolvedVersion,
+		LatestVersion:       info.LatestVersion,
+		Outdated:            outdatedStatus(*info),
+		Group:               entry.Package.Group,
+		Scope:               entry.Package.Scope,
+		Workspace:           entry.Package.Workspace,
+		DetectedLicense:     info.DetectedLicense,
+		DetectionConfidence: info.DetectionConfidence,
+		Notices:             info.Notices,
+		Vendored:            info.Vendored,
+		Archived:            info.Archived,
+		Deprecation:         info.Deprecation,
+		Security:            info.Security,
+		ApprovalStatus:      entry.Review.Status,
+		Reviewer:            entry.Review.Reviewer,
+		ReviewDate:          entry.Review.Date,
+		Source: jsonSource{
+			Manifest: entry.Package.Project,
+			Origin:   entry.Origin,
+			Registry: entry.Package.Registry,
+			Fields:   info.Sources,
+		},
+	}
+	if e.licenseFiles {
+		record.LicenseFile = licenseFilePath(*info)
+	}
+	if record.Source.Manifest == "" {
+		record.Source.Manifest = e.manifest
+	}
+	if entry.Package.Indirect {
+		record.Dependency = dependencyIndirect
+	}
+	if entry.Verdict.Verdict != "" {
+		record.Compatibility = entry.Verdict.String()
+	}
+	for _, failure := range entry.Failures {
+		record.Errors = append(record.Errors, jsonFetchError{
+			URL:      failure.URL,
+			Status:   failure.Status,
+			Error:    failure.Err,
+			Attempts: failure.Attempts,
+		})
+	}
+	e.packages = append(e.packages, record)
+}
+
+// close writes the records and moves the file into place, returning the name written
+func (e *jsonExporter) close() (string, error) {
+	data, err := json.MarshalIndent(e.packages, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	tmp, err := createTempFor(e.filename)
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return replaceFile(tmp.Name(), e.filename, true)
+}
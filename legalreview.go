@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// legalReviewSheetName is the sheet listing packages whose license needs a lawyer
+const legalReviewSheetName = "Legal Review"
+
+// maxCellText is the most characters an Excel cell holds
+const maxCellText = 32767
+
+// needsLegalReview reports whether a package is under a custom or amended license
+func needsLegalReview(info PackageInfo) bool {
+	return strings.Contains(info.License, customLicense) || strings.Contains(info.DetectedLicense, customLicense)
+}
+
+// writeLegalReviewSheet (re)creates the sheet listing packages under custom licenses,
+// with the license text attached. It returns how many packages were listed; no sheet
+// is created when there are none.
+func writeLegalReviewSheet(f *excelize.File, infos []PackageInfo) (int, error) {
+	if idx, _ := f.GetSheetIndex(legalReviewSheetName); idx >= 0 {
+		if err := f.DeleteSheet(legalReviewSheetName); err != nil {
+			return 0, err
+		}
+	}
+
+	rows := [][]interface{}{{"Package Name", "Version", "Declared License", "Detected License", "License Text"}}
+	for _, info := range infos {
+		if !needsLegalReview(info) {
+			continue
+		}
+		text := info.LicenseText
+		if utf8.RuneCountInString(text) > maxCellText {
+			text = string([]rune(text)[:maxCellText])
+		}
+		rows = append(rows, []interface{}{info.Name, info.Version, info.License, info.DetectedLicense, text})
+	}
+	if len(rows) == 1 {
+		return 0, nil
+	}
+
+	if _, err := f.NewSheet(legalReviewSheetName); err != nil {
+		return 0, err
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return 0, err
+		}
+		if err := f.SetSheetRow(legalReviewSheetName, cell, &row); err != nil {
+			return 0, err
+		}
+	}
+	return len(rows) - 1, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// licenseURLTemplate is the page linked for a license identifier, with {id} replaced by
+// the identifier; -license-url changes it and an empty template leaves License URL empty
+var licenseURLTemplate = "https://spdx.org/licenses/{id}.html"
+
+// licenseURL returns the page of a single SPDX license identifier. Compound
+// expressions, families such as "BSD" and names that are not identifiers have no page
+// of their own and get no link.
+func licenseURL(license string) string {
+	if licenseURLTemplate == "" {
+		return ""
+	}
+	expr, err := parseLicenseExpression(license)
+	if err != nil || expr.Op != "" || strings.HasPrefix(expr.License, "LicenseRef-") {
+		return ""
+	}
+	switch strings.ToUpper(expr.License) {
+	case "UNKNOWN", "NONE", spdxNoAssertion:
+		return ""
+	}
+	if slices.ContainsFunc(licenseFamilies, func(family string) bool { return strings.EqualFold(expr.License, family) }) {
+		return ""
+	}
+	return strings.ReplaceAll(licenseURLTemplate, "{id}", expr.License)
+}
+
+// goModuleFileURL links a file at the root of a Go module hosted on GitHub, at the tag
+// or commit of version and in the directory of the module within the repository
+func goModuleFileURL(modulePath string, version string, file string) string {
+	repo, dir := goModuleRepository(modulePath)
+	ref := goModuleRef(modulePath, version)
+	if repo == "" || ref == "" || file == "" {
+		return ""
+	}
+	return repo + "/blob/" + ref + "/" + path.Join(dir, file)
+}
+
+// relinkLicense replaces the licenses.nuget.org links that earlier versions gave every
+// license, which metadata cached by them still holds; NuGet packages keep theirs
+func relinkLicense(info *PackageInfo) {
+	if info.RepositoryType != "nuget" && strings.HasPrefix(info.LicenseURL, "https://licenses.nuget.org/") {
+		info.LicenseURL = licenseURL(info.License)
+	}
+}
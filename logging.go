@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Values of the -log-format flag
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// Environment variables giving the defaults of -log-level and -log-format
+const (
+	logLevelEnv  = "LICENSE_FETCHER_LOG_LEVEL"
+	logFormatEnv = "LICENSE_FETCHER_LOG_FORMAT"
+)
+
+// envOr returns the value of an environment variable, or fallback when it is unset
+func envOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// setupLogging sends the structured log to w at level (debug, info, warn or error) in
+// format, text or json
+func setupLogging(w io.Writer, level string, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", level)
+	}
+
+	options := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch format {
+	case logFormatText:
+		handler = slog.NewTextHandler(w, options)
+	case logFormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("unknown log format %q (expected text or json)", format)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
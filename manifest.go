@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+)
+
+// Package is a dependency listed by a manifest
+type Package = parser.Package
+
+// manifest is a parsed dependency file together with the metadata sources of its ecosystem
+type manifest struct {
+	packages       []Package
+	name           string
+	getMetadata    func(context.Context, *Package) PackageInfo
+	repositoryType string
+	// isPackageJSON selects the npm report layout
+	isPackageJSON bool
+}
+
+// parseManifest detects the kind of dependency file and parses it
+func parseManifest(filename string) (*manifest, error) {
+	parsed, err := parser.Parse(filename)
+	if err != nil {
+		return nil, err
+	}
+	m := &manifest{
+		packages:       parsed.Packages,
+		name:           parsed.Name,
+		getMetadata:    fetchersFor(parsed.Ecosystem).fetch,
+		repositoryType: parsed.Ecosystem,
+		isPackageJSON:  parsed.IsPackageJSON,
+	}
+	// Distribution archives carry their metadata and are not looked up on PyPI
+	if parser.IsPythonDist(filename) {
+		m.getMetadata = fetcherChain{"pypi", []MetadataFetcher{
+			sourceFetcher{"package archive", fetchDeclared, localFetch(getPythonDistMetadata)},
+		}}.fetch
+	}
+	return m, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+)
+
+// mavenCentral is the base URL of the Maven Central repository
+const mavenCentral = "https://repo1.maven.org/maven2/"
+
+// maxPOMParents limits how far parent POMs are followed for inherited licenses
+const maxPOMParents = 5
+
+// mavenPOMURL returns the Maven Central URL of an artifact's POM
+func mavenPOMURL(groupID string, artifactID string, version string) string {
+	return mavenCentral + strings.ReplaceAll(groupID, ".", "/") + "/" + artifactID + "/" + version + "/" + artifactID + "-" + version + ".pom"
+}
+
+// fetchMavenPOM downloads and decodes a POM from Maven Central. found is false when
+// Maven Central does not have the version.
+func fetchMavenPOM(ctx context.Context, client *http.Client, groupID string, artifactID string, version string) (pom *parser.POM, found bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", mavenPOMURL(groupID, artifactID, version), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == 404 {
+		return nil, false, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, false, fmt.Errorf("maven central returned status %d", resp.StatusCode)
+	}
+
+	pom = &parser.POM{}
+	if err := xml.NewDecoder(resp.Body).Decode(pom); err != nil {
+		return nil, true, err
+	}
+	return pom, true, nil
+}
+
+// latestMavenVersion asks the Maven Central search API for the newest version of an artifact
+func latestMavenVersion(ctx context.Context, client *http.Client, groupID string, artifactID string) string {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := url.Values{"q": {`g:"` + groupID + `" AND a:"` + artifactID + `"`}, "rows": {"1"}, "wt": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://search.maven.org/solrsearch/select?"+query.Encode(), nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return ""
+	}
+
+	var result struct {
+		Response struct {
+			Docs []struct {
+				LatestVersion string `json:"latestVersion"`
+			} `json:"docs"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || len(result.Response.Docs) == 0 {
+		return ""
+	}
+	return result.Response.Docs[0].LatestVersion
+}
+
+// getMavenMetadata fetches license, organization and SCM URL of a groupId:artifactId
+// from its POM on Maven Central, following parent POMs for inherited values
+func getMavenMetadata(ctx context.Context, pkg *Package) PackageInfo {
+	info := PackageInfo{
+		Name:            pkg.Path,
+		Version:         pkg.Version,
+		ModuleNameNoVer: pkg.Path,
+		RepositoryType:  "maven",
+	}
+
+	groupID, artifactID, ok := strings.Cut(pkg.Path, ":")
+	if !ok {
+		return info
+	}
+	client := createHTTPClient()
+
+	// Unresolved properties and version ranges cannot be looked up directly
+	version := pkg.Version
+	if version == "" || strings.Contains(version, "${") || strings.ContainsAny(version, "[(,") {
+		version = latestMavenVersion(ctx, client, groupID, artifactID)
+		if version == "" {
+			return info
+		}
+		if pkg.Version == "" {
+			info.Version = version
+		}
+	}
+
+	pom, found, err := fetchMavenPOM(ctx, client, groupID, artifactID, version)
+	if err != nil {
+		return info
+	}
+	if !found {
+		if isPinnedVersion(pkg.Version) {
+			info.VersionStatus = versionMissing
+		}
+		return info
+	}
+	info.VersionStatus = versionFound
+
+	info.Description = strings.Join(strings.Fields(pom.Description), " ")
+	if info.Description == "" {
+		info.Description = strings.TrimSpace(pom.Name)
+	}
+
+	// Licenses, organization and SCM are commonly declared once in a parent POM
+	var licenses []string
+	for range maxPOMParents {
+		props := pom.Variables()
+		if len(licenses) == 0 {
+			for _, license := range pom.Licenses {
+				if name := strings.TrimSpace(license.Name); name != "" {
+					licenses = append(licenses, standardizeLicense(name))
+				}
+			}
+		}
+		if info.Author == "" {
+			info.Author = strings.TrimSpace(pom.Organization.Name)
+			if info.Author == "" && len(pom.Developers) > 0 {
+				info.Author = strings.TrimSpace(pom.Developers[0].Name)
+				if info.Author == "" {
+					info.Author = strings.TrimSpace(pom.Developers[0].Organization)
+				}
+			}
+		}
+		if info.Repository == "" {
+			info.Repository = parser.InterpolatePOM(pom.SCM.URL, props)
+			if info.Repository == "" {
+				info.Repository = parser.InterpolatePOM(pom.URL, props)
+			}
+		}
+
+		if len(licenses) > 0 && info.Author != "" && info.Repository != "" || pom.Parent.ArtifactID == "" {
+			break
+		}
+		parent, found, err := fetchMavenPOM(ctx, client, pom.Parent.GroupID, pom.Parent.ArtifactID, pom.Parent.Version)
+		if err != nil || !found {
+			break
+		}
+		pom = parent
+	}
+
+	// Several licenses in a POM are alternatives the user may choose from
+	if len(licenses) > 0 {
+		info.License = strings.Join(licenses, " OR ")
+		info.LicenseURL = licenseURL(info.License)
+		info.setLicenseSource("POM licenses", declaredConfidence(info.License))
+	}
+	if strings.Contains(info.Repository, "github.com") {
+		info.GitHubURL = info.Repository
+	}
+	info.Copyright = setCopyrightFromLicense(info.License)
+	return info
+}
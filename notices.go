@@ -0,0 +1,246 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Values of the -notices flag
+const (
+	noticesText   = "txt"
+	noticesFolder = "folder"
+)
+
+// Names of the attribution outputs written next to the report
+const (
+	noticesFileName   = "THIRD-PARTY-NOTICES.txt"
+	noticesFolderName = "third-party-licenses"
+)
+
+// spdxLicenseTextURL serves the standard text of every SPDX license
+const spdxLicenseTextURL = "https://raw.githubusercontent.com/spdx/license-list-data/main/text/"
+
+// spdxIDPattern matches the license identifiers inside an SPDX expression
+var spdxIDPattern = regexp.MustCompile(`[A-Za-z0-9][A-Za-z0-9.+-]*`)
+
+// noticeFolderPattern matches characters that do not belong in a folder name
+var noticeFolderPattern = regexp.MustCompile(`[^A-Za-z0-9._@+-]+`)
+
+// licenseTextFetcher finds the full license text of dependencies, downloading the
+// standard text of each SPDX license only once
+type licenseTextFetcher struct {
+	standard map[string]string
+}
+
+// newLicenseTextFetcher returns a fetcher with an empty standard text cache
+func newLicenseTextFetcher() *licenseTextFetcher {
+	return &licenseTextFetcher{standard: make(map[string]string)}
+}
+
+// standardText returns the SPDX list text of a license identifier, or ""
+func (f *licenseTextFetcher) standardText(ctx context.Context, id string) string {
+	if text, ok := f.standard[id]; ok {
+		return text
+	}
+	text := fetchText(ctx, spdxLicenseTextURL+id+".txt")
+	f.standard[id] = text
+	return text
+}
+
+// licenseText returns the license text to ship for a package: the custom text found by
+// deep mode, the LICENSE file of its GitHub, GitLab or Bitbucket repository, or else the
+// standard text of each license of its SPDX expression. It returns "" when none is
+// available.
+func (f *licenseTextFetcher) licenseText(ctx context.Context, info PackageInfo) string {
+	if info.LicenseText != "" {
+		return info.LicenseText
+	}
+	if text := fetchPackageLicense(ctx, info); text != "" {
+		return text
+	}
+
+	var texts []string
+	for _, id := range spdxIDPattern.FindAllString(info.License, -1) {
+		switch strings.ToUpper(id) {
+		case "AND", "OR", "WITH":
+			continue
+		}
+		text := f.standardText(ctx, id)
+		if text == "" {
+			return ""
+		}
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// noticeHeading identifies a package in the notices file
+func noticeHeading(info PackageInfo) string {
+	heading := info.Name
+	if info.Version != "" {
+		heading += " " + info.Version
+	}
+	var lines []string
+	lines = append(lines, heading)
+	if info.License != "" {
+		lines = append(lines, "License: "+info.License)
+	}
+	if !isCopyrightPlaceholder(info) {
+		lines = append(lines, info.Copyright)
+	}
+	if info.Repository != "" {
+		lines = append(lines, info.Repository)
+	} else if info.GitHubURL != "" {
+		lines = append(lines, info.GitHubURL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeNotices writes the full license text of every dependency, either into one
+// THIRD-PARTY-NOTICES.txt or into one folder per package below third-party-licenses,
+// both in dir. It returns the name written and the packages whose text was not found.
+func writeNotices(ctx context.Context, dir string, format string, infos []PackageInfo, progress func(name string)) (string, []string, error) {
+	fetcher := newLicenseTextFetcher()
+	var b strings.Builder
+	var missing []string
+
+	target := filepath.Join(dir, noticesFileName)
+	if format == noticesFolder {
+		target = filepath.Join(dir, noticesFolderName)
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return "", nil, err
+		}
+	} else {
+		b.WriteString("THIRD-PARTY SOFTWARE NOTICES AND INFORMATION\n\n")
+		b.WriteString("This software includes the third-party components listed below.\n")
+	}
+
+	for _, info := range infos {
+		if progress != nil {
+			progress(info.Name)
+		}
+		text := fetcher.licenseText(ctx, info)
+		if err := ctx.Err(); err != nil {
+			return "", nil, err
+		}
+		if text == "" {
+			missing = append(missing, info.Name+"@"+info.Version)
+			text = "The license text of this component could not be found and must be added manually."
+		}
+		text = strings.TrimSpace(text) + "\n"
+
+		if format == noticesFolder {
+			folder := noticeFolderPattern.ReplaceAllString(info.Name+"@"+info.Version, "_")
+			folder = filepath.Join(target, strings.TrimSuffix(folder, "@"))
+			if err := os.MkdirAll(folder, 0755); err != nil {
+				return "", nil, err
+			}
+			if err := os.WriteFile(filepath.Join(folder, "LICENSE"), []byte(noticeHeading(info)+"\n\n"+text), 0644); err != nil {
+				return "", nil, err
+			}
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n%s\n%s\n\n%s", strings.Repeat("=", 80), noticeHeading(info), text)
+	}
+
+	if format == noticesFolder {
+		return target, missing, nil
+	}
+	return target, missing, os.WriteFile(target, []byte(b.String()), 0644)
+}
+
+// Values of the -license-files flag
+const (
+	licenseFilesFolder = "folder"
+	licenseFilesZip    = "zip"
+)
+
+// Names of the license file tree and its archive written next to the report
+const (
+	licenseFilesDirName = "licenses"
+	licenseFilesZipName = "licenses.zip"
+)
+
+// licenseFilePath is where the license file of a package goes, relative to the report:
+// licenses/<ecosystem>/<name>@<version>/LICENSE, with forward slashes as in a zip
+func licenseFilePath(info PackageInfo) string {
+	ecosystem := noticeFolderPattern.ReplaceAllString(strings.ToLower(info.RepositoryType), "_")
+	if ecosystem == "" {
+		ecosystem = "other"
+	}
+	folder := noticeFolderPattern.ReplaceAllString(info.Name+"@"+info.Version, "_")
+	return path.Join(licenseFilesDirName, ecosystem, strings.TrimSuffix(folder, "@"), "LICENSE")
+}
+
+// writeLicenseFiles saves the license file of every dependency at its licenseFilePath,
+// either as a folder tree or inside licenses.zip, in dir, to ship with the product. It
+// returns the name written and the packages whose text was not found.
+func writeLicenseFiles(ctx context.Context, dir string, format string, infos []PackageInfo, progress func(name string)) (string, []string, error) {
+	fetcher := newLicenseTextFetcher()
+	var missing []string
+
+	target := filepath.Join(dir, licenseFilesDirName)
+	var archive *zip.Writer
+	if format == licenseFilesZip {
+		target = filepath.Join(dir, licenseFilesZipName)
+		f, err := os.Create(target)
+		if err != nil {
+			return "", nil, err
+		}
+		defer f.Close()
+		archive = zip.NewWriter(f)
+	}
+
+	written := make(map[string]bool)
+	for _, info := range infos {
+		name := licenseFilePath(info)
+		if written[name] {
+			continue
+		}
+		written[name] = true
+		if progress != nil {
+			progress(info.Name)
+		}
+		text := fetcher.licenseText(ctx, info)
+		if err := ctx.Err(); err != nil {
+			return "", nil, err
+		}
+		if text == "" {
+			missing = append(missing, info.Name+"@"+info.Version)
+			text = "The license text of this component could not be found and must be added manually."
+		}
+		data := []byte(strings.TrimSpace(text) + "\n")
+
+		if archive != nil {
+			w, err := archive.Create(name)
+			if err != nil {
+				return "", nil, err
+			}
+			if _, err := w.Write(data); err != nil {
+				return "", nil, err
+			}
+			continue
+		}
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return "", nil, err
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if archive != nil {
+		if err := archive.Close(); err != nil {
+			return "", nil, err
+		}
+	}
+	return target, missing, nil
+}
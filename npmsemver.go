@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// semverComparator is one condition of an npm version range, such as >=1.2.3
+type semverComparator struct {
+	op      string // <, <=, >, >= or =
+	version string // with the v prefix golang.org/x/mod/semver expects
+	// pre is set when the range names a prerelease itself, which lets prereleases of
+	// the same major.minor.patch match
+	pre bool
+}
+
+// matches reports whether version satisfies the comparator
+func (c semverComparator) matches(version string) bool {
+	cmp := semver.Compare(version, c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return cmp == 0
+}
+
+// semverPartial is a version that may leave out or wildcard its trailing parts, as in
+// 1.x or 1.2; missing parts are -1
+type semverPartial struct {
+	major, minor, patch int
+	pre                 string
+}
+
+var (
+	semverPartialPattern = regexp.MustCompile(`^[v=]*(\d+|[xX*])(?:\.(\d+|[xX*])(?:\.(\d+|[xX*])(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?)?)?$`)
+	semverHyphenPattern  = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+	// semverOperatorSpace joins operators to the version written after a space, as in ">= 1.2"
+	semverOperatorSpace = regexp.MustCompile(`([<>=~^]+)\s+`)
+)
+
+// parseSemverPartial parses a possibly partial version; "" and * are fully wildcarded
+func parseSemverPartial(s string) (semverPartial, error) {
+	if s == "" {
+		return semverPartial{-1, -1, -1, ""}, nil
+	}
+	m := semverPartialPattern.FindStringSubmatch(s)
+	if m == nil {
+		return semverPartial{}, fmt.Errorf("invalid version %q", s)
+	}
+	p := semverPartial{pre: m[4]}
+	parts := []*int{&p.major, &p.minor, &p.patch}
+	for i, part := range m[1:4] {
+		n, err := strconv.Atoi(part)
+		if err != nil || (i > 0 && *parts[i-1] < 0) {
+			n = -1
+		}
+		*parts[i] = n
+	}
+	return p, nil
+}
+
+// semverString renders a full version for the comparators
+func semverString(major, minor, patch int, pre string) string {
+	v := fmt.Sprintf("v%d.%d.%d", major, minor, patch)
+	if pre != "" {
+		v += "-" + pre
+	}
+	return v
+}
+
+// comparator builds a comparator, noting whether its version is a prerelease
+func comparator(op string, major, minor, patch int, pre string) semverComparator {
+	return semverComparator{op, semverString(major, minor, patch, pre), pre != ""}
+}
+
+// below is the comparator excluding a version and its prereleases, as npm writes <2.0.0-0
+func below(major, minor, patch int) semverComparator {
+	return semverComparator{"<", semverString(major, minor, patch, "0"), false}
+}
+
+// primitiveComparators turns an operator and a partial version into comparators, so
+// >1.2 means >=1.3.0 and <=1 means <2.0.0-0
+func primitiveComparators(op string, p semverPartial) []semverComparator {
+	switch {
+	case p.major < 0:
+		if op == "<" || op == ">" {
+			// Nothing is below or above every version
+			return []semverComparator{below(0, 0, 0)}
+		}
+		return nil
+	case p.patch >= 0:
+		if op == "" {
+			op = "="
+		}
+		return []semverComparator{comparator(op, p.major, p.minor, p.patch, p.pre)}
+	case p.minor < 0:
+		switch op {
+		case ">":
+			return []semverComparator{comparator(">=", p.major+1, 0, 0, "")}
+		case ">=":
+			return []semverComparator{comparator(">=", p.major, 0, 0, "")}
+		case "<":
+			return []semverComparator{below(p.major, 0, 0)}
+		case "<=":
+			return []semverComparator{below(p.major+1, 0, 0)}
+		}
+		return []semverComparator{comparator(">=", p.major, 0, 0, ""), below(p.major+1, 0, 0)}
+	default:
+		switch op {
+		case ">":
+			return []semverComparator{comparator(">=", p.major, p.minor+1, 0, "")}
+		case ">=":
+			return []semverComparator{comparator(">=", p.major, p.minor, 0, "")}
+		case "<":
+			return []semverComparator{below(p.major, p.minor, 0)}
+		case "<=":
+			return []semverComparator{below(p.major, p.minor+1, 0)}
+		}
+		return []semverComparator{comparator(">=", p.major, p.minor, 0, ""), below(p.major, p.minor+1, 0)}
+	}
+}
+
+// tildeComparators expands ~1.2.3 to >=1.2.3 <1.3.0-0, allowing patch updates
+func tildeComparators(p semverPartial) []semverComparator {
+	switch {
+	case p.major < 0:
+		return nil
+	case p.minor < 0:
+		return primitiveComparators("", p)
+	}
+	return []semverComparator{comparator(">=", p.major, p.minor, max(p.patch, 0), p.pre), below(p.major, p.minor+1, 0)}
+}
+
+// caretComparators expands ^1.2.3 to >=1.2.3 <2.0.0-0, allowing changes that do not
+// modify the left-most non-zero part
+func caretComparators(p semverPartial) []semverComparator {
+	switch {
+	case p.major < 0:
+		return nil
+	case p.minor < 0:
+		return primitiveComparators("", p)
+	case p.patch < 0:
+		if p.major > 0 {
+			return []semverComparator{comparator(">=", p.major, p.minor, 0, ""), below(p.major+1, 0, 0)}
+		}
+		return primitiveComparators("", p)
+	}
+	lower := comparator(">=", p.major, p.minor, p.patch, p.pre)
+	switch {
+	case p.major > 0:
+		return []semverComparator{lower, below(p.major+1, 0, 0)}
+	case p.minor > 0:
+		return []semverComparator{lower, below(0, p.minor+1, 0)}
+	}
+	return []semverComparator{lower, below(0, 0, p.patch+1)}
+}
+
+// hyphenComparators expands 1.2 - 2.3.4 to >=1.2.0 <=2.3.4; a partial upper bound
+// includes every version it covers
+func hyphenComparators(from semverPartial, to semverPartial) []semverComparator {
+	var comparators []semverComparator
+	if from.major >= 0 {
+		comparators = append(comparators, comparator(">=", from.major, max(from.minor, 0), max(from.patch, 0), from.pre))
+	}
+	switch {
+	case to.major < 0:
+	case to.minor < 0:
+		comparators = append(comparators, below(to.major+1, 0, 0))
+	case to.patch < 0:
+		comparators = append(comparators, below(to.major, to.minor+1, 0))
+	default:
+		comparators = append(comparators, comparator("<=", to.major, to.minor, to.patch, to.pre))
+	}
+	return comparators
+}
+
+// parseNPMRange parses an npm version range such as "^1.2.0", ">=1.0.0 <2",
+// "1.x || 2.1 - 2.3" or "~0.4" into sets of comparators; a version satisfies the range
+// when it matches every comparator of one set
+func parseNPMRange(spec string) ([][]semverComparator, error) {
+	var sets [][]semverComparator
+	for _, part := range strings.Split(spec, "||") {
+		part = strings.TrimSpace(part)
+
+		if m := semverHyphenPattern.FindStringSubmatch(part); m != nil {
+			from, err := parseSemverPartial(m[1])
+			if err != nil {
+				return nil, err
+			}
+			to, err := parseSemverPartial(m[2])
+			if err != nil {
+				return nil, err
+			}
+			sets = append(sets, hyphenComparators(from, to))
+			continue
+		}
+
+		set := []semverComparator{}
+		for _, term := range strings.Fields(semverOperatorSpace.ReplaceAllString(part, "$1")) {
+			op := ""
+			switch {
+			case strings.HasPrefix(term, "~"):
+				// ~> is accepted as ~
+				p, err := parseSemverPartial(strings.TrimLeft(term, "~>"))
+				if err != nil {
+					return nil, err
+				}
+				set = append(set, tildeComparators(p)...)
+				continue
+			case strings.HasPrefix(term, "^"):
+				p, err := parseSemverPartial(term[1:])
+				if err != nil {
+					return nil, err
+				}
+				set = append(set, caretComparators(p)...)
+				continue
+			case strings.HasPrefix(term, ">="), strings.HasPrefix(term, "<="):
+				op = term[:2]
+			case strings.HasPrefix(term, ">"), strings.HasPrefix(term, "<"):
+				op = term[:1]
+			}
+			p, err := parseSemverPartial(strings.TrimPrefix(term[len(op):], "="))
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, primitiveComparators(op, p)...)
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// satisfiesSet reports whether version matches every comparator of set. Prereleases
+// only match when a comparator names a prerelease of the same major.minor.patch, as
+// npm does, so ^1.2.0 never installs 2.0.0-beta.
+func satisfiesSet(version string, set []semverComparator) bool {
+	for _, c := range set {
+		if !c.matches(version) {
+			return false
+		}
+	}
+	if semver.Prerelease(version) == "" {
+		return true
+	}
+	release := strings.TrimSuffix(version, semver.Prerelease(version)+semver.Build(version))
+	for _, c := range set {
+		if c.pre && strings.TrimSuffix(c.version, semver.Prerelease(c.version)) == release {
+			return true
+		}
+	}
+	return false
+}
+
+// satisfiesNPMRange reports whether a published version is within the range
+func satisfiesNPMRange(version string, sets [][]semverComparator) bool {
+	v := "v" + version
+	// Unlike npm, golang.org/x/mod/semver also accepts shorthands such as v1.2
+	release := strings.TrimSuffix(strings.TrimSuffix(v, semver.Build(v)), semver.Prerelease(v))
+	if !semver.IsValid(v) || strings.Count(release, ".") != 2 {
+		return false
+	}
+	for _, set := range sets {
+		if satisfiesSet(v, set) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveNPMVersion picks the version npm installs for a dependency from the published
+// versions and dist-tags: the version a tag such as latest points to, the latest
+// version when it is in range, and otherwise the highest version in range. It
+// returns "" when no published version satisfies the range.
+func resolveNPMVersion(spec string, versions []string, distTags map[string]string) string {
+	spec = strings.TrimSpace(spec)
+	if tagged, ok := distTags[spec]; ok {
+		return tagged
+	}
+	sets, err := parseNPMRange(spec)
+	if err != nil {
+		return ""
+	}
+	if latest, ok := distTags["latest"]; ok && satisfiesNPMRange(latest, sets) {
+		return latest
+	}
+
+	best := ""
+	for _, version := range versions {
+		if satisfiesNPMRange(version, sets) && (best == "" || semver.Compare("v"+version, "v"+best) > 0) {
+			best = version
+		}
+	}
+	return best
+}
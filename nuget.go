@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// nugetVersion picks the version to look up for a NuGet version requirement: the
+// lower bound of a range such as [1.0,2.0), or the newest release matching a
+// floating version such as 1.*
+func nugetVersion(ctx context.Context, client *http.Client, id string, version string) string {
+	version = strings.Trim(version, "[]() ")
+	version, _, _ = strings.Cut(version, ",")
+	version = strings.TrimSpace(version)
+	if version != "" && !strings.Contains(version, "*") {
+		return version
+	}
+
+	var index struct {
+		Versions []string `json:"versions"`
+	}
+	if found, err := getJSON(ctx, client, "https://api.nuget.org/v3-flatcontainer/"+strings.ToLower(id)+"/index.json", &index); err != nil || !found {
+		return ""
+	}
+	prefix := strings.TrimSuffix(version, "*")
+	for i := len(index.Versions) - 1; i >= 0; i-- {
+		// Floating versions do not match prereleases unless they ask for them
+		if strings.HasPrefix(index.Versions[i], prefix) && (strings.Contains(prefix, "-") || !strings.Contains(index.Versions[i], "-")) {
+			return index.Versions[i]
+		}
+	}
+	return ""
+}
+
+// getNuGetMetadata fetches license expression or URL, authors, description and
+// project URL of a package from the NuGet V3 registration API
+func getNuGetMetadata(ctx context.Context, pkg *Package) PackageInfo {
+	info := PackageInfo{
+		Name:            pkg.Path,
+		Version:         pkg.Version,
+		ModuleNameNoVer: pkg.Path,
+		RepositoryType:  "nuget",
+	}
+
+	client := createHTTPClient()
+	version := nugetVersion(ctx, client, pkg.Path, pkg.Version)
+	if version == "" {
+		return info
+	}
+	if pkg.Version == "" || strings.Contains(pkg.Version, "*") {
+		info.Version = version
+	}
+
+	// The registration leaf points to the catalog entry holding the package metadata
+	var leaf struct {
+		CatalogEntry string `json:"catalogEntry"`
+	}
+	found, err := getJSON(ctx, client, "https://api.nuget.org/v3/registration5-gz-semver2/"+strings.ToLower(pkg.Path)+"/"+strings.ToLower(version)+".json", &leaf)
+	if err != nil {
+		return info
+	}
+	if !found {
+		if isPinnedVersion(pkg.Version) {
+			info.VersionStatus = versionMissing
+		}
+		return info
+	}
+	info.VersionStatus = versionFound
+
+	var entry struct {
+		Authors           string `json:"authors"`
+		Description       string `json:"description"`
+		LicenseExpression string `json:"licenseExpression"`
+		LicenseURL        string `json:"licenseUrl"`
+		ProjectURL        string `json:"projectUrl"`
+	}
+	if found, err := getJSON(ctx, client, leaf.CatalogEntry, &entry); err != nil || !found {
+		return info
+	}
+
+	if entry.LicenseExpression != "" {
+		info.License = entry.LicenseExpression
+		info.LicenseURL = "https://licenses.nuget.org/" + entry.LicenseExpression
+		info.setLicenseSource("NuGet license expression", declaredConfidence(info.License))
+	} else if entry.LicenseURL != "" && !strings.Contains(entry.LicenseURL, "aka.ms/deprecateLicenseUrl") {
+		// Packages predating license expressions only link to their license
+		info.LicenseURL = entry.LicenseURL
+	}
+	info.Author = entry.Authors
+	info.Description = strings.Join(strings.Fields(entry.Description), " ")
+	info.Repository = entry.ProjectURL
+	if strings.Contains(info.Repository, "github.com") {
+		info.GitHubURL = info.Repository
+	}
+	info.Copyright = setCopyrightFromLicense(info.License)
+	return info
+}
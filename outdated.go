@@ -0,0 +1,175 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// Values of the Outdated? column; empty when the versions cannot be compared
+const (
+	outdatedYes = "yes"
+	outdatedNo  = "no"
+)
+
+// latestVersion asks the registry of a package for its newest release: the latest
+// dist-tag on npm, the version PyPI describes, @latest of the Go proxies, and the newest
+// stable version on Maven Central, NuGet and RubyGems. Other ecosystems, and packages
+// their registry could not tell, fall back to deps.dev. It returns "" when nobody knows.
+func latestVersion(ctx context.Context, pkg Package, ecosystem string) string {
+	client := createHTTPClient()
+	latest := ""
+	switch ecosystem {
+	case "npm":
+		for _, registry := range registries.npmRegistryURLs(pkg.Path) {
+			var tags map[string]string
+			if found, err := getJSON(ctx, client, registry+"-/package/"+strings.Replace(pkg.Path, "/", "%2f", 1)+"/dist-tags", &tags); err == nil && found {
+				latest = tags["latest"]
+				break
+			}
+		}
+	case "pypi":
+		var project pypiProject
+		if found, err := getPyPIJSON(ctx, client, pkg.Path+"/json", &project); err == nil && found {
+			latest = project.Info.Version
+		}
+	case "go":
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		if resp, err := goProxyGet(ctx, client, pkg.Path, "@latest"); err == nil {
+			var info struct {
+				Version string `json:"Version"`
+			}
+			if resp.StatusCode == 200 && json.NewDecoder(resp.Body).Decode(&info) == nil {
+				latest = info.Version
+			}
+			resp.Body.Close()
+		}
+		cancel()
+	case "maven":
+		if groupID, artifactID, ok := strings.Cut(pkg.Path, ":"); ok {
+			latest = latestMavenVersion(ctx, client, groupID, artifactID)
+		}
+	case "nuget":
+		latest = nugetVersion(ctx, client, pkg.Path, "*")
+	case "rubygems":
+		var gem struct {
+			Version string `json:"version"`
+		}
+		if found, err := getJSON(ctx, client, "https://rubygems.org/api/v1/versions/"+pkg.Path+"/latest.json", &gem); err == nil && found && gem.Version != "unknown" {
+			latest = gem.Version
+		}
+	}
+	if latest == "" {
+		latest = depsDevLatestVersion(ctx, pkg, ecosystem)
+	}
+	return latest
+}
+
+// usedVersion returns the exact version a row describes, or "" for a range that was
+// not resolved
+func usedVersion(info PackageInfo) string {
+	if info.ResolvedVersion != "" {
+		return info.ResolvedVersion
+	}
+	if isPinnedVersion(info.Version) {
+		return cleanVersionString(info.Version)
+	}
+	return ""
+}
+
+// outdatedStatus tells whether a newer release than the version in use is published
+func outdatedStatus(info PackageInfo) string {
+	used := usedVersion(info)
+	if used == "" || info.LatestVersion == "" {
+		return ""
+	}
+	order, ok := compareVersions(info.RepositoryType, used, info.LatestVersion)
+	switch {
+	case !ok:
+		return ""
+	case order < 0:
+		return outdatedYes
+	}
+	return outdatedNo
+}
+
+// compareVersions orders two versions of an ecosystem: semantic versions for Go, npm
+// and Cargo, PEP 440 for PyPI, and their dotted numbers otherwise. ok is false when
+// they cannot be ordered.
+func compareVersions(ecosystem string, a string, b string) (int, bool) {
+	switch ecosystem {
+	case "go", "npm", "cargo":
+		va, vb := "v"+strings.TrimPrefix(a, "v"), "v"+strings.TrimPrefix(b, "v")
+		if semver.IsValid(va) && semver.IsValid(vb) {
+			return semver.Compare(va, vb), true
+		}
+	case "pypi":
+		pa, okA := parsePEP440(a)
+		pb, okB := parsePEP440(b)
+		if okA && okB {
+			return comparePEP440(pa, pb), true
+		}
+		return 0, false
+	}
+	return compareDottedVersions(a, b)
+}
+
+// versionPartPattern splits a version into its runs of digits and of letters
+var versionPartPattern = regexp.MustCompile(`\d+|[A-Za-z]+`)
+
+// compareDottedVersions orders versions such as 2.13.1, 5.3.27.RELEASE or 1.0.0-rc1
+// part by part. Numbers compare numerically; a version that goes on with letters where
+// the other ends, as 1.0-beta against 1.0, is a prerelease and comes first.
+func compareDottedVersions(a string, b string) (int, bool) {
+	// Tags such as v1.2.3 name the same version as 1.2.3
+	pa := versionPartPattern.FindAllString(strings.TrimPrefix(a, "v"), -1)
+	pb := versionPartPattern.FindAllString(strings.TrimPrefix(b, "v"), -1)
+	if len(pa) == 0 || len(pb) == 0 {
+		return 0, false
+	}
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		switch {
+		case i >= len(pa):
+			return versionTail(pb[i]), true
+		case i >= len(pb):
+			return -versionTail(pa[i]), true
+		}
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return cmp.Compare(na, nb), true
+			}
+		case errA == nil:
+			// A number after the common parts is a release, letters a qualifier
+			return 1, true
+		case errB == nil:
+			return -1, true
+		default:
+			if c := strings.Compare(strings.ToLower(pa[i]), strings.ToLower(pb[i])); c != 0 {
+				return c, true
+			}
+		}
+	}
+	return 0, true
+}
+
+// versionTail orders a version ending against one that goes on with part: more numbers
+// make a later release, letters a prerelease or a qualifier such as RELEASE
+func versionTail(part string) int {
+	if _, err := strconv.Atoi(part); err == nil {
+		return -1
+	}
+	switch strings.ToLower(part) {
+	case "release", "final", "ga":
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// overridesFileName is the overrides file picked up next to the manifest
+const overridesFileName = "license_overrides.json"
+
+// overrideSource names overridden fields in the Sources and License Source columns
+const overrideSource = "manual"
+
+// licenseOverride pins fields of a package the fetchers get wrong. Version and
+// ecosystem narrow it down; left empty it applies to every version and ecosystem.
+type licenseOverride struct {
+	Package   string `json:"package"`
+	Version   string `json:"version,omitempty"`
+	Ecosystem string `json:"ecosystem,omitempty"`
+	License   string `json:"license,omitempty"`
+	Author    string `json:"author,omitempty"`
+	Copyright string `json:"copyright,omitempty"`
+	Reason    string `json:"reason,omitempty"` // why the override exists, for whoever reviews it
+}
+
+// matches reports whether the override applies to a package
+func (o licenseOverride) matches(info PackageInfo) bool {
+	if o.Package != info.Name {
+		return false
+	}
+	if o.Version != "" && o.Version != info.Version && o.Version != info.ResolvedVersion {
+		return false
+	}
+	return o.Ecosystem == "" || strings.EqualFold(o.Ecosystem, info.RepositoryType)
+}
+
+// loadOverrides reads an overrides file; a missing file means no overrides
+func loadOverrides(filename string) ([]licenseOverride, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var overrides []licenseOverride
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	for i, o := range overrides {
+		if o.Package == "" || (o.License == "" && o.Author == "" && o.Copyright == "") {
+			return nil, fmt.Errorf("%s: override %d needs a package and a license, author or copyright", filename, i+1)
+		}
+	}
+	return overrides, nil
+}
+
+// applyOverrides sets the fields pinned by the first matching override of every
+// package, with "manual" as their source
+func applyOverrides(overrides []licenseOverride, infos []PackageInfo) {
+	for i := range infos {
+		info := &infos[i]
+		for _, o := range overrides {
+			if !o.matches(*info) {
+				continue
+			}
+			slog.Debug("override applied", "package", info.Name, "version", info.Version, "license", o.License, "reason", o.Reason)
+			if o.License != "" {
+				// The placeholder copyright names the license, so it follows the new one
+				placeholder := isCopyrightPlaceholder(*info)
+				info.License = standardizeLicense(o.License)
+				info.LicenseURL = licenseURL(info.License)
+				if placeholder {
+					info.Copyright = setCopyrightFromLicense(info.License)
+				}
+				info.setSource("License", overrideSource)
+				info.setLicenseSource(overrideSource, confidenceHigh)
+			}
+			if o.Author != "" {
+				info.Author = o.Author
+				info.setSource("Author", overrideSource)
+			}
+			if o.Copyright != "" {
+				info.Copyright = o.Copyright
+				info.setSource("Copyright", overrideSource)
+			}
+			break
+		}
+	}
+}
+
+// saveOverrides adds overrides to the file, replacing the entries they supersede
+func saveOverrides(filename string, added []licenseOverride) error {
+	overrides, err := loadOverrides(filename)
+	if err != nil {
+		return err
+	}
+	for _, o := range added {
+		overrides = slices.DeleteFunc(overrides, func(old licenseOverride) bool {
+			return old.Package == o.Package && old.Version == o.Version && strings.EqualFold(old.Ecosystem, o.Ecosystem)
+		})
+		overrides = append(overrides, o)
+	}
+
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
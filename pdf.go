@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Page sizes in points
+const (
+	a4Width  = 595.0
+	a4Height = 842.0
+)
+
+// pdfFont is one of the standard fonts every PDF reader has, so nothing is embedded
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota
+	fontBold
+	fontMono
+)
+
+// pdfFontNames are the base font names, in resource order /F1, /F2, /F3
+var pdfFontNames = []string{"Helvetica", "Helvetica-Bold", "Courier"}
+
+// helveticaWidths and helveticaBoldWidths are the advance widths of the printable
+// ASCII characters (space to ~) per 1000 units of font size
+var helveticaWidths = []int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = []int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// textWidth returns the width of s in points. Characters outside ASCII are counted
+// as a digit, which is close enough for the Latin letters WinAnsi can show.
+func (font pdfFont) textWidth(s string, size float64) float64 {
+	if font == fontMono {
+		return float64(utf8.RuneCountInString(s)) * 600 * size / 1000
+	}
+	widths := helveticaWidths
+	if font == fontBold {
+		widths = helveticaBoldWidths
+	}
+	units := 0
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			units += widths[r-' ']
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// wrapText breaks s into lines no wider than width, at spaces where possible
+func (font pdfFont) wrapText(s string, size float64, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if font.textWidth(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Words wider than a line, like long URLs, are cut
+			for font.textWidth(word, size) > width {
+				cut := 0
+				for cut < len(word) {
+					_, n := utf8.DecodeRuneInString(word[cut:])
+					if cut > 0 && font.textWidth(word[:cut+n], size) > width {
+						break
+					}
+					cut += n
+				}
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfPage is a page and the content drawn on it so far
+type pdfPage struct {
+	width   float64
+	height  float64
+	content bytes.Buffer
+}
+
+// pdfDocument builds a PDF of text, lines and filled boxes in memory. Coordinates
+// are in points from the top left corner of the page.
+type pdfDocument struct {
+	title string
+	pages []*pdfPage
+	page  *pdfPage
+}
+
+// newPDFDocument starts an empty document
+func newPDFDocument(title string) *pdfDocument {
+	return &pdfDocument{title: title}
+}
+
+// addPage starts a new page of the given size
+func (d *pdfDocument) addPage(width, height float64) {
+	d.page = &pdfPage{width: width, height: height}
+	d.pages = append(d.pages, d.page)
+}
+
+// text draws s with its baseline at y
+func (d *pdfDocument) text(x, y float64, font pdfFont, size float64, s string) {
+	fmt.Fprintf(&d.page.content, "BT /F%d %.1f Tf %.2f %.2f Td %s Tj ET\n", font+1, size, x, d.page.height-y, pdfString(s))
+}
+
+// fillRect fills a box with an RGB color given as RRGGBB
+func (d *pdfDocument) fillRect(x, y, width, height float64, color string) {
+	var r, g, b int
+	fmt.Sscanf(color, "%02x%02x%02x", &r, &g, &b)
+	fmt.Fprintf(&d.page.content, "q %.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f Q\n",
+		float64(r)/255, float64(g)/255, float64(b)/255, x, d.page.height-y-height, width, height)
+}
+
+// line draws a thin gray line
+func (d *pdfDocument) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&d.page.content, "q 0.6 G 0.5 w %.2f %.2f m %.2f %.2f l S Q\n", x1, d.page.height-y1, x2, d.page.height-y2)
+}
+
+// pdfString encodes s as a literal string in WinAnsi, replacing what it cannot show
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			c = '?'
+		}
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c == 0x7F:
+			b.WriteByte(' ')
+		case c > 0x7F:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// write writes the document with a page number in the footer of every page
+func (d *pdfDocument) write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// 1: catalog, 2: page tree, 3: info, then the fonts, then each page and its content
+	fonts := 4
+	firstPage := fonts + len(pdfFontNames)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(fmt.Sprintf("<< /Title %s /Producer (license_fetcher) >>", pdfString(d.title)))
+	var resources strings.Builder
+	for i, name := range pdfFontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fmt.Fprintf(&resources, "/F%d %d 0 R ", i+1, fonts+i)
+	}
+
+	for i, page := range d.pages {
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		x := page.width - 36 - fontRegular.textWidth(footer, 8)
+		fmt.Fprintf(&page.content, "BT /F1 8 Tf 0.4 g %.2f 20 Td %s Tj ET\n", x, pdfString(footer))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			page.width, page.height, resources.String(), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
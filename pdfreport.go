@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Layout of the PDF attribution document, in points
+const (
+	pdfMargin      = 36.0
+	pdfTableSize   = 8.0 // font size of the dependency table
+	pdfLineHeight  = 10.0
+	pdfTextSize    = 7.0 // font size of the license texts
+	pdfTextLeading = 8.5
+)
+
+// pdfColumn is a column of the dependency table and its share of the page width
+type pdfColumn struct {
+	Header string
+	Share  float64
+	Value  func(info PackageInfo) string
+}
+
+// pdfColumns are the columns attribution needs; the full metadata is in the workbook
+var pdfColumns = []pdfColumn{
+	{"Name", 0.20, func(info PackageInfo) string { return info.Name }},
+	{"Version", 0.09, func(info PackageInfo) string { return info.Version }},
+	{"Ecosystem", 0.07, func(info PackageInfo) string { return info.RepositoryType }},
+	{"License", 0.16, func(info PackageInfo) string { return info.License }},
+	{"Copyright", 0.26, func(info PackageInfo) string {
+		if isCopyrightPlaceholder(info) {
+			return ""
+		}
+		return info.Copyright
+	}},
+	{"Source", 0.22, func(info PackageInfo) string {
+		for _, link := range []string{info.Repository, info.GitHubURL, info.PackageURL} {
+			if link != "" {
+				return link
+			}
+		}
+		return ""
+	}},
+}
+
+// pdfWriter lays out the attribution document top to bottom, starting a page
+// whenever the next block does not fit
+type pdfWriter struct {
+	doc    *pdfDocument
+	width  float64
+	height float64
+	y      float64
+}
+
+// newPage starts a page of the writer's size
+func (w *pdfWriter) newPage(width, height float64) {
+	w.width, w.height = width, height
+	w.doc.addPage(width, height)
+	w.y = pdfMargin
+}
+
+// fits reports whether a block of the given height fits on the current page
+func (w *pdfWriter) fits(height float64) bool {
+	return w.y+height <= w.height-pdfMargin
+}
+
+// tableHeader draws the header row of the dependency table
+func (w *pdfWriter) tableHeader() {
+	tableWidth := w.width - 2*pdfMargin
+	w.doc.fillRect(pdfMargin, w.y, tableWidth, pdfLineHeight+4, "D9E1F2")
+	x := pdfMargin
+	for _, col := range pdfColumns {
+		w.doc.text(x+2, w.y+pdfLineHeight, fontBold, pdfTableSize, col.Header)
+		x += col.Share * tableWidth
+	}
+	w.y += pdfLineHeight + 4
+}
+
+// tableRow draws the row of a package, its cells wrapped to the column widths
+func (w *pdfWriter) tableRow(info PackageInfo) {
+	tableWidth := w.width - 2*pdfMargin
+	cells := make([][]string, len(pdfColumns))
+	lines := 1
+	for i, col := range pdfColumns {
+		cells[i] = fontRegular.wrapText(col.Value(info), pdfTableSize, col.Share*tableWidth-4)
+		lines = max(lines, len(cells[i]))
+	}
+	height := float64(lines)*pdfLineHeight + 4
+	if !w.fits(height) {
+		w.newPage(w.width, w.height)
+		w.tableHeader()
+	}
+
+	x := pdfMargin
+	for i, col := range pdfColumns {
+		for j, line := range cells[i] {
+			w.doc.text(x+2, w.y+float64(j+1)*pdfLineHeight, fontRegular, pdfTableSize, line)
+		}
+		x += col.Share * tableWidth
+	}
+	w.y += height
+	w.doc.line(pdfMargin, w.y, pdfMargin+tableWidth, w.y)
+}
+
+// signOff draws the lines legal signs the document on
+func (w *pdfWriter) signOff() {
+	const height = 110.0
+	if !w.fits(height) {
+		w.newPage(w.width, w.height)
+	}
+	w.y += 30
+	w.doc.text(pdfMargin, w.y, fontBold, 11, "Sign-off")
+	w.y += 8
+	for _, label := range []string{"Reviewed by", "Signature", "Date"} {
+		w.y += 22
+		w.doc.text(pdfMargin, w.y, fontRegular, 9, label)
+		w.doc.line(pdfMargin+80, w.y+2, pdfMargin+320, w.y+2)
+	}
+}
+
+// licenseText draws the heading and full license text of a package, continuing on
+// new pages as needed
+func (w *pdfWriter) licenseText(info PackageInfo, text string) {
+	textWidth := w.width - 2*pdfMargin
+	heading := strings.Split(noticeHeading(info), "\n")
+	if !w.fits(float64(len(heading))*pdfLineHeight + 3*pdfTextLeading + 12) {
+		w.newPage(w.width, w.height)
+	} else if w.y > pdfMargin {
+		w.y += 12
+		w.doc.line(pdfMargin, w.y, pdfMargin+textWidth, w.y)
+		w.y += 6
+	}
+
+	for i, line := range heading {
+		font, size := fontRegular, 8.0
+		if i == 0 {
+			font, size = fontBold, 10.0
+		}
+		for _, wrapped := range font.wrapText(line, size, textWidth) {
+			w.y += pdfLineHeight + 1
+			w.doc.text(pdfMargin, w.y, font, size, wrapped)
+		}
+	}
+	w.y += 4
+
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\t", "    ", "\f", "").Replace(strings.TrimSpace(text))
+	for _, paragraph := range strings.Split(text, "\n") {
+		// Keep the indentation of the text, which the monospaced font shows as written
+		indent := paragraph[:len(paragraph)-len(strings.TrimLeft(paragraph, " "))]
+		for _, line := range fontMono.wrapText(paragraph, pdfTextSize, textWidth-fontMono.textWidth(indent, pdfTextSize)) {
+			if !w.fits(pdfTextLeading) {
+				w.newPage(w.width, w.height)
+			}
+			w.y += pdfTextLeading
+			w.doc.text(pdfMargin, w.y, fontMono, pdfTextSize, indent+line)
+		}
+	}
+}
+
+// writePDFReport writes the attribution document: the dependency table with a sign-off
+// block on landscape pages and, with texts, the full license text of every dependency on
+// portrait pages. It returns the name written and the packages whose text was not found.
+func writePDFReport(ctx context.Context, filename string, title string, infos []PackageInfo, texts bool, progress func(name string)) (string, []string, error) {
+	w := &pdfWriter{doc: newPDFDocument("Third-party software attribution: " + title)}
+	w.newPage(a4Height, a4Width)
+
+	w.y += 16
+	w.doc.text(pdfMargin, w.y, fontBold, 16, "Third-Party Software Attribution")
+	w.y += 18
+	w.doc.text(pdfMargin, w.y, fontRegular, 10, title)
+	w.y += 14
+	w.doc.text(pdfMargin, w.y, fontRegular, 9, fmt.Sprintf("%d components, generated %s", len(infos), time.Now().Format("2006-01-02 15:04")))
+	w.y += 14
+
+	w.tableHeader()
+	for _, info := range infos {
+		w.tableRow(info)
+	}
+	w.signOff()
+
+	var missing []string
+	if texts {
+		fetcher := newLicenseTextFetcher()
+		w.newPage(a4Width, a4Height)
+		w.y += 16
+		w.doc.text(pdfMargin, w.y, fontBold, 14, "License Texts")
+		w.y += 8
+		for _, info := range infos {
+			if progress != nil {
+				progress(info.Name)
+			}
+			text := fetcher.licenseText(ctx, info)
+			if err := ctx.Err(); err != nil {
+				return "", nil, err
+			}
+			if text == "" {
+				missing = append(missing, info.Name+"@"+info.Version)
+				text = "The license text of this component could not be found and must be added manually."
+			}
+			w.licenseText(info, text)
+		}
+	}
+
+	tmp, err := createTempFor(filename)
+	if err != nil {
+		return "", nil, err
+	}
+	err = w.doc.write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", nil, err
+	}
+	name, err := replaceFile(tmp.Name(), filename, true)
+	return name, missing, err
+}
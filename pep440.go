@@ -0,0 +1,382 @@
+package main
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pep440Version is a Python version as PEP 440 defines it, e.g. 1!2.0.1rc2.post1.dev3+local
+type pep440Version struct {
+	epoch   int
+	release []int
+	preTag  int // 0 for a, 1 for b, 2 for rc; -1 without a prerelease
+	preNum  int
+	post    int // -1 without a post-release
+	dev     int // -1 without a development release
+	local   string
+}
+
+// pep440Pattern is the version pattern of PEP 440's appendix, accepting the spellings
+// it normalizes such as 1.0-alpha.1, 1.0.post-1 or v1.0
+var pep440Pattern = regexp.MustCompile(`(?i)^\s*v?(?:([0-9]+)!)?([0-9]+(?:\.[0-9]+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?([0-9]+)?)?` +
+	`(?:-([0-9]+)|[-_.]?(post|rev|r)[-_.]?([0-9]+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?([0-9]+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?\s*$`)
+
+// parsePEP440 parses a version; ok is false for versions PEP 440 does not allow
+func parsePEP440(version string) (pep440Version, bool) {
+	m := pep440Pattern.FindStringSubmatch(version)
+	if m == nil {
+		return pep440Version{}, false
+	}
+	number := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	v := pep440Version{epoch: number(m[1]), preTag: -1, post: -1, dev: -1, local: strings.ToLower(m[10])}
+	for _, part := range strings.Split(m[2], ".") {
+		v.release = append(v.release, number(part))
+	}
+	switch strings.ToLower(m[3]) {
+	case "":
+	case "a", "alpha":
+		v.preTag = 0
+	case "b", "beta":
+		v.preTag = 1
+	default:
+		v.preTag = 2
+	}
+	v.preNum = number(m[4])
+	if m[5] != "" {
+		v.post = number(m[5])
+	} else if m[6] != "" {
+		v.post = number(m[7])
+	}
+	if m[8] != "" {
+		v.dev = number(m[9])
+	}
+	return v, true
+}
+
+// isPrerelease reports whether the version is a pre- or development release
+func (v pep440Version) isPrerelease() bool {
+	return v.preTag >= 0 || v.dev >= 0
+}
+
+// public returns the version without its local label
+func (v pep440Version) public() pep440Version {
+	v.local = ""
+	return v
+}
+
+// compareRelease compares release segments, padding the shorter one with zeros
+func compareRelease(a []int, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// hasReleasePrefix reports whether release starts with the segments of prefix, padding
+// release with zeros so 1 starts with 1.0
+func hasReleasePrefix(release []int, prefix []int) bool {
+	padded := slices.Clone(release)
+	for len(padded) < len(prefix) {
+		padded = append(padded, 0)
+	}
+	return slices.Equal(padded[:len(prefix)], prefix)
+}
+
+// comparePEP440 orders versions: 1.0.dev1 < 1.0a1 < 1.0 < 1.0.post1 < 1.0.post1+local
+func comparePEP440(a pep440Version, b pep440Version) int {
+	if c := cmp.Compare(a.epoch, b.epoch); c != 0 {
+		return c
+	}
+	if c := compareRelease(a.release, b.release); c != 0 {
+		return c
+	}
+	// A development release without a pre- or post-release comes before every
+	// prerelease of its version, and a final release after them
+	preKey := func(v pep440Version) [2]int {
+		switch {
+		case v.preTag >= 0:
+			return [2]int{v.preTag, v.preNum}
+		case v.post < 0 && v.dev >= 0:
+			return [2]int{-1, 0}
+		}
+		return [2]int{3, 0}
+	}
+	aPre, bPre := preKey(a), preKey(b)
+	if c := slices.Compare(aPre[:], bPre[:]); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.post, b.post); c != 0 {
+		return c
+	}
+	devKey := func(v pep440Version) int {
+		if v.dev < 0 {
+			return int(^uint(0) >> 1)
+		}
+		return v.dev
+	}
+	if c := cmp.Compare(devKey(a), devKey(b)); c != 0 {
+		return c
+	}
+	return compareLocal(a.local, b.local)
+}
+
+// compareLocal orders local labels segment by segment, numbers after names
+func compareLocal(a string, b string) int {
+	if a == "" || b == "" {
+		return cmp.Compare(len(a), len(b))
+	}
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == '-' || r == '_' })
+	}
+	as, bs := split(a), split(b)
+	for i := range min(len(as), len(bs)) {
+		x, xErr := strconv.Atoi(as[i])
+		y, yErr := strconv.Atoi(bs[i])
+		var c int
+		switch {
+		case xErr == nil && yErr == nil:
+			c = cmp.Compare(x, y)
+		case xErr == nil:
+			c = 1
+		case yErr == nil:
+			c = -1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+// pep440Clause is one comparison of a specifier set, such as >=2.0 or ==1.4.*
+type pep440Clause struct {
+	op      string // ~=, ==, !=, <=, >=, <, > or ===
+	version string
+	prefix  bool // == or != with a trailing .*
+}
+
+// pep440Operators are the comparison operators, longest first so ~= and === win
+var pep440Operators = []string{"===", "~=", "==", "!=", "<=", ">=", "<", ">"}
+
+// parsePEP440Specifier splits a specifier set such as ">=2.0,<3,!=2.1.*" into
+// clauses. Poetry's ^1.2 and ~1.2 and bare versions, which pyproject.toml files of
+// Poetry projects use, are accepted as well.
+func parsePEP440Specifier(spec string) ([]pep440Clause, bool) {
+	var clauses []pep440Clause
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.Join(strings.Fields(part), "")
+		if part == "" || part == "*" {
+			continue
+		}
+
+		op := ""
+		for _, candidate := range pep440Operators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		version := strings.TrimPrefix(part, op)
+		switch {
+		case op == "" && strings.HasPrefix(part, "^"):
+			poetry, ok := poetryCaret(part[1:])
+			if !ok {
+				return nil, false
+			}
+			clauses = append(clauses, poetry...)
+			continue
+		case op == "" && strings.HasPrefix(part, "~"):
+			poetry, ok := poetryTilde(part[1:])
+			if !ok {
+				return nil, false
+			}
+			clauses = append(clauses, poetry...)
+			continue
+		case op == "":
+			op = "=="
+		}
+
+		clause := pep440Clause{op: op, version: version}
+		if (op == "==" || op == "!=") && strings.HasSuffix(version, ".*") {
+			clause.prefix = true
+			clause.version = strings.TrimSuffix(version, ".*")
+		}
+		if op != "===" {
+			if _, ok := parsePEP440(clause.version); !ok {
+				return nil, false
+			}
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, true
+}
+
+// poetryBounds returns the release segments of a Poetry constraint and the upper bound
+// formed by bumping segment i, e.g. 1.2.3 bumped at 1 is 1.3
+func poetryBounds(version string, i int) (pep440Version, string, bool) {
+	v, ok := parsePEP440(version)
+	if !ok {
+		return v, "", false
+	}
+	upper := slices.Clone(v.release[:min(i+1, len(v.release))])
+	upper[len(upper)-1]++
+	parts := make([]string, len(upper))
+	for j, n := range upper {
+		parts[j] = strconv.Itoa(n)
+	}
+	return v, strings.Join(parts, "."), true
+}
+
+// poetryCaret expands Poetry's ^1.2.3 to >=1.2.3,<2.0.0, bumping the left-most
+// non-zero segment
+func poetryCaret(version string) ([]pep440Clause, bool) {
+	v, ok := parsePEP440(version)
+	if !ok {
+		return nil, false
+	}
+	i := slices.IndexFunc(v.release, func(n int) bool { return n != 0 })
+	if i < 0 {
+		i = len(v.release) - 1
+	}
+	_, upper, _ := poetryBounds(version, i)
+	return []pep440Clause{{op: ">=", version: version}, {op: "<", version: upper}}, true
+}
+
+// poetryTilde expands Poetry's ~1.2.3 to >=1.2.3,<1.3.0, and ~1 to >=1,<2
+func poetryTilde(version string) ([]pep440Clause, bool) {
+	v, upper, ok := poetryBounds(version, 1)
+	if !ok {
+		return nil, false
+	}
+	if len(v.release) == 1 {
+		_, upper, _ = poetryBounds(version, 0)
+	}
+	return []pep440Clause{{op: ">=", version: version}, {op: "<", version: upper}}, true
+}
+
+// matches reports whether a candidate version satisfies the clause
+func (c pep440Clause) matches(candidate pep440Version, raw string) bool {
+	if c.op == "===" {
+		return strings.EqualFold(strings.TrimSpace(raw), c.version)
+	}
+	spec, _ := parsePEP440(c.version)
+
+	switch c.op {
+	case "==", "!=":
+		var equal bool
+		switch {
+		case c.prefix:
+			// ==1.4.* compares the release segments the specifier names
+			equal = candidate.epoch == spec.epoch && hasReleasePrefix(candidate.release, spec.release)
+		case spec.local == "":
+			// Without a local label in the specifier the candidate's is ignored
+			equal = comparePEP440(candidate.public(), spec) == 0
+		default:
+			equal = comparePEP440(candidate, spec) == 0
+		}
+		return equal == (c.op == "==")
+	case "~=":
+		// ~=2.2.1 is >=2.2.1,==2.2.*
+		if len(spec.release) < 2 || comparePEP440(candidate.public(), spec) < 0 {
+			return false
+		}
+		return candidate.epoch == spec.epoch && hasReleasePrefix(candidate.release, spec.release[:len(spec.release)-1])
+	case "<=":
+		return comparePEP440(candidate.public(), spec) <= 0
+	case ">=":
+		return comparePEP440(candidate.public(), spec) >= 0
+	case "<":
+		// <3.0 does not admit 3.0's own prereleases unless it names a prerelease
+		if comparePEP440(candidate.public(), spec) >= 0 {
+			return false
+		}
+		return spec.isPrerelease() || !candidate.isPrerelease() || compareRelease(candidate.release, spec.release) != 0 || candidate.epoch != spec.epoch
+	case ">":
+		// >1.7 does not admit 1.7's post-releases unless it names a post-release
+		if comparePEP440(candidate.public(), spec) <= 0 {
+			return false
+		}
+		if spec.post < 0 && candidate.post >= 0 && candidate.epoch == spec.epoch && compareRelease(candidate.release, spec.release) == 0 && candidate.preTag == spec.preTag && candidate.preNum == spec.preNum {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// allowsPrereleases reports whether a specifier set names a prerelease itself, which
+// lets prereleases match
+func allowsPrereleases(clauses []pep440Clause) bool {
+	for _, c := range clauses {
+		if c.op == "!=" {
+			continue
+		}
+		if v, ok := parsePEP440(c.version); ok && v.isPrerelease() {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonRelease is a published version of a PyPI project
+type pythonRelease struct {
+	version string
+	yanked  bool // every file of the release was yanked (PEP 592)
+}
+
+// resolvePythonVersion picks the release pip installs for a specifier set: the highest
+// matching final release, or the highest matching prerelease when no final release
+// matches or the specifier names a prerelease. Yanked releases only satisfy a
+// specifier pinning them with == or ===. It returns "" when nothing matches.
+func resolvePythonVersion(spec string, releases []pythonRelease) string {
+	clauses, ok := parsePEP440Specifier(spec)
+	if !ok {
+		return ""
+	}
+	pinned := len(clauses) == 1 && (clauses[0].op == "===" || clauses[0].op == "==" && !clauses[0].prefix)
+
+	best := func(prereleases bool) string {
+		var bestRaw string
+		var bestVersion pep440Version
+		for _, release := range releases {
+			if release.yanked && !pinned {
+				continue
+			}
+			v, ok := parsePEP440(release.version)
+			if !ok || v.isPrerelease() && !prereleases {
+				continue
+			}
+			if !slices.ContainsFunc(clauses, func(c pep440Clause) bool { return !c.matches(v, release.version) }) &&
+				(bestRaw == "" || comparePEP440(v, bestVersion) > 0) {
+				bestRaw, bestVersion = release.version, v
+			}
+		}
+		return bestRaw
+	}
+
+	if version := best(allowsPrereleases(clauses)); version != "" {
+		return version
+	}
+	return best(true)
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// isCargoManifest reports whether filename is a Rust Cargo.toml or Cargo.lock
+func isCargoManifest(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "Cargo.toml" || base == "Cargo.lock"
+}
+
+// parseCargo parses a Cargo.toml, or the Cargo.lock next to it when there is one
+// because it pins the exact version of every crate in the build
+func parseCargo(filename string) ([]Package, string, error) {
+	if filepath.Base(filename) == "Cargo.toml" {
+		lock := filepath.Join(filepath.Dir(filename), "Cargo.lock")
+		if _, err := os.Stat(lock); err == nil {
+			return parseCargoLock(lock)
+		}
+		return parseCargoToml(filename)
+	}
+	return parseCargoLock(filename)
+}
+
+// cargoProjectName returns the package or directory name of the Cargo.toml in dir
+func cargoProjectName(dir string) (string, error) {
+	var manifest struct {
+		Package struct {
+			Name string `toml:"name"`
+		} `toml:"package"`
+	}
+	if data, err := ReadManifest(filepath.Join(dir, "Cargo.toml")); err == nil {
+		if toml.Unmarshal(data, &manifest) == nil && manifest.Package.Name != "" {
+			return manifest.Package.Name + "-rs", nil
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(abs) + "-rs", nil
+}
+
+// cargoDependency reads a dependency given as "1.0" or as a table with version, git,
+// path and package (the real crate name of a renamed dependency). ok is false for
+// path dependencies, which are part of the project.
+func cargoDependency(name string, spec any) (Package, bool) {
+	pkg := Package{Path: name}
+	switch spec := spec.(type) {
+	case string:
+		pkg.Version = spec
+	case map[string]any:
+		if _, ok := spec["path"]; ok {
+			return pkg, false
+		}
+		// Workspace inherited dependencies are declared in [workspace.dependencies]
+		if inherited, _ := spec["workspace"].(bool); inherited {
+			return pkg, false
+		}
+		if real, ok := spec["package"].(string); ok {
+			pkg.Path = real
+		}
+		pkg.Version, _ = spec["version"].(string)
+		if git, ok := spec["git"].(string); ok {
+			pkg.Registry = git
+			pkg.Homepage = git
+		}
+	}
+	return pkg, true
+}
+
+// parseCargoToml parses the dependency tables of a Cargo.toml, including workspace,
+// dev, build and target specific dependencies
+func parseCargoToml(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	type dependencies map[string]any
+	type table struct {
+		Dependencies      dependencies `toml:"dependencies"`
+		DevDependencies   dependencies `toml:"dev-dependencies"`
+		BuildDependencies dependencies `toml:"build-dependencies"`
+	}
+	var manifest struct {
+		table
+		Workspace struct {
+			Dependencies dependencies `toml:"dependencies"`
+		} `toml:"workspace"`
+		Target map[string]table `toml:"target"`
+	}
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	seen := make(map[string]bool)
+	add := func(deps dependencies, group string) {
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			pkg, ok := cargoDependency(name, deps[name])
+			if !ok || seen[pkg.Path+"@"+pkg.Version] {
+				continue
+			}
+			seen[pkg.Path+"@"+pkg.Version] = true
+			pkg.Group = group
+			packages = append(packages, pkg)
+		}
+	}
+
+	tables := []table{manifest.table}
+	targets := make([]string, 0, len(manifest.Target))
+	for target := range manifest.Target {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		tables = append(tables, manifest.Target[target])
+	}
+
+	add(manifest.Workspace.Dependencies, "normal")
+	for _, t := range tables {
+		add(t.Dependencies, "normal")
+		add(t.BuildDependencies, "build")
+		add(t.DevDependencies, "dev")
+	}
+
+	name, err := cargoProjectName(filepath.Dir(filename))
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
+
+// parseCargoLock parses a Cargo.lock. Crates without a source are the workspace's own.
+func parseCargoLock(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lock struct {
+		Package []struct {
+			Name    string `toml:"name"`
+			Version string `toml:"version"`
+			Source  string `toml:"source"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, entry := range lock.Package {
+		if entry.Source == "" {
+			continue
+		}
+		pkg := Package{Path: entry.Name, Version: entry.Version}
+		if git, ok := strings.CutPrefix(entry.Source, "git+"); ok {
+			// git+https://github.com/org/repo?branch=main#<commit>
+			git, _, _ = strings.Cut(git, "#")
+			git, _, _ = strings.Cut(git, "?")
+			pkg.Registry = git
+			pkg.Homepage = git
+		}
+		packages = append(packages, pkg)
+	}
+
+	name, err := cargoProjectName(filepath.Dir(filename))
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
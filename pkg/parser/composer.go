@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// isComposerManifest reports whether filename is a composer.json or composer.lock
+func isComposerManifest(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "composer.json" || base == "composer.lock"
+}
+
+// isComposerPlatformPackage reports whether a requirement names the PHP runtime or one
+// of its extensions rather than a Packagist package
+func isComposerPlatformPackage(name string) bool {
+	return name == "php" || !strings.Contains(name, "/") ||
+		strings.HasPrefix(name, "ext-") || strings.HasPrefix(name, "lib-")
+}
+
+// ComposerPackage is a package entry of composer.lock and of the Packagist API
+type ComposerPackage struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Homepage    string `json:"homepage"`
+	License     []string
+	Authors     []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	Source struct {
+		URL string `json:"url"`
+	} `json:"source"`
+}
+
+// UnmarshalJSON accepts license as a list or, in older packages, a single string
+func (p *ComposerPackage) UnmarshalJSON(data []byte) error {
+	type plain ComposerPackage
+	var raw struct {
+		plain
+		License any `json:"license"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = ComposerPackage(raw.plain)
+	switch license := raw.License.(type) {
+	case string:
+		p.License = []string{license}
+	case []any:
+		for _, l := range license {
+			if s, ok := l.(string); ok {
+				p.License = append(p.License, s)
+			}
+		}
+	}
+	return nil
+}
+
+// composerProjectName returns the name of the composer.json in dir, or the directory name
+func composerProjectName(dir string) (string, error) {
+	if data, err := ReadManifest(filepath.Join(dir, "composer.json")); err == nil {
+		var manifest struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &manifest) == nil && manifest.Name != "" {
+			return manifest.Name + "-php", nil
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(abs) + "-php", nil
+}
+
+// parseComposer parses a composer.json, or the composer.lock next to it when there is
+// one because it pins every installed package and already carries its metadata
+func parseComposer(filename string) ([]Package, string, error) {
+	if filepath.Base(filename) == "composer.json" {
+		lock := filepath.Join(filepath.Dir(filename), "composer.lock")
+		if _, err := os.Stat(lock); err == nil {
+			filename = lock
+		}
+	}
+	if filepath.Base(filename) == "composer.lock" {
+		return parseComposerLock(filename)
+	}
+	return parseComposerJSON(filename)
+}
+
+// parseComposerJSON reads the require and require-dev sections of a composer.json
+func parseComposerJSON(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var manifest struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, section := range []struct {
+		group    string
+		requires map[string]string
+	}{{groupDefault, manifest.Require}, {groupDev, manifest.RequireDev}} {
+		names := make([]string, 0, len(section.requires))
+		for name := range section.requires {
+			if !isComposerPlatformPackage(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			packages = append(packages, Package{Path: name, Version: section.requires[name], Group: section.group})
+		}
+	}
+
+	name, err := composerProjectName(filepath.Dir(filename))
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
+
+// parseComposerLock reads the packages and packages-dev of a composer.lock together
+// with the license, authors and source recorded for each
+func parseComposerLock(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lock struct {
+		Packages    []ComposerPackage `json:"packages"`
+		PackagesDev []ComposerPackage `json:"packages-dev"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, section := range []struct {
+		group    string
+		packages []ComposerPackage
+	}{{groupDefault, lock.Packages}, {groupDev, lock.PackagesDev}} {
+		for _, entry := range section.packages {
+			pkg := Package{
+				Path:        entry.Name,
+				Version:     entry.Version,
+				Group:       section.group,
+				License:     strings.Join(entry.License, " OR "),
+				Description: entry.Description,
+				Homepage:    entry.Source.URL,
+			}
+			if len(entry.Authors) > 0 {
+				pkg.Author = entry.Authors[0].Name
+			}
+			packages = append(packages, pkg)
+		}
+	}
+
+	name, err := composerProjectName(filepath.Dir(filename))
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
+
+// ExpandComposerVersions undoes the minification of Packagist's p2 metadata, where
+// each version only lists the fields that differ from the version before it
+func ExpandComposerVersions(minified []map[string]json.RawMessage) []ComposerPackage {
+	var versions []ComposerPackage
+	current := make(map[string]json.RawMessage)
+	for _, diff := range minified {
+		for key, value := range diff {
+			if string(value) == `"__unset"` {
+				delete(current, key)
+			} else {
+				current[key] = value
+			}
+		}
+		data, err := json.Marshal(current)
+		if err != nil {
+			continue
+		}
+		var version ComposerPackage
+		if json.Unmarshal(data, &version) == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
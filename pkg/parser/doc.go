@@ -0,0 +1,16 @@
+// Package parser reads the dependency files of many ecosystems into a common list of
+// packages: go.mod and Go binaries, package.json, npm/yarn lockfiles and node_modules,
+// pyproject.toml, requirements.txt, Pipfile and Poetry lockfiles, Python wheels, sdists
+// and environments, Cargo, Maven and Gradle, NuGet, Bundler, Composer, Unity, Yocto,
+// and the dpkg and apk databases of a root filesystem.
+//
+// Parse detects the kind of a single file; ScanFolder finds and parses every manifest
+// below a folder, and FindInstalled the copies of their packages installed in vendor/,
+// node_modules/ or a virtualenv, which ReadInstalled reads. None makes network requests:
+// fetching license metadata for the packages is left to the caller.
+package parser
+
+// NormalizeLicense maps the free-form license names some manifests declare, such as
+// Python core metadata, to SPDX identifiers. It returns names unchanged unless the
+// caller installs its own mapping.
+var NormalizeLicense = func(name string) string { return name }
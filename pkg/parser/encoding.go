@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// legacyEncodings are the non-Unicode code pages manifests edited on Windows are
+// commonly saved in, in order of preference when several decode equally well
+var legacyEncodings = []encoding.Encoding{
+	simplifiedchinese.GBK,
+	japanese.ShiftJIS,
+}
+
+// ReadManifest reads a manifest and transcodes it to UTF-8, so files saved as UTF-16,
+// GBK or Shift-JIS parse like any other. A UTF-8 byte order mark is dropped.
+func ReadManifest(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return toUTF8(data)
+}
+
+// toUTF8 detects the encoding of data and converts it to UTF-8
+func toUTF8(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return data[3:], nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		// The BOM overrides the default endianness
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder().Bytes(data)
+	}
+
+	// UTF-16 without BOM: ASCII text leaves every other byte zero
+	if len(data) >= 4 && len(data)%2 == 0 {
+		if data[1] == 0 && data[3] == 0 && data[0] != 0 {
+			return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder().Bytes(data)
+		}
+		if data[0] == 0 && data[2] == 0 && data[1] != 0 {
+			return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder().Bytes(data)
+		}
+	}
+
+	if utf8.Valid(data) {
+		return data, nil
+	}
+
+	// Pick the legacy code page that decodes with the fewest invalid sequences
+	var best []byte
+	bestScore := 0
+	for _, enc := range legacyEncodings {
+		decoded, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			continue
+		}
+		if score := decodingPenalty(decoded); best == nil || score < bestScore {
+			best, bestScore = decoded, score
+		}
+	}
+	if best == nil {
+		return data, nil
+	}
+	return best, nil
+}
+
+// decodingPenalty scores how implausible decoded text is: replacement characters mean
+// invalid input and half-width katakana is what GBK text usually turns into when read
+// as Shift-JIS, while Japanese text read correctly is full of kana
+func decodingPenalty(text []byte) int {
+	penalty := 0
+	for _, r := range string(text) {
+		switch {
+		case r == utf8.RuneError:
+			penalty += 10
+		case r >= 0xFF61 && r <= 0xFF9F:
+			penalty++
+		case r >= 0x3040 && r <= 0x30FF:
+			penalty--
+		}
+	}
+	return penalty
+}
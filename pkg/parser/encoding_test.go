@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestToUTF8(t *testing.T) {
+	const text = "requests==2.31.0  # 网络请求\n"
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]byte{
+		"UTF-8":          []byte(text),
+		"UTF-8 with BOM": append([]byte{0xEF, 0xBB, 0xBF}, text...),
+		"UTF-16LE":       utf16,
+		"GBK":            gbk,
+	}
+	for name, data := range tests {
+		got, err := toUTF8(data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(got) != text {
+			t.Errorf("%s: got %q, want %q", name, got, text)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"debug/buildinfo"
+	"path/filepath"
+	"strings"
+)
+
+// IsGoBinary reports whether filename is an executable carrying Go build info
+func IsGoBinary(filename string) bool {
+	_, err := buildinfo.ReadFile(filename)
+	return err == nil
+}
+
+// Parse the module build info embedded in a compiled Go binary, the same data
+// `go version -m` prints, so the report covers exactly the linked modules
+func parseGoBinary(filename string) ([]Package, string, error) {
+	info, err := buildinfo.ReadFile(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, dep := range info.Deps {
+		mod := dep
+		// Modules replaced by another published module are what actually got linked;
+		// local directory replacements have no version and keep the original path
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			mod = dep.Replace
+		}
+		packages = append(packages, Package{
+			Path:    mod.Path,
+			Version: mod.Version,
+			GoMod:   true,
+		})
+	}
+
+	name := info.Main.Path
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+
+	return packages, name + "-api", nil
+}
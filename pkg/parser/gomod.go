@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Parse go.mod file
+func parseGoMod(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Use ParseLax to allow unknown block types
+	file, err := modfile.ParseLax(filepath.Base(filename), data, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, req := range file.Require {
+		packages = append(packages, Package{
+			Path:    req.Mod.Path,
+			Version: req.Mod.Version,
+			GoMod:   true,
+		})
+	}
+
+	// Get module name from the parsed file
+	moduleName := file.Module.Mod.Path + "-api"
+
+	// The vendored modules are the whole build, with their license files at hand
+	if modules := goVendorManifest(filename); modules != "" {
+		slog.Debug("reading vendor/modules.txt next to go.mod", "file", modules)
+		vendored, err := parseGoVendor(modules)
+		if err != nil {
+			return nil, "", err
+		}
+		return vendored, moduleName, nil
+	}
+	return packages, moduleName, nil
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// goVendorManifest returns the vendor/modules.txt next to a go.mod, or "" when the
+// module does not vendor its dependencies
+func goVendorManifest(gomod string) string {
+	modules := filepath.Join(filepath.Dir(gomod), "vendor", "modules.txt")
+	if _, err := os.Stat(modules); err != nil {
+		return ""
+	}
+	return modules
+}
+
+// HasGoVendor reports whether the module of a go.mod vendors its dependencies, in
+// which case vendor/modules.txt already lists its whole build
+func HasGoVendor(gomod string) bool {
+	return goVendorManifest(gomod) != ""
+}
+
+// parseGoVendor reads vendor/modules.txt, the modules go mod vendor copied into the
+// build with the packages used from each. Modules none of whose packages are vendored
+// are not part of the build and are left out, as are those replaced by a folder of the
+// project. A module replaced by another is listed as the module whose code is vendored.
+// Modules go.mod does not require explicitly are indirect, as far as modules.txt tells.
+func parseGoVendor(filename string) ([]Package, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+	vendor := filepath.Dir(filename)
+
+	var packages []Package
+	var current *Package
+	vendored, explicit := false, false
+	flush := func() {
+		if current != nil && vendored {
+			packages = append(packages, *current)
+		}
+		current, vendored = nil, false
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "## "):
+			// ## explicit; go 1.21
+			if current != nil {
+				for annotation := range strings.SplitSeq(strings.TrimPrefix(line, "## "), ";") {
+					if strings.TrimSpace(annotation) == "explicit" {
+						current.Indirect, explicit = false, true
+					}
+				}
+			}
+		case strings.HasPrefix(line, "# "):
+			// # path version [=> replacement [version]]; the replacements of every version
+			// are listed again at the end as # path => replacement, without packages
+			flush()
+			module, replacement, replaced := strings.Cut(strings.TrimPrefix(line, "# "), "=>")
+			fields := strings.Fields(module)
+			if len(fields) == 0 {
+				continue
+			}
+			pkg := Package{Path: fields[0], GoMod: true, Indirect: true, Installed: filepath.Join(vendor, filepath.FromSlash(fields[0]))}
+			if len(fields) > 1 {
+				pkg.Version = fields[1]
+			}
+			if replaced {
+				target := strings.Fields(replacement)
+				// A replacement without a version is a folder of the project
+				if len(target) < 2 {
+					continue
+				}
+				pkg.Path, pkg.Version = target[0], target[1]
+			}
+			current = &pkg
+		default:
+			// A package vendored from the current module
+			vendored = true
+		}
+	}
+	flush()
+
+	// Before Go 1.17 modules.txt did not tell the requirements of go.mod apart
+	if !explicit {
+		for i := range packages {
+			packages[i].Indirect = false
+		}
+	}
+	return packages, nil
+}
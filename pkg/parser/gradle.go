@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// gradleConfigurations are the dependency configurations read from build files
+var gradleConfigurations = []string{
+	"api", "implementation", "compileOnly", "runtimeOnly", "compile", "runtime",
+	"testImplementation", "testCompileOnly", "testRuntimeOnly", "testCompile",
+	"androidTestImplementation", "kapt", "annotationProcessor", "classpath",
+}
+
+var (
+	// gradleStringNotation matches implementation 'g:a:v', implementation("g:a:v") and
+	// implementation 'g:a:' + version
+	gradleStringNotation = regexp.MustCompile(`^(\w+)\s*\(?\s*(?:platform\s*\(\s*)?["']([^"':\s]+):([^"':\s]+)(?::([^"'@\s]*))?(?:@\w+)?["'](?:\s*\+\s*(\w+))?`)
+	// gradleMapNotation matches implementation group: 'g', name: 'a', version: 'v' and
+	// the Kotlin named arguments group = "g", name = "a", version = "v"
+	gradleMapNotation = regexp.MustCompile(`^(\w+)\s*\(?\s*group\s*[:=]\s*["']([^"']+)["']\s*,\s*name\s*[:=]\s*["']([^"']+)["'](?:\s*,\s*version\s*[:=]\s*["']([^"']+)["'])?`)
+	// gradleCatalogNotation matches implementation(libs.some.library)
+	gradleCatalogNotation = regexp.MustCompile(`^(\w+)\s*\(?\s*libs\.([\w.]+)`)
+	// gradleVariable matches def x = '1.0', val x = "1.0", ext.x = '1.0' and x = '1.0' in ext blocks
+	gradleVariable = regexp.MustCompile(`^(?:def\s+|val\s+|var\s+|ext\.|extra\[")?(\w+)"?\]?\s*=\s*["']([^"'$]*)["']`)
+	// gradleInterpolation matches $name and ${name} in Groovy and Kotlin strings
+	gradleInterpolation = regexp.MustCompile(`\$\{?(\w+(?:\.\w+)*)\}?`)
+)
+
+// isGradleBuild reports whether filename is a Gradle build script or lockfile
+func isGradleBuild(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "build.gradle" || base == "build.gradle.kts" || base == "gradle.lockfile"
+}
+
+// gradleProjectName returns rootProject.name from the settings file in dir, falling back
+// to the directory name
+func gradleProjectName(dir string) (string, error) {
+	pattern := regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+	for _, settings := range []string{"settings.gradle", "settings.gradle.kts"} {
+		if data, err := ReadManifest(filepath.Join(dir, settings)); err == nil {
+			if match := pattern.FindSubmatch(data); match != nil {
+				return string(match[1]) + "-java", nil
+			}
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(abs) + "-java", nil
+}
+
+// parseGradle parses a Gradle build script, or the gradle.lockfile next to it when
+// dependency locking is enabled, because it pins every resolved module
+func parseGradle(filename string) ([]Package, string, error) {
+	if filepath.Base(filename) != "gradle.lockfile" {
+		lock := filepath.Join(filepath.Dir(filename), "gradle.lockfile")
+		if _, err := os.Stat(lock); err == nil {
+			filename = lock
+		}
+	}
+	if filepath.Base(filename) == "gradle.lockfile" {
+		return parseGradleLockfile(filename)
+	}
+	return parseGradleScript(filename)
+}
+
+// parseGradleLockfile parses a gradle.lockfile, whose lines read
+// group:artifact:version=configuration,configuration
+func parseGradleLockfile(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "empty=") {
+			continue
+		}
+		coordinates, configurations, _ := strings.Cut(line, "=")
+		parts := strings.Split(coordinates, ":")
+		if len(parts) != 3 {
+			continue
+		}
+		packages = append(packages, Package{
+			Path:    parts[0] + ":" + parts[1],
+			Version: parts[2],
+			Group:   configurations,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+
+	name, err := gradleProjectName(filepath.Dir(filename))
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
+
+// gradleCatalog maps the accessor of each library in gradle/libs.versions.toml
+// (libs.some.library for some-library) to its coordinates and version
+func gradleCatalog(dir string) map[string]Package {
+	catalog := make(map[string]Package)
+	data, err := ReadManifest(filepath.Join(dir, "gradle", "libs.versions.toml"))
+	if err != nil {
+		return catalog
+	}
+
+	var file struct {
+		Versions  map[string]string `toml:"versions"`
+		Libraries map[string]any    `toml:"libraries"`
+	}
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return catalog
+	}
+
+	for alias, spec := range file.Libraries {
+		var pkg Package
+		switch spec := spec.(type) {
+		case string:
+			parts := strings.Split(spec, ":")
+			if len(parts) < 2 {
+				continue
+			}
+			pkg.Path = parts[0] + ":" + parts[1]
+			if len(parts) > 2 {
+				pkg.Version = parts[2]
+			}
+		case map[string]any:
+			if module, ok := spec["module"].(string); ok {
+				pkg.Path = module
+			} else {
+				group, _ := spec["group"].(string)
+				name, _ := spec["name"].(string)
+				pkg.Path = group + ":" + name
+			}
+			switch version := spec["version"].(type) {
+			case string:
+				pkg.Version = version
+			case map[string]any:
+				if ref, ok := version["ref"].(string); ok {
+					pkg.Version = file.Versions[ref]
+				}
+			}
+			if ref, ok := spec["version.ref"].(string); ok {
+				pkg.Version = file.Versions[ref]
+			}
+		}
+		// Gradle turns -, _ and . in aliases into accessor segments
+		accessor := strings.NewReplacer("-", ".", "_", ".").Replace(alias)
+		catalog[accessor] = pkg
+	}
+	return catalog
+}
+
+// parseGradleScript reads the dependency declarations of build.gradle or
+// build.gradle.kts in string, map and version catalog notation. Versions held in
+// variables, ext properties or gradle.properties are substituted.
+func parseGradleScript(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	dir := filepath.Dir(filename)
+
+	variables := make(map[string]string)
+	if properties, err := ReadManifest(filepath.Join(dir, "gradle.properties")); err == nil {
+		for line := range strings.SplitSeq(string(properties), "\n") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok && !strings.HasPrefix(key, "#") {
+				variables[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lines = append(lines, line)
+		if match := gradleVariable.FindStringSubmatch(line); match != nil {
+			variables[match[1]] = match[2]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+
+	interpolate := func(value string) string {
+		return gradleInterpolation.ReplaceAllStringFunc(value, func(ref string) string {
+			name := strings.Trim(ref, "${}")
+			name = strings.TrimPrefix(name, "rootProject.ext.")
+			name = strings.TrimPrefix(name, "project.ext.")
+			if value, ok := variables[name]; ok {
+				return value
+			}
+			return ref
+		})
+	}
+
+	configurations := make(map[string]bool)
+	for _, configuration := range gradleConfigurations {
+		configurations[configuration] = true
+	}
+	// The version catalog sits in the root project, which may be the parent directory
+	catalog := gradleCatalog(dir)
+	if len(catalog) == 0 {
+		catalog = gradleCatalog(filepath.Dir(dir))
+	}
+
+	var packages []Package
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		var pkg Package
+		var configuration string
+		if match := gradleMapNotation.FindStringSubmatch(line); match != nil {
+			configuration = match[1]
+			pkg = Package{Path: interpolate(match[2]) + ":" + interpolate(match[3]), Version: interpolate(match[4])}
+		} else if match := gradleStringNotation.FindStringSubmatch(line); match != nil {
+			configuration = match[1]
+			pkg = Package{Path: interpolate(match[2]) + ":" + interpolate(match[3]), Version: interpolate(match[4])}
+			if pkg.Version == "" && match[5] != "" {
+				pkg.Version = variables[match[5]]
+			}
+		} else if match := gradleCatalogNotation.FindStringSubmatch(line); match != nil {
+			configuration = match[1]
+			var ok bool
+			if pkg, ok = catalog[match[2]]; !ok {
+				continue
+			}
+		} else {
+			continue
+		}
+		if !configurations[configuration] {
+			continue
+		}
+		pkg.Group = configuration
+		if seen[pkg.Path+"@"+pkg.Version] {
+			continue
+		}
+		seen[pkg.Path+"@"+pkg.Version] = true
+		packages = append(packages, pkg)
+	}
+	sort.SliceStable(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+
+	name, err := gradleProjectName(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
@@ -0,0 +1,315 @@
+package parser
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// virtualenvDirs are the folders next to a manifest that conventionally hold the
+// project's Python virtual environment
+var virtualenvDirs = []string{".venv", "venv", "env", ".env"}
+
+// FindInstalled looks for the copy of each package a build left in its project folder:
+// vendor/ for Go modules, node_modules/ for npm (also in the folders above, where
+// workspaces hoist packages) and the site-packages of a virtual environment, .venv or
+// the one $VIRTUAL_ENV names, for Python. It sets Installed to the folder of the copy.
+// A copy of another version than the one the manifest pins is ignored.
+//
+// root is the folder of the manifest, or for packages of ScanFolder the folder scanned,
+// their Project giving the manifests below it. ecosystem applies to the packages
+// without a RepositoryType of their own.
+func FindInstalled(root string, ecosystem string, packages []Package) {
+	sitePackages := make(map[string][]string)
+	found := 0
+	for i := range packages {
+		pkg := &packages[i]
+		// Parsers reading the installed packages themselves set it already
+		if pkg.Installed != "" {
+			found++
+			continue
+		}
+		repositoryType := pkg.RepositoryType
+		if repositoryType == "" {
+			repositoryType = ecosystem
+		}
+		for _, dir := range projectDirs(root, pkg.Project) {
+			switch repositoryType {
+			case "go":
+				pkg.Installed = findVendored(dir, *pkg)
+			case "npm":
+				pkg.Installed = findNodeModule(root, dir, *pkg)
+			case "pypi":
+				if _, ok := sitePackages[dir]; !ok {
+					sitePackages[dir] = findSitePackages(dir)
+				}
+				pkg.Installed = findDistInfo(sitePackages[dir], *pkg)
+			}
+			if pkg.Installed != "" {
+				found++
+				break
+			}
+		}
+	}
+	slog.Debug("installed copies", "root", root, "found", found, "packages", len(packages))
+}
+
+// ReadInstalled reads the metadata an installed copy declares: the package.json of an
+// npm package or the METADATA of a Python distribution. Vendored Go modules declare
+// nothing but their license files, so only their path comes back.
+func ReadInstalled(dir string, ecosystem string) (Package, bool) {
+	switch ecosystem {
+	case "npm":
+		return readNodeModule(dir)
+	case "pypi":
+		return readDistInfo(dir)
+	case "go":
+		return Package{}, dir != ""
+	}
+	return Package{}, false
+}
+
+// projectDirs returns the folders of the manifests listing a package
+func projectDirs(root string, project string) []string {
+	if project == "" {
+		return []string{root}
+	}
+	var dirs []string
+	for manifest := range strings.SplitSeq(project, "; ") {
+		dirs = append(dirs, filepath.Join(root, filepath.Dir(filepath.FromSlash(manifest))))
+	}
+	return dirs
+}
+
+// versionMatches reports whether an installed version satisfies the version a manifest
+// asks for: only the same one does for pinned versions, any for ranges, tags and URLs
+func versionMatches(wanted string, installed string) bool {
+	wanted = strings.TrimPrefix(strings.TrimLeft(strings.TrimSpace(wanted), "="), "v")
+	if wanted == "" || wanted[0] < '0' || wanted[0] > '9' || strings.ContainsAny(wanted, "^~<>*|xX, !") {
+		return true
+	}
+	return strings.EqualFold(wanted, strings.TrimPrefix(installed, "v"))
+}
+
+// findVendored returns the vendor/ folder of a Go module
+func findVendored(dir string, pkg Package) string {
+	vendored := filepath.Join(dir, "vendor", filepath.FromSlash(pkg.Path))
+	if info, err := os.Stat(vendored); err == nil && info.IsDir() {
+		return vendored
+	}
+	return ""
+}
+
+// findNodeModule returns the node_modules/ folder of an npm package, looking in dir and
+// then in the folders above it up to root, as Node.js resolves packages
+func findNodeModule(root string, dir string, pkg Package) string {
+	for {
+		installed := filepath.Join(dir, "node_modules", filepath.FromSlash(pkg.Path))
+		if found, ok := readNodeModule(installed); ok && versionMatches(pkg.Version, found.Version) {
+			return installed
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// nodeModuleManifest is the part of an installed package's package.json read for its
+// metadata and dependencies. License is a string, or in old packages an object with a type.
+type nodeModuleManifest struct {
+	Name        string          `json:"name"`
+	Version     string          `json:"version"`
+	Description string          `json:"description"`
+	Homepage    string          `json:"homepage"`
+	License     json.RawMessage `json:"license"`
+	Licenses    []struct {
+		Type string `json:"type"`
+	} `json:"licenses"`
+	Author               json.RawMessage   `json:"author"`
+	Repository           json.RawMessage   `json:"repository"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// readNodeModuleManifest reads the package.json of an installed npm package
+func readNodeModuleManifest(dir string) (nodeModuleManifest, bool) {
+	var manifest nodeModuleManifest
+	data, err := ReadManifest(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return manifest, false
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest.Name == "" {
+		return manifest, false
+	}
+	return manifest, true
+}
+
+// readNodeModule reads the metadata of an installed npm package
+func readNodeModule(dir string) (Package, bool) {
+	manifest, ok := readNodeModuleManifest(dir)
+	if !ok {
+		return Package{}, false
+	}
+	return manifest.pkg(), true
+}
+
+// pkg returns the package the manifest describes
+func (m nodeModuleManifest) pkg() Package {
+	pkg := Package{
+		Path:        m.Name,
+		Version:     m.Version,
+		Description: m.Description,
+		License:     stringOrField(m.License, "type"),
+		Author:      stringOrField(m.Author, "name"),
+		Homepage:    stringOrField(m.Repository, "url"),
+	}
+	if pkg.License == "" && len(m.Licenses) > 0 {
+		pkg.License = m.Licenses[0].Type
+	}
+	if pkg.Homepage == "" {
+		pkg.Homepage = m.Homepage
+	}
+	return pkg
+}
+
+// stringOrField decodes a package.json field given either as a string or as an object,
+// of which it returns the named field
+func stringOrField(raw json.RawMessage, field string) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var object map[string]any
+	if json.Unmarshal(raw, &object) == nil {
+		text, _ = object[field].(string)
+	}
+	return text
+}
+
+// findSitePackages returns the site-packages folders of the virtual environments of a
+// project: those next to its manifest and the active one
+func findSitePackages(dir string) []string {
+	var envs []string
+	for _, name := range virtualenvDirs {
+		envs = append(envs, filepath.Join(dir, name))
+	}
+	if active := os.Getenv("VIRTUAL_ENV"); active != "" {
+		envs = append(envs, active)
+	}
+
+	var folders []string
+	for _, env := range envs {
+		folders = append(folders, envSitePackages(env)...)
+	}
+	return folders
+}
+
+// envSitePackages returns the site-packages folders of a virtual environment:
+// lib/pythonX.Y/site-packages on Unix, Lib/site-packages on Windows
+func envSitePackages(env string) []string {
+	var folders []string
+	matches, _ := filepath.Glob(filepath.Join(env, "lib", "python*", "site-packages"))
+	matches = append(matches, filepath.Join(env, "Lib", "site-packages"))
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() && !slices.Contains(folders, match) {
+			folders = append(folders, match)
+		}
+	}
+	return folders
+}
+
+// distNameSeparators are the runs of characters PEP 503 treats as one separator
+var distNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizeDistName normalizes a Python distribution name for comparison
+func normalizeDistName(name string) string {
+	return strings.ToLower(distNameSeparators.ReplaceAllString(name, "-"))
+}
+
+// findDistInfo returns the .dist-info folder of a Python distribution in the first
+// site-packages folder that has it installed
+func findDistInfo(sitePackages []string, pkg Package) string {
+	wanted := normalizeDistName(pkg.Path)
+	for _, folder := range sitePackages {
+		entries, err := os.ReadDir(folder)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			// <name>-<version>.dist-info, the name having its dashes replaced
+			stem, ok := strings.CutSuffix(entry.Name(), ".dist-info")
+			if !entry.IsDir() || !ok {
+				continue
+			}
+			name, version, ok := strings.Cut(stem, "-")
+			if ok && normalizeDistName(name) == wanted && versionMatches(pkg.Version, version) {
+				return filepath.Join(folder, entry.Name())
+			}
+		}
+	}
+	return ""
+}
+
+// readDistInfo reads the METADATA of an installed Python distribution, or the PKG-INFO
+// of an .egg-info folder, with its first license file for the copyright: at the top of
+// the folder, in its licenses/ folder since PEP 639, or else among the files its
+// RECORD installed
+func readDistInfo(dir string) (Package, bool) {
+	metadata, err := os.ReadFile(filepath.Join(dir, "METADATA"))
+	if err != nil {
+		if metadata, err = os.ReadFile(filepath.Join(dir, "PKG-INFO")); err != nil {
+			return Package{}, false
+		}
+	}
+	var license []byte
+	for _, folder := range []string{dir, filepath.Join(dir, "licenses")} {
+		entries, _ := os.ReadDir(folder)
+		for _, entry := range entries {
+			if !entry.IsDir() && IsLicenseFile(entry.Name()) && license == nil {
+				license, _ = os.ReadFile(filepath.Join(folder, entry.Name()))
+			}
+		}
+	}
+	if recorded := RecordedLicenseFiles(dir); license == nil && len(recorded) > 0 {
+		license, _ = os.ReadFile(recorded[0])
+	}
+	return pythonDistPackage(metadata, license)
+}
+
+// RecordedLicenseFiles returns the license files the RECORD of an installed Python
+// distribution lists outside its .dist-info folder, such as a LICENSE shipped inside
+// the package's own folder, the shallowest first
+func RecordedLicenseFiles(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, "RECORD"))
+	if err != nil {
+		return nil
+	}
+	sitePackages := filepath.Dir(dir)
+	var files []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		// path,hash,size with paths relative to site-packages
+		name, _, _ := strings.Cut(strings.TrimSpace(line), ",")
+		name = strings.Trim(name, `"`)
+		if name == "" || strings.HasPrefix(name, filepath.Base(dir)+"/") || strings.HasPrefix(name, "..") || !IsLicenseFile(name) {
+			continue
+		}
+		// Modules named after licenses, e.g. license.py, are code
+		if ext := strings.ToLower(path.Ext(name)); ext == ".py" || ext == ".pyc" || ext == ".pyi" || ext == ".so" || ext == ".pyd" {
+			continue
+		}
+		files = append(files, name)
+	}
+	slices.SortStableFunc(files, func(a, b string) int { return strings.Count(a, "/") - strings.Count(b, "/") })
+	for i, name := range files {
+		files[i] = filepath.Join(sitePackages, filepath.FromSlash(name))
+	}
+	return files
+}
@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isMavenPOM reports whether filename is a Maven pom.xml
+func isMavenPOM(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "pom.xml" || strings.HasSuffix(base, ".pom")
+}
+
+// mavenDependency is a <dependency> of a POM
+type mavenDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Type       string `xml:"type"`
+	Scope      string `xml:"scope"`
+}
+
+// POM holds the parts of a Maven POM the tool reads
+type POM struct {
+	GroupID     string `xml:"groupId"`
+	ArtifactID  string `xml:"artifactId"`
+	Version     string `xml:"version"`
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+	URL         string `xml:"url"`
+	Parent      struct {
+		GroupID      string `xml:"groupId"`
+		ArtifactID   string `xml:"artifactId"`
+		Version      string `xml:"version"`
+		RelativePath string `xml:"relativePath"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies []mavenDependency `xml:"dependencies>dependency"`
+	Managed      []mavenDependency `xml:"dependencyManagement>dependencies>dependency"`
+	Licenses     []struct {
+		Name string `xml:"name"`
+		URL  string `xml:"url"`
+	} `xml:"licenses>license"`
+	Organization struct {
+		Name string `xml:"name"`
+	} `xml:"organization"`
+	SCM struct {
+		URL string `xml:"url"`
+	} `xml:"scm"`
+	Developers []struct {
+		Name         string `xml:"name"`
+		Organization string `xml:"organization"`
+	} `xml:"developers>developer"`
+}
+
+// Variables returns the values ${...} placeholders of the POM may refer to
+func (p *POM) Variables() map[string]string {
+	props := make(map[string]string)
+	for _, entry := range p.Properties.Entries {
+		props[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+
+	groupID, version := p.GroupID, p.Version
+	if groupID == "" {
+		groupID = p.Parent.GroupID
+	}
+	if version == "" {
+		version = p.Parent.Version
+	}
+	for _, prefix := range []string{"project.", "pom.", ""} {
+		props[prefix+"groupId"] = groupID
+		props[prefix+"artifactId"] = p.ArtifactID
+		props[prefix+"version"] = version
+	}
+	props["project.parent.groupId"] = p.Parent.GroupID
+	props["project.parent.version"] = p.Parent.Version
+	return props
+}
+
+// InterpolatePOM replaces ${name} placeholders, following properties that refer to
+// other properties
+func InterpolatePOM(value string, props map[string]string) string {
+	for range 10 {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			break
+		}
+		key := value[start+2 : start+end]
+		replacement, ok := props[key]
+		if !ok {
+			break
+		}
+		value = value[:start] + replacement + value[start+end+1:]
+	}
+	return strings.TrimSpace(value)
+}
+
+// readPOM reads and decodes a POM file
+func readPOM(filename string) (*POM, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+	var pom POM
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+	return &pom, nil
+}
+
+// parseMavenPOM parses the dependencies and dependencyManagement of a pom.xml,
+// interpolating ${...} properties. A parent POM found in the source tree contributes
+// its properties and managed versions.
+func parseMavenPOM(filename string) ([]Package, string, error) {
+	pom, err := readPOM(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	props := pom.Variables()
+	managed := pom.Managed
+
+	// The parent's properties apply unless the child overrides them
+	if pom.Parent.ArtifactID != "" {
+		relative := pom.Parent.RelativePath
+		if relative == "" {
+			relative = "../pom.xml"
+		}
+		parentFile := filepath.Join(filepath.Dir(filename), relative)
+		if info, err := os.Stat(parentFile); err == nil && info.IsDir() {
+			parentFile = filepath.Join(parentFile, "pom.xml")
+		}
+		if parent, err := readPOM(parentFile); err == nil && parent.ArtifactID == pom.Parent.ArtifactID {
+			for key, value := range parent.Variables() {
+				if _, ok := props[key]; !ok {
+					props[key] = value
+				}
+			}
+			managed = append(managed, parent.Managed...)
+		}
+	}
+
+	managedVersions := make(map[string]string)
+	for _, dep := range managed {
+		key := InterpolatePOM(dep.GroupID, props) + ":" + InterpolatePOM(dep.ArtifactID, props)
+		if _, ok := managedVersions[key]; !ok {
+			managedVersions[key] = InterpolatePOM(dep.Version, props)
+		}
+	}
+
+	var packages []Package
+	seen := make(map[string]bool)
+	add := func(dep mavenDependency, group string) {
+		name := InterpolatePOM(dep.GroupID, props) + ":" + InterpolatePOM(dep.ArtifactID, props)
+		version := InterpolatePOM(dep.Version, props)
+		if version == "" {
+			version = managedVersions[name]
+		}
+		if seen[name+"@"+version] {
+			return
+		}
+		seen[name+"@"+version] = true
+		packages = append(packages, Package{Path: name, Version: version, Group: group})
+	}
+
+	for _, dep := range pom.Dependencies {
+		scope := dep.Scope
+		if scope == "" {
+			scope = "compile"
+		}
+		add(dep, scope)
+	}
+	for _, dep := range pom.Managed {
+		// Imported BOMs only contribute versions, they are not dependencies themselves
+		if dep.Scope == "import" {
+			continue
+		}
+		// Managed artifacts already listed as dependencies are skipped by add
+		add(dep, "managed")
+	}
+
+	name := pom.ArtifactID
+	if name == "" {
+		name = filepath.Base(filepath.Dir(filename))
+	}
+	return packages, name + "-java", nil
+}
@@ -0,0 +1,255 @@
+package parser
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsNodeModules reports whether filename is a node_modules folder, or the hidden
+// lockfile npm keeps in it, either of which is parsed by walking the installed packages
+func IsNodeModules(filename string) bool {
+	if filepath.Base(filename) == ".package-lock.json" {
+		return filepath.Base(filepath.Dir(filename)) == "node_modules"
+	}
+	if filepath.Base(filename) != "node_modules" {
+		return false
+	}
+	info, err := os.Stat(filename)
+	return err == nil && info.IsDir()
+}
+
+// installedNodeModule is a package folder found by walking node_modules
+type installedNodeModule struct {
+	pkg  Package
+	key  string // folder relative to the project, e.g. node_modules/a/node_modules/b
+	deps []string
+}
+
+// nodeModulesTree is the installed packages of a project, indexed by every folder they
+// can be reached at: their own and, for pnpm, the symlinks pointing to them
+type nodeModulesTree struct {
+	project string // absolute folder holding the node_modules folder walked
+	modules []installedNodeModule
+	byKey   map[string]int
+}
+
+// parseNodeModules walks a node_modules folder, reading the package.json of every
+// package installed in it, nested versions and the .pnpm store included. Each version
+// is listed once, with the license its package.json declares and the folder it is
+// installed in. The project's package.json, if any, tells the direct dependencies,
+// and the scope of the others follows from which of them they are installed for.
+func parseNodeModules(filename string) ([]Package, string, error) {
+	dir := filename
+	if filepath.Base(filename) != "node_modules" {
+		dir = filepath.Dir(filename)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	tree := &nodeModulesTree{project: filepath.Dir(abs), byKey: make(map[string]int)}
+	if err := tree.walk(abs); err != nil {
+		return nil, "", err
+	}
+
+	// The project and its workspaces declare the direct dependencies
+	name := filepath.Base(tree.project)
+	roots := map[string]packageJSONManifest{}
+	if root, err := readPackageJSON(filepath.Join(tree.project, "package.json")); err == nil {
+		if root.Name != "" {
+			name = root.Name
+		}
+		roots[""] = root
+		folders, _ := root.workspaceFolders(tree.project)
+		for _, folder := range folders {
+			manifest, err := readPackageJSON(filepath.Join(tree.project, filepath.FromSlash(folder), "package.json"))
+			if err != nil {
+				continue
+			}
+			roots[folder] = manifest
+			tree.walk(filepath.Join(tree.project, filepath.FromSlash(folder), "node_modules"))
+		}
+	}
+	scopes, direct := tree.scopes(roots)
+
+	var packages []Package
+	index := make(map[string]int)
+	for i, module := range tree.modules {
+		pkg := module.pkg
+		pkg.Scope = scopes[i]
+		pkg.Indirect = len(roots) > 0 && !direct[i]
+		id := pkg.Path + "@" + pkg.Version
+		if j, ok := index[id]; ok {
+			packages[j].Scope = unionScope(packages[j].Scope, pkg.Scope)
+			packages[j].Indirect = packages[j].Indirect && pkg.Indirect
+			continue
+		}
+		index[id] = len(packages)
+		packages = append(packages, pkg)
+	}
+	return packages, name + "-ui", nil
+}
+
+// unionScope returns the scope of a version installed at two places: the one closer
+// to shipping with the project, or the known one when the other is unknown
+func unionScope(a string, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return broaderScope(a, b)
+}
+
+// walk reads the packages of a node_modules folder and of those nested in them
+func (t *nodeModulesTree) walk(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case name == ".pnpm":
+			// pnpm installs every version in .pnpm/<name>@<version>/node_modules/<name>
+			stores, _ := filepath.Glob(filepath.Join(dir, name, "*", "node_modules"))
+			for _, store := range stores {
+				if err := t.walk(store); err != nil {
+					return err
+				}
+			}
+		case strings.HasPrefix(name, "."):
+			// .bin, .cache, the hidden lockfile and the like
+		case strings.HasPrefix(name, "@"):
+			scoped, _ := os.ReadDir(filepath.Join(dir, name))
+			for _, entry := range scoped {
+				if err := t.visit(filepath.Join(dir, name, entry.Name())); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := t.visit(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// visit reads the package installed in folder, once for all the symlinks to it.
+// Symlinks leading out of node_modules are workspace packages of the project itself.
+func (t *nodeModulesTree) visit(folder string) error {
+	key := t.key(folder)
+	target, err := filepath.EvalSymlinks(folder)
+	if err != nil {
+		return nil
+	}
+	targetKey := t.key(target)
+	if !strings.HasPrefix(targetKey, "node_modules/") && !strings.Contains(targetKey, "/node_modules/") {
+		return nil
+	}
+	if i, ok := t.byKey[targetKey]; ok {
+		t.byKey[key] = i
+		return nil
+	}
+
+	manifest, ok := readNodeModuleManifest(target)
+	if !ok {
+		return nil
+	}
+	pkg := manifest.pkg()
+	pkg.Installed = target
+
+	module := installedNodeModule{pkg: pkg, key: targetKey}
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.OptionalDependencies, manifest.PeerDependencies} {
+		for dep := range deps {
+			module.deps = append(module.deps, dep)
+		}
+	}
+	sort.Strings(module.deps)
+	t.byKey[targetKey] = len(t.modules)
+	t.byKey[key] = len(t.modules)
+	t.modules = append(t.modules, module)
+
+	return t.walk(filepath.Join(target, "node_modules"))
+}
+
+// key returns the folder relative to the project with forward slashes
+func (t *nodeModulesTree) key(folder string) string {
+	rel, err := filepath.Rel(t.project, folder)
+	if err != nil {
+		return filepath.ToSlash(folder)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// resolve finds the package name required from the folder at key, as Node.js does: in
+// the node_modules folder inside it, then in those of the folders above it
+func (t *nodeModulesTree) resolve(from string, name string) (int, bool) {
+	for dir := from; ; {
+		if i, ok := t.byKey[path.Join(dir, "node_modules", name)]; ok {
+			return i, true
+		}
+		if dir == "" {
+			return 0, false
+		}
+		// Move up to the package the folder is installed in, or the project
+		if i := strings.LastIndex(dir, "/node_modules/"); i >= 0 {
+			dir = dir[:i]
+		} else {
+			dir = ""
+		}
+	}
+}
+
+// scopes follows the dependencies of the project and its workspaces (roots, by folder)
+// through the tree: a package reached from a runtime dependency is runtime, else
+// optional, peer or dev after the dependency it is reached from, and unknown when
+// nothing declared reaches it. It also returns which packages are declared directly.
+func (t *nodeModulesTree) scopes(roots map[string]packageJSONManifest) ([]string, []bool) {
+	scopes := make([]string, len(t.modules))
+	direct := make([]bool, len(t.modules))
+
+	folders := make([]string, 0, len(roots))
+	for folder := range roots {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+
+	// Each pass marks what the packages of one scope reach, in the order of scopeRank
+	for _, scope := range []string{ScopeRuntime, ScopeOptional, ScopePeer, ScopeDev} {
+		var queue []int
+		for _, folder := range folders {
+			for _, pkg := range roots[folder].packages() {
+				if pkg.Scope != scope {
+					continue
+				}
+				if i, ok := t.resolve(folder, pkg.Path); ok {
+					direct[i] = true
+					if scopes[i] == "" {
+						scopes[i] = scope
+						queue = append(queue, i)
+					}
+				}
+			}
+		}
+		for len(queue) > 0 {
+			module := t.modules[queue[0]]
+			queue = queue[1:]
+			for _, dep := range module.deps {
+				if i, ok := t.resolve(module.key, dep); ok && scopes[i] == "" {
+					scopes[i] = scope
+					queue = append(queue, i)
+				}
+			}
+		}
+	}
+	return scopes, direct
+}
@@ -0,0 +1,232 @@
+package parser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// msbuildProperty matches $(Name) references in MSBuild values
+var msbuildProperty = regexp.MustCompile(`\$\((\w+)\)`)
+
+// isNuGetProject reports whether filename is an MSBuild project, a central package
+// management file or a NuGet lockfile
+func isNuGetProject(filename string) bool {
+	base := filepath.Base(filename)
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".csproj", ".fsproj", ".vbproj":
+		return true
+	}
+	return base == "Directory.Packages.props" || base == "packages.lock.json"
+}
+
+// msbuildProject holds the package references and properties of an MSBuild file
+type msbuildProject struct {
+	PropertyGroups []struct {
+		Properties []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"PropertyGroup"`
+	ItemGroups []struct {
+		PackageReferences []msbuildPackage `xml:"PackageReference"`
+		PackageVersions   []msbuildPackage `xml:"PackageVersion"`
+	} `xml:"ItemGroup"`
+}
+
+// msbuildPackage is a <PackageReference> or <PackageVersion> item; the version may be
+// an attribute or a child element
+type msbuildPackage struct {
+	Include         string `xml:"Include,attr"`
+	Update          string `xml:"Update,attr"`
+	Version         string `xml:"Version,attr"`
+	VersionOverride string `xml:"VersionOverride,attr"`
+	VersionElement  string `xml:"Version"`
+}
+
+// version returns the declared version of the item
+func (p msbuildPackage) version() string {
+	for _, version := range []string{p.VersionOverride, p.Version, p.VersionElement} {
+		if version = strings.TrimSpace(version); version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// readMSBuildProject decodes an MSBuild file and substitutes $(Property) references
+// in the versions with the file's own properties
+func readMSBuildProject(filename string) (references []msbuildPackage, versions map[string]string, err error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	var project msbuildProject
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, nil, err
+	}
+
+	properties := make(map[string]string)
+	for _, group := range project.PropertyGroups {
+		for _, property := range group.Properties {
+			properties[property.XMLName.Local] = strings.TrimSpace(property.Value)
+		}
+	}
+	expand := func(value string) string {
+		return msbuildProperty.ReplaceAllStringFunc(value, func(ref string) string {
+			if value, ok := properties[ref[2:len(ref)-1]]; ok {
+				return value
+			}
+			return ref
+		})
+	}
+
+	versions = make(map[string]string)
+	for _, group := range project.ItemGroups {
+		for _, ref := range group.PackageReferences {
+			if ref.Include == "" {
+				continue
+			}
+			ref.Version = expand(ref.version())
+			ref.VersionOverride, ref.VersionElement = "", ""
+			references = append(references, ref)
+		}
+		for _, version := range group.PackageVersions {
+			if version.Include != "" {
+				versions[strings.ToLower(version.Include)] = expand(version.version())
+			}
+		}
+	}
+	return references, versions, nil
+}
+
+// centralPackageVersions reads the nearest Directory.Packages.props above dir, which
+// holds the versions of central package management
+func centralPackageVersions(dir string) map[string]string {
+	for {
+		if _, versions, err := readMSBuildProject(filepath.Join(dir, "Directory.Packages.props")); err == nil {
+			return versions
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// parseNuGet parses an MSBuild project, Directory.Packages.props or packages.lock.json.
+// A packages.lock.json next to a project is preferred because it pins every package,
+// transitive ones included.
+func parseNuGet(filename string) ([]Package, string, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	base := filepath.Base(abs)
+	switch base {
+	case "packages.lock.json":
+		return parseNuGetLock(abs)
+	case "Directory.Packages.props":
+		_, versions, err := readMSBuildProject(abs)
+		if err != nil {
+			return nil, "", err
+		}
+		var packages []Package
+		for name, version := range versions {
+			packages = append(packages, Package{Path: name, Version: version})
+		}
+		sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+		return packages, filepath.Base(filepath.Dir(abs)) + "-dotnet", nil
+	}
+
+	projectName := strings.TrimSuffix(base, filepath.Ext(base)) + "-dotnet"
+	// Projects restored in locked mode pin every package in packages.lock.json
+	lock := filepath.Join(filepath.Dir(abs), "packages.lock.json")
+	if _, err := os.Stat(lock); err == nil {
+		packages, _, err := parseNuGetLock(lock)
+		return packages, projectName, err
+	}
+
+	references, _, err := readMSBuildProject(abs)
+	if err != nil {
+		return nil, "", err
+	}
+	central := centralPackageVersions(filepath.Dir(abs))
+
+	var packages []Package
+	for _, ref := range references {
+		version := ref.Version
+		if version == "" {
+			version = central[strings.ToLower(ref.Include)]
+		}
+		packages = append(packages, Package{Path: ref.Include, Version: version})
+	}
+	return packages, projectName, nil
+}
+
+// parseNuGetLock parses a packages.lock.json. Packages resolved for several target
+// frameworks are listed once per version; project references are skipped.
+func parseNuGetLock(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lock struct {
+		Dependencies map[string]map[string]struct {
+			Type     string `json:"type"`
+			Resolved string `json:"resolved"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, "", err
+	}
+
+	frameworks := make([]string, 0, len(lock.Dependencies))
+	for framework := range lock.Dependencies {
+		frameworks = append(frameworks, framework)
+	}
+	sort.Strings(frameworks)
+
+	var packages []Package
+	index := make(map[string]int)
+	for _, framework := range frameworks {
+		names := make([]string, 0, len(lock.Dependencies[framework]))
+		for name := range lock.Dependencies[framework] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			entry := lock.Dependencies[framework][name]
+			if entry.Type == "Project" {
+				continue
+			}
+			key := strings.ToLower(name) + "@" + entry.Resolved
+			// Central package management pins transitive packages as CentralTransitive
+			indirect := entry.Type == "Transitive" || entry.Type == "CentralTransitive"
+			// A package direct for one framework is direct for the project
+			if i, ok := index[key]; ok {
+				packages[i].Indirect = packages[i].Indirect && indirect
+				continue
+			}
+			index[key] = len(packages)
+			packages = append(packages, Package{
+				Path:     name,
+				Version:  entry.Resolved,
+				Indirect: indirect,
+			})
+		}
+	}
+
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, filepath.Base(filepath.Dir(abs)) + "-dotnet", nil
+}
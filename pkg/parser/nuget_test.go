@@ -0,0 +1,33 @@
+package parser
+
+import "testing"
+
+func TestParseNuGetLockIndirect(t *testing.T) {
+	lock := `{
+  "version": 2,
+  "dependencies": {
+    "net8.0": {
+      "Serilog": {"type": "Direct", "requested": "[3.1.1, )", "resolved": "3.1.1"},
+      "Newtonsoft.Json": {"type": "CentralTransitive", "requested": "[13.0.3, )", "resolved": "13.0.3"},
+      "System.Memory": {"type": "Transitive", "resolved": "4.5.5"},
+      "Shared": {"type": "Project"}
+    }
+  }
+}`
+	packages, _, err := parseNuGetLock(writeFile(t, "packages.lock.json", lock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"Serilog": false, "Newtonsoft.Json": true, "System.Memory": true}
+	for _, pkg := range packages {
+		indirect, ok := want[pkg.Path]
+		if !ok {
+			t.Errorf("unexpected package %s", pkg.Path)
+		} else if pkg.Indirect != indirect {
+			t.Errorf("%s: Indirect = %v, want %v", pkg.Path, pkg.Indirect, indirect)
+		}
+	}
+	if len(packages) != len(want) {
+		t.Errorf("got %d packages, want %d", len(packages), len(want))
+	}
+}
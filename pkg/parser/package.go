@@ -0,0 +1,70 @@
+package parser
+
+import "strings"
+
+// Package represents a dependency
+type Package struct {
+	Path      string
+	Version   string
+	GoMod     bool
+	PyProject bool
+	// Metadata declared by the manifest itself, if any
+	License     string
+	Author      string
+	Description string
+	Homepage    string
+	Copyright   string
+	Recipe      string // Yocto recipe the package was built from
+	Registry    string // Registry base URL or git URL the package resolves from
+	Indirect    bool   // only required by other dependencies
+	Group       string // dependency group the manifest lists the package in, e.g. dev
+	Scope       string // ScopeRuntime, ScopeDev, ScopeOptional or ScopePeer; "" when unknown
+	Workspace   string // npm workspace folders using the package, "; " separated
+	Installed   string // folder of the copy installed or vendored in the project, set by FindInstalled
+	// Set by folder scans, which mix projects and ecosystems in one report
+	Project        string
+	RepositoryType string
+}
+
+// Values of Package.Scope: whether a package ships with the project or only serves its
+// development
+const (
+	ScopeRuntime  = "runtime"
+	ScopeDev      = "dev"
+	ScopeOptional = "optional"
+	ScopePeer     = "peer"
+)
+
+// devGroups are the dependency groups of the manifests that only serve development:
+// Cargo, Composer and pipenv dev sections, Gemfile groups and the Maven test scope
+var devGroups = map[string]bool{"dev": true, "develop": true, "development": true, "test": true, "tests": true, "testing": true}
+
+// groupScope derives the scope of a package from its dependency group: dev when every
+// group or Gradle configuration it is listed in only serves development, runtime otherwise
+func groupScope(group string) string {
+	if group == "" {
+		return ""
+	}
+	for name := range strings.SplitSeq(group, ",") {
+		name = strings.TrimSpace(name)
+		if !devGroups[strings.ToLower(name)] && !strings.HasPrefix(name, "test") {
+			return ScopeRuntime
+		}
+	}
+	return ScopeDev
+}
+
+// scopeRank orders the scopes from shipping with the project to only serving development
+var scopeRank = map[string]int{ScopeRuntime: 1, ScopeOptional: 2, ScopePeer: 3, ScopeDev: 4}
+
+// broaderScope returns the scope of a package listed with two scopes: the one closer to
+// shipping with the project, so a package one workspace needs at runtime stays runtime
+func broaderScope(a string, b string) string {
+	if a == "" || b == "" {
+		return ""
+	}
+	if scopeRank[b] < scopeRank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// packageJSONManifest is the part of a package.json the parser reads
+type packageJSONManifest struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	// Workspaces is a list of folder globs, or for Yarn an object with the list as packages
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+// readPackageJSON reads and decodes a package.json
+func readPackageJSON(filename string) (packageJSONManifest, error) {
+	var manifest packageJSONManifest
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return manifest, err
+	}
+	err = json.Unmarshal(data, &manifest)
+	return manifest, err
+}
+
+// packages lists the dependencies of the manifest. A package listed in several sections
+// is reported once. optionalDependencies override dependencies as they do for npm, and
+// a peer dependency also installed for development is reported as the peer dependency
+// consumers have to provide.
+func (m packageJSONManifest) packages() []Package {
+	var packages []Package
+	seen := make(map[string]bool)
+	for _, section := range []struct {
+		scope        string
+		dependencies map[string]string
+	}{
+		{ScopeOptional, m.OptionalDependencies},
+		{ScopeRuntime, m.Dependencies},
+		{ScopePeer, m.PeerDependencies},
+		{ScopeDev, m.DevDependencies},
+	} {
+		for name, version := range section.dependencies {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			packages = append(packages, Package{
+				Path:    name,
+				Version: version,
+				GoMod:   false,
+				Scope:   section.scope,
+			})
+		}
+	}
+	return packages
+}
+
+// workspaceFolders expands the workspaces field to the folders below dir that hold a
+// package.json, relative to dir with forward slashes. Patterns starting with ! exclude
+// the folders they match.
+func (m packageJSONManifest) workspaceFolders(dir string) ([]string, error) {
+	if len(m.Workspaces) == 0 {
+		return nil, nil
+	}
+	var patterns []string
+	if err := json.Unmarshal(m.Workspaces, &patterns); err != nil {
+		var yarn struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(m.Workspaces, &yarn); err != nil {
+			return nil, fmt.Errorf("invalid workspaces: %w", err)
+		}
+		patterns = yarn.Packages
+	}
+
+	included := make(map[string]bool)
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "!")
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(pattern, "!"))))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if _, err := os.Stat(filepath.Join(match, "package.json")); err != nil {
+				continue
+			}
+			folder, err := filepath.Rel(dir, match)
+			if err != nil {
+				return nil, err
+			}
+			included[filepath.ToSlash(folder)] = !exclude
+		}
+	}
+
+	var folders []string
+	for folder, ok := range included {
+		if ok {
+			folders = append(folders, folder)
+		}
+	}
+	sort.Strings(folders)
+	return folders, nil
+}
+
+// Parse package.json file. When it declares workspaces, the dependencies of every
+// workspace are added, tagged with the workspace folder ("." for the root); packages
+// several workspaces use are listed once, and dependencies on the workspaces
+// themselves are left out.
+func parsePackageJSON(filename string) ([]Package, string, error) {
+	root, err := readPackageJSON(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dir := filepath.Dir(filename)
+	folders, err := root.workspaceFolders(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(folders) == 0 {
+		return root.packages(), root.Name + "-ui", nil
+	}
+	slog.Debug("reading npm workspaces", "file", filename, "workspaces", strings.Join(folders, ", "))
+
+	workspaces := map[string]packageJSONManifest{".": root}
+	internal := make(map[string]bool)
+	for _, folder := range folders {
+		manifest, err := readPackageJSON(filepath.Join(dir, filepath.FromSlash(folder), "package.json"))
+		if err != nil {
+			return nil, "", fmt.Errorf("workspace %s: %w", folder, err)
+		}
+		workspaces[folder] = manifest
+		if manifest.Name != "" {
+			internal[manifest.Name] = true
+		}
+	}
+
+	var packages []Package
+	index := make(map[string]int)
+	for _, folder := range append([]string{"."}, folders...) {
+		for _, pkg := range workspaces[folder].packages() {
+			if internal[pkg.Path] {
+				continue
+			}
+			key := pkg.Path + "@" + pkg.Version
+			if i, ok := index[key]; ok {
+				packages[i].Workspace += "; " + folder
+				packages[i].Scope = broaderScope(packages[i].Scope, pkg.Scope)
+				continue
+			}
+			pkg.Workspace = folder
+			index[key] = len(packages)
+			packages = append(packages, pkg)
+		}
+	}
+
+	return packages, root.Name + "-ui", nil
+}
@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"cmp"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// isPackageLock reports whether filename is an npm lockfile (package-lock.json or
+// npm-shrinkwrap.json)
+func isPackageLock(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "package-lock.json" || base == "npm-shrinkwrap.json"
+}
+
+// packageLockDependency is an entry of the nested "dependencies" tree of lockfile v1
+type packageLockDependency struct {
+	Version      string                           `json:"version"`
+	Dev          bool                             `json:"dev"`
+	Optional     bool                             `json:"optional"`
+	Dependencies map[string]packageLockDependency `json:"dependencies"`
+}
+
+// packageLockEntry is an entry of the "packages" map of lockfile v2/v3
+type packageLockEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	License string `json:"license"`
+	Link    bool   `json:"link"`
+	// Set on packages only installed for development, as optional dependencies
+	// or as peer dependencies
+	Dev         bool `json:"dev"`
+	DevOptional bool `json:"devOptional"`
+	Optional    bool `json:"optional"`
+	Peer        bool `json:"peer"`
+	// What the package.json of the project and of each workspace declares
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// declares reports whether the entry lists name in any of its dependency sections
+func (e packageLockEntry) declares(name string) bool {
+	for _, deps := range []map[string]string{e.Dependencies, e.DevDependencies, e.OptionalDependencies, e.PeerDependencies} {
+		if _, ok := deps[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// joinWorkspaces adds the workspaces of b missing from the "; " separated list a
+func joinWorkspaces(a string, b string) string {
+	if a == "" {
+		return b
+	}
+	list := strings.Split(a, "; ")
+	for workspace := range strings.SplitSeq(b, "; ") {
+		if workspace != "" && !slices.Contains(list, workspace) {
+			list = append(list, workspace)
+		}
+	}
+	return strings.Join(list, "; ")
+}
+
+// packageLockName returns the package name of a lockfile v2/v3 "packages" key, the
+// part after the last node_modules/: node_modules/a/node_modules/@scope/b is @scope/b
+func packageLockName(key string) string {
+	idx := strings.LastIndex(key, "node_modules/")
+	if idx < 0 {
+		return ""
+	}
+	return key[idx+len("node_modules/"):]
+}
+
+// parsePackageLock parses an npm package-lock.json, covering the complete installed
+// tree with exact versions. Packages installed at several places in node_modules are
+// listed once per version.
+func parsePackageLock(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lock struct {
+		Name            string                           `json:"name"`
+		LockfileVersion int                              `json:"lockfileVersion"`
+		Packages        map[string]packageLockEntry      `json:"packages"`
+		Dependencies    map[string]packageLockDependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	index := make(map[string]int)
+	add := func(name string, version string, license string, scope string, workspace string) {
+		if name == "" {
+			return
+		}
+		if i, ok := index[name+"@"+version]; ok {
+			packages[i].Workspace = joinWorkspaces(packages[i].Workspace, workspace)
+			return
+		}
+		index[name+"@"+version] = len(packages)
+		packages = append(packages, Package{Path: name, Version: version, License: license, Scope: scope, Workspace: workspace})
+	}
+
+	if len(lock.Packages) > 0 {
+		// Lockfile v2/v3: one entry per installed location, "" is the project itself
+		keys := make([]string, 0, len(lock.Packages))
+		for key := range lock.Packages {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		// Entries of folders of the project outside node_modules are its workspaces
+		var workspaces []string
+		for _, key := range keys {
+			if key != "" && !strings.Contains(key, "node_modules/") && !lock.Packages[key].Link {
+				workspaces = append(workspaces, key)
+			}
+		}
+		// workspaceOf tags a package with the workspace it is installed in, or the
+		// workspaces ("." for the root) that declare it and have no copy of their own
+		// when it is hoisted to the root
+		workspaceOf := func(key string) string {
+			if len(workspaces) == 0 {
+				return ""
+			}
+			for _, workspace := range workspaces {
+				if strings.HasPrefix(key, workspace+"/node_modules/") {
+					return workspace
+				}
+			}
+			name := packageLockName(key)
+			if key != "node_modules/"+name {
+				return ""
+			}
+			var declaring []string
+			for _, workspace := range append([]string{""}, workspaces...) {
+				if _, nested := lock.Packages[workspace+"/node_modules/"+name]; lock.Packages[workspace].declares(name) && !nested {
+					declaring = append(declaring, cmp.Or(workspace, "."))
+				}
+			}
+			return strings.Join(declaring, "; ")
+		}
+
+		for _, key := range keys {
+			entry := lock.Packages[key]
+			// Workspace links point at a folder of the project, not at a dependency
+			if entry.Link {
+				continue
+			}
+			name := packageLockName(key)
+			// An aliased install (npm:real@1.0) records the real name
+			if entry.Name != "" && name != "" {
+				name = entry.Name
+			}
+			scope := ScopeRuntime
+			switch {
+			case entry.Dev || entry.DevOptional:
+				scope = ScopeDev
+			case entry.Optional:
+				scope = ScopeOptional
+			case entry.Peer:
+				scope = ScopePeer
+			}
+			add(name, entry.Version, entry.License, scope, workspaceOf(key))
+		}
+	} else {
+		// Lockfile v1 nests the dependencies of packages that could not be hoisted
+		var walk func(deps map[string]packageLockDependency)
+		walk = func(deps map[string]packageLockDependency) {
+			names := make([]string, 0, len(deps))
+			for name := range deps {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				scope := ScopeRuntime
+				if deps[name].Dev {
+					scope = ScopeDev
+				} else if deps[name].Optional {
+					scope = ScopeOptional
+				}
+				add(name, deps[name].Version, "", scope, "")
+				walk(deps[name].Dependencies)
+			}
+		}
+		walk(lock.Dependencies)
+	}
+
+	name := lock.Name
+	if name == "" {
+		abs, err := filepath.Abs(filename)
+		if err != nil {
+			return nil, "", err
+		}
+		name = filepath.Base(filepath.Dir(abs))
+	}
+	return packages, name + "-ui", nil
+}
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Project is the result of parsing a dependency file
+type Project struct {
+	Packages []Package
+	// Name of the project, used to name the report
+	Name string
+	// Ecosystem the packages come from: go, npm, pypi, cargo, maven, nuget, rubygems,
+	// composer, upm, yocto, deb or apk for the built-in parsers
+	Ecosystem string
+	// IsPackageJSON is set for npm manifests and lockfiles
+	IsPackageJSON bool
+}
+
+// Parser reads one kind of dependency file
+type Parser interface {
+	// Detect reports whether the parser handles filename
+	Detect(filename string) bool
+	// Parse reads the packages from the content of a file
+	Parse(r io.Reader) (Project, error)
+}
+
+// FileParser is a Parser that also reads files next to the one parsed, such as a
+// lockfile beside its manifest, or names the project after its folder. Parse and
+// ScanFolder call ParseFile instead of Parse for it.
+type FileParser interface {
+	Parser
+	ParseFile(filename string) (Project, error)
+}
+
+// registered are the parsers added with Register, tried in order before the built-in ones
+var registered []Parser
+
+// Register adds a parser for a new kind of dependency file. Registered parsers are
+// tried before the built-in ones, so they can also take over a file a built-in parser
+// would read, and folder scans pick up every file they detect.
+func Register(p Parser) {
+	registered = append(registered, p)
+}
+
+// builtinParser adapts one of the parse functions of this package to FileParser
+type builtinParser struct {
+	ecosystem   string
+	packageJSON bool
+	detect      func(filename string) bool
+	parse       func(filename string) ([]Package, string, error)
+}
+
+// Detect reports whether the parser handles filename
+func (p *builtinParser) Detect(filename string) bool {
+	return p.detect(filename)
+}
+
+// ParseFile parses filename together with the files next to it
+func (p *builtinParser) ParseFile(filename string) (Project, error) {
+	packages, name, err := p.parse(filename)
+	if err != nil {
+		return Project{}, err
+	}
+	for i := range packages {
+		if packages[i].Scope == "" {
+			packages[i].Scope = groupScope(packages[i].Group)
+		}
+	}
+	return Project{Packages: packages, Name: name, Ecosystem: p.ecosystem, IsPackageJSON: p.packageJSON}, nil
+}
+
+// Parse parses content on its own: it is written to a file of the first name the parser
+// detects, in an otherwise empty folder named "project", so no sibling files are read.
+func (p *builtinParser) Parse(r io.Reader) (Project, error) {
+	dir, err := os.MkdirTemp("", "license_fetcher")
+	if err != nil {
+		return Project{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	dir = filepath.Join(dir, "project")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return Project{}, err
+	}
+	var filename string
+	for _, name := range builtinFileNames {
+		if p.detect(name) {
+			filename = filepath.Join(dir, name)
+			break
+		}
+	}
+	if filename == "" {
+		return Project{}, fmt.Errorf("%s files can only be parsed from disk", p.ecosystem)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Project{}, err
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return Project{}, err
+	}
+	return p.ParseFile(filename)
+}
+
+// builtinFileNames are typical names of the files the built-in parsers read, used to
+// parse content that comes without a name
+var builtinFileNames = []string{
+	"package.json", "go.mod", "pyproject.toml", "poetry.lock", "requirements.txt", "Pipfile", "Pipfile.lock",
+	"Cargo.toml", "Cargo.lock", "pom.xml", "build.gradle", "gradle.lockfile", "project.csproj",
+	"packages.lock.json", "Gemfile", "Gemfile.lock", "composer.json", "composer.lock",
+	"license.manifest", "status", "installed", "package-lock.json", "yarn.lock", "manifest.json",
+}
+
+// builtinParsers are the parsers of this package, in the order they are tried
+var builtinParsers = []*builtinParser{
+	{ecosystem: "go", detect: func(filename string) bool {
+		return !strings.HasSuffix(filename, "go.mod") && IsGoBinary(filename)
+	}, parse: parseGoBinary},
+	{ecosystem: "go", detect: func(filename string) bool { return strings.HasSuffix(filename, "go.mod") }, parse: parseGoMod},
+	{ecosystem: "pypi", detect: func(filename string) bool { return strings.HasSuffix(filename, "pyproject.toml") }, parse: parsePyProject},
+	{ecosystem: "pypi", detect: isPoetryLock, parse: parsePoetryLock},
+	{ecosystem: "pypi", detect: isRequirementsTxt, parse: parseRequirementsTxt},
+	{ecosystem: "pypi", detect: isPipfile, parse: parsePipenv},
+	{ecosystem: "cargo", detect: isCargoManifest, parse: parseCargo},
+	{ecosystem: "maven", detect: isMavenPOM, parse: parseMavenPOM},
+	{ecosystem: "maven", detect: isGradleBuild, parse: parseGradle},
+	{ecosystem: "nuget", detect: isNuGetProject, parse: parseNuGet},
+	{ecosystem: "rubygems", detect: isGemfile, parse: parseBundler},
+	{ecosystem: "composer", detect: isComposerManifest, parse: parseComposer},
+	{ecosystem: "yocto", detect: isYoctoManifest, parse: parseYoctoManifest},
+	{ecosystem: "deb", detect: isDpkgStatus, parse: parseDpkgStatus},
+	{ecosystem: "apk", detect: isApkInstalled, parse: parseApkInstalled},
+	{ecosystem: "npm", packageJSON: true, detect: IsNodeModules, parse: parseNodeModules},
+	{ecosystem: "npm", packageJSON: true, detect: isPackageLock, parse: parsePackageLock},
+	{ecosystem: "npm", packageJSON: true, detect: isYarnLock, parse: parseYarnLock},
+	{ecosystem: "upm", detect: isUnityManifest, parse: parseUnityManifest},
+	{ecosystem: "pypi", detect: IsPythonDist, parse: parsePythonDistDir},
+	{ecosystem: "pypi", detect: IsPythonEnv, parse: parsePythonEnv},
+	packageJSONParser,
+}
+
+// packageJSONParser reads package.json, and any file no other parser recognizes
+var packageJSONParser = &builtinParser{ecosystem: "npm", packageJSON: true, detect: func(filename string) bool {
+	return strings.HasSuffix(filename, "package.json")
+}, parse: parsePackageJSON}
+
+// parsePyProject parses a pyproject.toml, or the poetry.lock next to it, which has the
+// exact versions the constraints resolved to
+func parsePyProject(filename string) ([]Package, string, error) {
+	if lock := siblingPoetryLock(filename); lock != "" {
+		slog.Debug("reading the poetry.lock next to pyproject.toml", "file", lock)
+		return parsePoetryLock(lock)
+	}
+	return parsePyProjectToml(filename)
+}
+
+// Lookup returns the parser of a file: the first registered parser that detects it, else
+// the built-in one. Files no parser recognizes are read as package.json.
+func Lookup(filename string) Parser {
+	for _, p := range registered {
+		if p.Detect(filename) {
+			return p
+		}
+	}
+	for _, p := range builtinParsers {
+		if p.Detect(filename) {
+			return p
+		}
+	}
+	slog.Debug("no parser detects the file, reading it as package.json", "file", filename)
+	return packageJSONParser
+}
+
+// Parse detects the kind of dependency file and parses it
+func Parse(filename string) (*Project, error) {
+	p := Lookup(filename)
+	var project Project
+	var err error
+	if fp, ok := p.(FileParser); ok {
+		project, err = fp.ParseFile(filename)
+	} else {
+		var f *os.File
+		if f, err = os.Open(filename); err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		project, err = p.Parse(f)
+	}
+	if err != nil {
+		slog.Debug("parse failed", "file", filename, "error", err)
+		return nil, err
+	}
+	slog.Debug("parsed", "file", filename, "ecosystem", project.Ecosystem, "project", project.Name, "packages", len(project.Packages))
+	return &project, nil
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile creates a file with content in a temporary folder and returns its path
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		ecosystem string
+		packages  map[string]string
+	}{
+		{
+			name:      "go.mod",
+			file:      "go.mod",
+			content:   "module example.com/app\n\ngo 1.24\n\nrequire (\n\tgolang.org/x/text v0.14.0\n\tgithub.com/pkg/errors v0.9.1 // indirect\n)\n",
+			ecosystem: "go",
+			packages:  map[string]string{"golang.org/x/text": "v0.14.0", "github.com/pkg/errors": "v0.9.1"},
+		},
+		{
+			name:      "package.json",
+			file:      "package.json",
+			content:   `{"name": "app", "dependencies": {"left-pad": "1.3.0"}, "devDependencies": {"jest": "^29.0.0"}}`,
+			ecosystem: "npm",
+			packages:  map[string]string{"left-pad": "1.3.0", "jest": "^29.0.0"},
+		},
+		{
+			name:      "requirements.txt",
+			file:      "requirements.txt",
+			content:   "# pinned\nrequests==2.31.0\nflask>=2.0\n",
+			ecosystem: "pypi",
+			packages:  map[string]string{"requests": "==2.31.0", "flask": ">=2.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := Parse(writeFile(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if project.Ecosystem != tt.ecosystem {
+				t.Errorf("Ecosystem = %q, want %q", project.Ecosystem, tt.ecosystem)
+			}
+			got := make(map[string]string)
+			for _, pkg := range project.Packages {
+				got[pkg.Path] = pkg.Version
+			}
+			for path, version := range tt.packages {
+				if got[path] != version {
+					t.Errorf("%s: version %q, want %q (packages %v)", path, got[path], version, got)
+				}
+			}
+			if len(got) != len(tt.packages) {
+				t.Errorf("got packages %v, want %v", got, tt.packages)
+			}
+		})
+	}
+}
+
+// conanParser is a parser registered by a library user
+type conanParser struct{}
+
+func (conanParser) Detect(filename string) bool { return filepath.Base(filename) == "conanfile.txt" }
+
+func (conanParser) Parse(r io.Reader) (Project, error) {
+	return Project{Ecosystem: "conan", Packages: []Package{{Path: "zlib", Version: "1.3"}}}, nil
+}
+
+func TestRegister(t *testing.T) {
+	Register(conanParser{})
+	defer func() { registered = nil }()
+
+	project, err := Parse(writeFile(t, "conanfile.txt", "[requires]\nzlib/1.3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Ecosystem != "conan" || len(project.Packages) != 1 {
+		t.Errorf("got %+v, want the registered parser's project", project)
+	}
+}
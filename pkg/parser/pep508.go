@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pep508Requirement is a dependency specification as PEP 508 defines it, e.g.
+// requests[security,socks]>=2.0,<3; python_version < "3.9"
+type pep508Requirement struct {
+	Name      string
+	Extras    []string
+	Specifier string // version specifiers without spaces, e.g. ">=2.0,<3"
+	URL       string // direct reference after @
+	Marker    string // environment marker after ;
+}
+
+// pep508NamePattern matches the distribution name and the optional extras
+var pep508NamePattern = regexp.MustCompile(`^\s*([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[([^\]]*)\])?\s*`)
+
+// parsePEP508 parses a requirement; ok is false when it does not start with a name
+func parsePEP508(requirement string) (pep508Requirement, bool) {
+	var req pep508Requirement
+	match := pep508NamePattern.FindStringSubmatch(requirement)
+	if match == nil {
+		return req, false
+	}
+	req.Name = match[1]
+	for _, extra := range strings.Split(match[2], ",") {
+		if extra = strings.TrimSpace(extra); extra != "" {
+			req.Extras = append(req.Extras, extra)
+		}
+	}
+	rest := requirement[len(match[0]):]
+
+	// A direct reference ends at the whitespace before its marker, since URLs may
+	// contain a semicolon
+	if strings.HasPrefix(rest, "@") {
+		rest = strings.TrimSpace(rest[1:])
+		if idx := strings.Index(rest, " ;"); idx >= 0 {
+			req.URL, req.Marker = rest[:idx], strings.TrimSpace(rest[idx+2:])
+		} else {
+			req.URL = rest
+		}
+		return req, true
+	}
+
+	specifier, marker, _ := strings.Cut(rest, ";")
+	req.Marker = strings.TrimSpace(marker)
+	specifier = strings.TrimSpace(specifier)
+	if strings.HasPrefix(specifier, "(") && strings.HasSuffix(specifier, ")") {
+		specifier = specifier[1 : len(specifier)-1]
+	}
+	req.Specifier = strings.Join(strings.Fields(specifier), "")
+	return req, true
+}
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Values of Package.Group for pipenv's two package sections
+const (
+	groupDefault = "default"
+	groupDev     = "dev"
+)
+
+// isPipfile reports whether filename is a pipenv Pipfile or Pipfile.lock
+func isPipfile(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "Pipfile" || base == "Pipfile.lock"
+}
+
+// pipfileProjectName names a pipenv project after its directory, as pipenv does
+func pipfileProjectName(filename string) (string, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(filepath.Dir(abs)) + "-py", nil
+}
+
+// parsePipenv parses a Pipfile, or the Pipfile.lock next to it when there is one
+// because it pins the exact versions of every installed package
+func parsePipenv(filename string) ([]Package, string, error) {
+	if filepath.Base(filename) == "Pipfile" {
+		lock := filename + ".lock"
+		if _, err := os.Stat(lock); err == nil {
+			filename = lock
+		}
+	}
+	if filepath.Base(filename) == "Pipfile.lock" {
+		return parsePipfileLock(filename)
+	}
+	return parsePipfile(filename)
+}
+
+// parsePipfile parses the [packages] and [dev-packages] sections of a Pipfile. A
+// requirement is either a version string or a table with version, git or path keys.
+func parsePipfile(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var pipfile struct {
+		Packages    map[string]any `toml:"packages"`
+		DevPackages map[string]any `toml:"dev-packages"`
+	}
+	if err := toml.Unmarshal(data, &pipfile); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, section := range []struct {
+		group        string
+		requirements map[string]any
+	}{{groupDefault, pipfile.Packages}, {groupDev, pipfile.DevPackages}} {
+		names := make([]string, 0, len(section.requirements))
+		for name := range section.requirements {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			pkg := Package{Path: name, PyProject: true, Group: section.group}
+			switch spec := section.requirements[name].(type) {
+			case string:
+				pkg.Version = spec
+			case map[string]any:
+				// Local path requirements are part of the project itself
+				if _, ok := spec["path"]; ok {
+					continue
+				}
+				pkg.Version, _ = spec["version"].(string)
+				if git, ok := spec["git"].(string); ok {
+					pkg.Registry = git
+					pkg.Homepage = git
+					pkg.Version, _ = spec["ref"].(string)
+				}
+			}
+			if pkg.Version == "*" {
+				pkg.Version = ""
+			}
+			packages = append(packages, pkg)
+		}
+	}
+
+	name, err := pipfileProjectName(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
+
+// parsePipfileLock parses a Pipfile.lock, whose "default" and "develop" sections pin
+// each package with ==version
+func parsePipfileLock(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	type lockEntry struct {
+		Version string `json:"version"`
+		Git     string `json:"git"`
+		Ref     string `json:"ref"`
+		Path    string `json:"path"`
+	}
+	var lock struct {
+		Default map[string]lockEntry `json:"default"`
+		Develop map[string]lockEntry `json:"develop"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, section := range []struct {
+		group   string
+		entries map[string]lockEntry
+	}{{groupDefault, lock.Default}, {groupDev, lock.Develop}} {
+		names := make([]string, 0, len(section.entries))
+		for name := range section.entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			entry := section.entries[name]
+			if entry.Path != "" {
+				continue
+			}
+			pkg := Package{
+				Path:      name,
+				Version:   strings.TrimPrefix(entry.Version, "=="),
+				PyProject: true,
+				Group:     section.group,
+			}
+			if entry.Git != "" {
+				pkg.Registry = entry.Git
+				pkg.Homepage = entry.Git
+				pkg.Version = entry.Ref
+			}
+			packages = append(packages, pkg)
+		}
+	}
+
+	name, err := pipfileProjectName(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
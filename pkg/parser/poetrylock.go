@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// isPoetryLock reports whether filename is a Poetry lockfile
+func isPoetryLock(filename string) bool {
+	return filepath.Base(filename) == "poetry.lock"
+}
+
+// siblingPoetryLock returns the poetry.lock next to a pyproject.toml, or "" if there is none
+func siblingPoetryLock(pyproject string) string {
+	lock := filepath.Join(filepath.Dir(pyproject), "poetry.lock")
+	if _, err := os.Stat(lock); err != nil {
+		return ""
+	}
+	return lock
+}
+
+// parsePoetryLock parses a poetry.lock, which records the exact version of every
+// installed package, direct or not. Packages installed from a local directory or
+// file are project sources rather than dependencies and are skipped.
+func parsePoetryLock(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lock struct {
+		Package []struct {
+			Name        string `toml:"name"`
+			Version     string `toml:"version"`
+			Description string `toml:"description"`
+			// Lockfiles before Poetry 1.2 tell main packages from dev ones
+			Category string `toml:"category"`
+			Source   struct {
+				Type      string `toml:"type"`
+				URL       string `toml:"url"`
+				Reference string `toml:"reference"`
+			} `toml:"source"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, entry := range lock.Package {
+		pkg := Package{
+			Path:        entry.Name,
+			Version:     entry.Version,
+			PyProject:   true,
+			Description: entry.Description,
+		}
+		switch entry.Category {
+		case "dev":
+			pkg.Scope = ScopeDev
+		case "main":
+			pkg.Scope = ScopeRuntime
+		}
+		switch entry.Source.Type {
+		case "directory", "file":
+			continue
+		case "git", "url":
+			pkg.Registry = entry.Source.URL
+			pkg.Homepage = entry.Source.URL
+		}
+		packages = append(packages, pkg)
+	}
+
+	// The project name lives in the pyproject.toml next to the lockfile, which also
+	// tells the scope of the direct dependencies when the lockfile does not
+	pyproject := filepath.Join(filepath.Dir(filename), "pyproject.toml")
+	if _, err := os.Stat(pyproject); err == nil {
+		if direct, name, err := parsePyProjectToml(pyproject); err == nil {
+			scopes := make(map[string]string)
+			for _, pkg := range direct {
+				// A package of both the main and a dev group ships with the project
+				if scopes[pythonProjectName(pkg.Path)] != ScopeRuntime {
+					scopes[pythonProjectName(pkg.Path)] = pkg.Scope
+				}
+			}
+			for i := range packages {
+				if packages[i].Scope == "" {
+					packages[i].Scope = scopes[pythonProjectName(packages[i].Path)]
+				}
+			}
+			if name != "-py" {
+				return packages, name, nil
+			}
+		}
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, filepath.Base(filepath.Dir(abs)) + "-py", nil
+}
+
+// pythonProjectName normalizes a distribution name as PEP 503 compares them, so
+// Typing_Extensions and typing-extensions are the same project
+func pythonProjectName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
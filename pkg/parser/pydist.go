@@ -0,0 +1,293 @@
+package parser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/mail"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Distribution file names: {name}-{version}(-{build})?-{python}-{abi}-{platform}.whl
+// for wheels and {name}-{version}.tar.gz or .zip for sdists
+var (
+	wheelNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._]*-[0-9][A-Za-z0-9._+!]*(-[0-9][A-Za-z0-9_]*)?-[A-Za-z0-9._]+-[A-Za-z0-9._]+-[A-Za-z0-9._]+\.whl$`)
+	sdistNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*-[0-9][A-Za-z0-9._+!-]*\.(tar\.gz|zip)$`)
+)
+
+// IsPythonDist reports whether filename is named like a wheel or sdist archive. Other
+// archives, such as a release.zip, are not taken for Python distributions.
+func IsPythonDist(filename string) bool {
+	base := filepath.Base(filename)
+	return wheelNamePattern.MatchString(base) || sdistNamePattern.MatchString(base)
+}
+
+// IsLicenseFile reports whether a file name looks like a bundled license text
+func IsLicenseFile(name string) bool {
+	base := strings.ToUpper(path.Base(name))
+	for _, prefix := range []string{"LICENSE", "LICENCE", "COPYING", "NOTICE"} {
+		if strings.HasPrefix(base, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePythonDistDir scans the directory containing the selected archive, so
+// users can point the tool at any file inside dist/ or a wheelhouse
+func parsePythonDistDir(filename string) ([]Package, string, error) {
+	dir := filepath.Dir(filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, entry := range entries {
+		if entry.IsDir() || !IsPythonDist(entry.Name()) {
+			continue
+		}
+
+		archive := filepath.Join(dir, entry.Name())
+		var pkg Package
+		var ok bool
+		if strings.HasSuffix(entry.Name(), ".tar.gz") {
+			pkg, ok = readSdist(archive)
+		} else {
+			pkg, ok = readWheel(archive)
+		}
+		if ok {
+			packages = append(packages, pkg)
+		}
+	}
+
+	return packages, filepath.Base(dir) + "-dist", nil
+}
+
+// readWheel reads *.dist-info/METADATA and the license files of the .dist-info folder
+// from a wheel, or PKG-INFO and the license files of the root folder from a zip sdist
+func readWheel(filename string) (Package, bool) {
+	reader, err := zip.OpenReader(filename)
+	if err != nil {
+		return Package{}, false
+	}
+	defer reader.Close()
+
+	var metadata []byte
+	var metadataDir string
+	licenses := make(map[string][]byte)
+	var licenseNames []string
+	for _, file := range reader.File {
+		dir, name, ok := strings.Cut(file.Name, "/")
+		if !ok || name == "" {
+			continue
+		}
+		distInfo := strings.HasSuffix(dir, ".dist-info")
+		// Only the top-level PKG-INFO of zip sdists counts, not nested egg-info copies
+		isMetadata := (distInfo && name == "METADATA") || (!distInfo && name == "PKG-INFO")
+		if !isMetadata && !IsLicenseFile(name) && !(distInfo && strings.HasPrefix(name, "licenses/")) {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+
+		if isMetadata {
+			// A wheel's METADATA wins over any PKG-INFO shipped among its files
+			if metadata == nil || distInfo {
+				metadata, metadataDir = data, dir
+			}
+		} else {
+			licenses[file.Name] = data
+			licenseNames = append(licenseNames, file.Name)
+		}
+	}
+
+	if metadata == nil {
+		return Package{}, false
+	}
+	return pythonDistPackage(metadata, distLicenseText(metadata, metadataDir, licenses, licenseNames))
+}
+
+// readSdist reads PKG-INFO and the license files of the root folder from a .tar.gz sdist
+func readSdist(filename string) (Package, bool) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return Package{}, false
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return Package{}, false
+	}
+	defer gz.Close()
+
+	var metadata []byte
+	var metadataDir string
+	licenses := make(map[string][]byte)
+	var licenseNames []string
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			break
+		}
+		// sdists contain a single <name>-<version>/ top-level directory
+		name := strings.TrimPrefix(header.Name, "./")
+		dir, rest, ok := strings.Cut(name, "/")
+		if header.Typeflag != tar.TypeReg || !ok {
+			continue
+		}
+
+		if rest == "PKG-INFO" {
+			metadata, _ = io.ReadAll(reader)
+			metadataDir = dir
+		} else if IsLicenseFile(rest) {
+			licenses[name], _ = io.ReadAll(reader)
+			licenseNames = append(licenseNames, name)
+		}
+	}
+
+	if metadata == nil {
+		return Package{}, false
+	}
+	return pythonDistPackage(metadata, distLicenseText(metadata, metadataDir, licenses, licenseNames))
+}
+
+// distLicenseText picks the license text of a distribution whose metadata lies in dir:
+// the first License-File entry of the metadata found, below dir/licenses/ as core
+// metadata 2.4 places them or below dir as setuptools did before, otherwise the first
+// license file directly in dir
+func distLicenseText(metadata []byte, dir string, licenses map[string][]byte, names []string) []byte {
+	if msg, err := mail.ReadMessage(bytes.NewReader(metadata)); err == nil {
+		for _, entry := range msg.Header["License-File"] {
+			for _, name := range []string{dir + "/licenses/" + entry, dir + "/" + entry} {
+				if text, ok := licenses[name]; ok {
+					return text
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		if folder := path.Dir(name); folder == dir || folder == dir+"/licenses" {
+			return licenses[name]
+		}
+	}
+	return nil
+}
+
+// pythonDistPackage converts core metadata (METADATA / PKG-INFO) into a Package
+func pythonDistPackage(metadata []byte, licenseText []byte) (Package, bool) {
+	msg, err := mail.ReadMessage(strings.NewReader(string(metadata)))
+	if err != nil {
+		return Package{}, false
+	}
+	header := msg.Header
+
+	pkg := Package{
+		Path:        header.Get("Name"),
+		Version:     header.Get("Version"),
+		Description: header.Get("Summary"),
+		PyProject:   true,
+	}
+
+	pkg.Author = header.Get("Author")
+	if pkg.Author == "" {
+		pkg.Author = header.Get("Author-email")
+	}
+	if pkg.Author == "" {
+		pkg.Author = header.Get("Maintainer")
+	}
+
+	// License-Expression (PEP 639) is authoritative, then classifiers, then the free-form field
+	if expression := header.Get("License-Expression"); expression != "" {
+		pkg.License = expression
+	} else if license := classifierLicense(header["Classifier"]); license != "" {
+		pkg.License = license
+	} else if license := header.Get("License"); license != "" && len(license) < 100 {
+		pkg.License = NormalizeLicense(license)
+	}
+
+	projectURLs := make(map[string]string)
+	for _, projectURL := range header["Project-Url"] {
+		if key, value, ok := strings.Cut(projectURL, ","); ok {
+			projectURLs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	pkg.Homepage = projectHomepage(projectURLs, header.Get("Home-Page"))
+
+	if licenseText != nil {
+		scanner := bufio.NewScanner(strings.NewReader(string(licenseText)))
+		firstLine := ""
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if firstLine == "" {
+				firstLine = line
+			}
+			if strings.HasPrefix(strings.ToLower(line), "copyright") {
+				pkg.Copyright = line
+				break
+			}
+		}
+		// Fall back to the license title, e.g. "MIT License"
+		if pkg.License == "" && firstLine != "" {
+			if license := NormalizeLicense(firstLine); license != firstLine {
+				pkg.License = license
+			}
+		}
+	}
+
+	return pkg, pkg.Path != ""
+}
+
+// classifierLicense returns the license of the first "License :: ..." trove classifier
+func classifierLicense(classifiers []string) string {
+	for _, classifier := range classifiers {
+		if strings.HasPrefix(classifier, "License :: ") {
+			parts := strings.Split(classifier, " :: ")
+			if len(parts) >= 3 {
+				return NormalizeLicense(parts[len(parts)-1])
+			}
+		}
+	}
+	return ""
+}
+
+// projectHomepage picks the link of a distribution: a GitHub project URL, else a source
+// or repository URL, else the home page
+func projectHomepage(projectURLs map[string]string, homepage string) string {
+	var repository, githubURL string
+	for key, url := range projectURLs {
+		if strings.Contains(strings.ToLower(url), "github") {
+			githubURL = url
+		}
+		if strings.Contains(strings.ToLower(key), "source") || strings.Contains(strings.ToLower(key), "repository") {
+			repository = url
+		}
+	}
+	if githubURL != "" {
+		return githubURL
+	}
+	if repository != "" {
+		return repository
+	}
+	return homepage
+}
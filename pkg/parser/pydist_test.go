@@ -0,0 +1,20 @@
+package parser
+
+import "testing"
+
+func TestIsPythonDist(t *testing.T) {
+	tests := map[string]bool{
+		"dist/requests-2.31.0.tar.gz":                                             true,
+		"requests-2.31.0-py3-none-any.whl":                                        true,
+		"numpy-1.26.0-cp312-cp312-manylinux_2_17_x86_64.manylinux2014_x86_64.whl": true,
+		"zope.interface-6.0.zip":                                                  true,
+		"release.zip":                                                             false,
+		"backup.tar.gz":                                                           false,
+		"requests.whl":                                                            false,
+	}
+	for filename, want := range tests {
+		if got := IsPythonDist(filename); got != want {
+			t.Errorf("IsPythonDist(%q) = %v, want %v", filename, got, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// IsPythonEnv reports whether filename is a Python virtual environment (a folder with a
+// pyvenv.cfg) or a site-packages folder, which are parsed for the distributions
+// installed in them
+func IsPythonEnv(filename string) bool {
+	info, err := os.Stat(filename)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	if base := filepath.Base(filename); base == "site-packages" || base == "dist-packages" {
+		return true
+	}
+	_, err = os.Stat(filepath.Join(filename, "pyvenv.cfg"))
+	return err == nil
+}
+
+// parsePythonEnv lists the distributions installed in a virtual environment or
+// site-packages folder, named after the environment's project folder
+func parsePythonEnv(filename string) ([]Package, string, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	folders := []string{abs}
+	env := abs
+	if base := filepath.Base(abs); base == "site-packages" || base == "dist-packages" {
+		// <env>/lib/pythonX.Y/site-packages or <env>/Lib/site-packages
+		env = filepath.Dir(filepath.Dir(abs))
+		if strings.EqualFold(filepath.Base(env), "lib") {
+			env = filepath.Dir(env)
+		}
+	} else {
+		folders = envSitePackages(abs)
+	}
+	// Environments kept in the project are named after the project
+	if slices.Contains(virtualenvDirs, filepath.Base(env)) {
+		env = filepath.Dir(env)
+	}
+	packages, err := ParseSitePackages(folders...)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, filepath.Base(env) + "-py", nil
+}
+
+// ParseSitePackages lists the distributions installed in site-packages folders from
+// their .dist-info METADATA, or the PKG-INFO of legacy .egg-info folders, with the
+// folder of each in Installed. A distribution installed in several folders is listed
+// from the first, as the interpreter imports it. When pip recorded which distributions
+// were requested by name (the REQUESTED file), the others are marked Indirect.
+func ParseSitePackages(folders ...string) ([]Package, error) {
+	var packages []Package
+	var requested []bool
+	seen := make(map[string]bool)
+	anyRequested := false
+	for _, folder := range folders {
+		entries, err := os.ReadDir(folder)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || !(strings.HasSuffix(name, ".dist-info") || strings.HasSuffix(name, ".egg-info")) {
+				continue
+			}
+			dir := filepath.Join(folder, name)
+			pkg, ok := readDistInfo(dir)
+			if !ok || seen[normalizeDistName(pkg.Path)] {
+				continue
+			}
+			seen[normalizeDistName(pkg.Path)] = true
+			pkg.Installed = dir
+			_, err := os.Stat(filepath.Join(dir, "REQUESTED"))
+			requested = append(requested, err == nil)
+			anyRequested = anyRequested || err == nil
+			packages = append(packages, pkg)
+		}
+	}
+	for i := range packages {
+		packages[i].Indirect = anyRequested && !requested[i]
+	}
+	return packages, nil
+}
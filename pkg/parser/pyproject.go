@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Parse pyproject.toml file
+func parsePyProjectToml(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var pyProject struct {
+		Project struct {
+			Name         string   `toml:"name"`
+			Dependencies []string `toml:"dependencies"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Name            string            `toml:"name"`
+				Dependencies    map[string]string `toml:"dependencies"`
+				DevDependencies map[string]string `toml:"dev-dependencies"`
+				// Poetry 1.2 groups, e.g. [tool.poetry.group.test.dependencies]
+				Group map[string]struct {
+					Dependencies map[string]string `toml:"dependencies"`
+				} `toml:"group"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+		BuildSystem struct {
+			Requires []string `toml:"requires"`
+		} `toml:"build-system"`
+	}
+
+	if err := toml.Unmarshal(data, &pyProject); err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+
+	// Handle Poetry dependencies
+	if pyProject.Tool.Poetry.Dependencies != nil {
+		for name, version := range pyProject.Tool.Poetry.Dependencies {
+			// Skip poetry itself and special entries
+			if name == "python" || strings.Contains(name, "poetry") {
+				continue
+			}
+			packages = append(packages, Package{
+				Path:      name,
+				Version:   version,
+				GoMod:     false,
+				PyProject: true,
+				Scope:     ScopeRuntime,
+			})
+		}
+	}
+
+	// Handle Poetry dev-dependencies
+	if pyProject.Tool.Poetry.DevDependencies != nil {
+		for name, version := range pyProject.Tool.Poetry.DevDependencies {
+			// Skip poetry itself and special entries
+			if name == "python" || strings.Contains(name, "poetry") {
+				continue
+			}
+			packages = append(packages, Package{
+				Path:      name,
+				Version:   version,
+				GoMod:     false,
+				PyProject: true,
+				Group:     "dev",
+				Scope:     ScopeDev,
+			})
+		}
+	}
+
+	// Handle Poetry dependency groups; only the main group is installed with the project
+	groups := make([]string, 0, len(pyProject.Tool.Poetry.Group))
+	for group := range pyProject.Tool.Poetry.Group {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		scope := ScopeDev
+		if group == "main" {
+			scope = ScopeRuntime
+		}
+		for name, version := range pyProject.Tool.Poetry.Group[group].Dependencies {
+			if name == "python" || strings.Contains(name, "poetry") {
+				continue
+			}
+			packages = append(packages, Package{
+				Path:      name,
+				Version:   version,
+				PyProject: true,
+				Group:     group,
+				Scope:     scope,
+			})
+		}
+	}
+
+	// Handle PEP 621 dependencies (project.dependencies)
+	if len(pyProject.Project.Dependencies) > 0 {
+		for _, dep := range pyProject.Project.Dependencies {
+			// PEP 508 strings such as "requests[security]>=2.0; python_version<'3.9'"
+			req, ok := parsePEP508(dep)
+			if !ok {
+				continue
+			}
+			pkg := Package{
+				Path:      req.Name,
+				Version:   req.Specifier,
+				GoMod:     false,
+				PyProject: true,
+				Scope:     ScopeRuntime,
+			}
+			if req.URL != "" {
+				pkg.Registry = req.URL
+				pkg.Homepage = req.URL
+			}
+			packages = append(packages, pkg)
+		}
+	}
+
+	// Determine project name
+	projectName := "python-project"
+	if pyProject.Tool.Poetry.Name != "" {
+		projectName = pyProject.Tool.Poetry.Name
+	} else if pyProject.Project.Name != "" {
+		projectName = pyProject.Project.Name
+	}
+
+	return packages, projectName + "-py", nil
+}
@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// eggPattern extracts the project name from the #egg= fragment of a VCS or local URL
+var eggPattern = regexp.MustCompile(`[#&]egg=([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// isRequirementsTxt reports whether filename is a pip requirements file, such as
+// requirements.txt, requirements-dev.txt or dev-requirements.txt
+func isRequirementsTxt(filename string) bool {
+	base := strings.ToLower(filepath.Base(filename))
+	return strings.HasSuffix(base, ".txt") && strings.Contains(base, "requirements")
+}
+
+// requirementLines reads a requirements file, joining backslash continuations and
+// dropping comments
+func requirementLines(filename string) ([]string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	var current strings.Builder
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// A comment starts at a # at the beginning of a line or after whitespace;
+		// a # inside a URL fragment (#egg=) is not a comment
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			line = ""
+		} else if idx := strings.Index(line, " #"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\") + " ")
+			continue
+		}
+		current.WriteString(line)
+		if text := strings.TrimSpace(current.String()); text != "" {
+			lines = append(lines, text)
+		}
+		current.Reset()
+	}
+	return lines, scanner.Err()
+}
+
+// parseRequirement converts one requirement line into a package. ok is false for
+// lines that do not name a distribution, such as local paths without #egg=.
+func parseRequirement(line string) (Package, bool) {
+	pkg := Package{PyProject: true}
+
+	// Editable installs: -e git+https://host/org/repo.git@v1.0#egg=name or -e ./path
+	editable := false
+	for _, prefix := range []string{"--editable=", "--editable ", "-e "} {
+		if strings.HasPrefix(line, prefix) {
+			line = strings.TrimSpace(strings.TrimPrefix(line, prefix))
+			editable = true
+			break
+		}
+	}
+	if editable || strings.Contains(line, "://") && !strings.Contains(line, " @ ") {
+		match := eggPattern.FindStringSubmatch(line)
+		if match == nil {
+			return pkg, false
+		}
+		pkg.Path = match[1]
+		url, _, _ := strings.Cut(line, "#")
+		if strings.Contains(url, "://") {
+			pkg.Registry = strings.TrimPrefix(url, "git+")
+			pkg.Homepage = pkg.Registry
+			// A VCS reference (@tag) is the closest thing to a version
+			if idx := strings.LastIndex(pkg.Registry, "@"); idx > strings.Index(pkg.Registry, "://")+3 {
+				pkg.Version = pkg.Registry[idx+1:]
+				pkg.Registry = pkg.Registry[:idx]
+				pkg.Homepage = pkg.Registry
+			}
+		}
+		return pkg, true
+	}
+
+	// Per-requirement options such as --hash=sha256:... follow the specifier
+	if idx := strings.Index(line, " --"); idx >= 0 {
+		line = line[:idx]
+	}
+	// Extras and environment markers do not change which distribution is meant
+	req, ok := parsePEP508(line)
+	if !ok {
+		return pkg, false
+	}
+	pkg.Path = req.Name
+	pkg.Version = req.Specifier
+	// PEP 508 direct reference: name @ https://...
+	if req.URL != "" {
+		pkg.Registry = req.URL
+		pkg.Homepage = pkg.Registry
+	}
+	return pkg, true
+}
+
+// requirementInclude returns the file named by a -r / --requirement line
+func requirementInclude(line string) (string, bool) {
+	for _, prefix := range []string{"--requirement=", "--requirement", "-r"} {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, prefix)), true
+		}
+	}
+	return "", false
+}
+
+// parseRequirementsFile collects the requirements of filename and of the files it
+// includes with -r, skipping files already visited
+func parseRequirementsFile(filename string, visited map[string]bool, seen map[string]bool) ([]Package, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	if visited[abs] {
+		return nil, nil
+	}
+	visited[abs] = true
+
+	lines, err := requirementLines(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []Package
+	for _, line := range lines {
+		// Nested requirement files are relative to the including file
+		if include, ok := requirementInclude(line); ok {
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(filename), include)
+			}
+			nested, err := parseRequirementsFile(include, visited, seen)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", filepath.Base(filename), err)
+			}
+			packages = append(packages, nested...)
+			continue
+		}
+
+		// Other options (-c constraints, --index-url, -f, ...) do not add packages
+		if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "-e") && !strings.HasPrefix(line, "--editable") {
+			continue
+		}
+
+		pkg, ok := parseRequirement(line)
+		if !ok {
+			continue
+		}
+		// PyPI names are case and separator insensitive; the first mention wins
+		key := strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(pkg.Path))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// parseRequirementsTxt parses a pip requirements file, including pinned versions,
+// ranges, environment markers, -r includes and editable installs
+func parseRequirementsTxt(filename string) ([]Package, string, error) {
+	packages, err := parseRequirementsFile(filename, make(map[string]bool), make(map[string]bool))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// requirements.txt has no project name, the directory name stands in for it
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, filepath.Base(filepath.Dir(abs)) + "-py", nil
+}
@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// isGemfile reports whether filename is a Bundler Gemfile or Gemfile.lock
+func isGemfile(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "Gemfile" || base == "Gemfile.lock" || base == "gems.rb" || base == "gems.locked"
+}
+
+// gemProjectName names a Ruby project after its directory
+func gemProjectName(filename string) (string, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(filepath.Dir(abs)) + "-rb", nil
+}
+
+// parseBundler parses a Gemfile, or the Gemfile.lock next to it when there is one
+// because it pins the exact version of every gem in the bundle
+func parseBundler(filename string) ([]Package, string, error) {
+	lockNames := map[string]string{"Gemfile": "Gemfile.lock", "gems.rb": "gems.locked"}
+	if lockName, ok := lockNames[filepath.Base(filename)]; ok {
+		lock := filepath.Join(filepath.Dir(filename), lockName)
+		if _, err := os.Stat(lock); err == nil {
+			filename = lock
+		}
+	}
+	if base := filepath.Base(filename); base == "Gemfile.lock" || base == "gems.locked" {
+		return parseGemfileLock(filename)
+	}
+	return parseGemfile(filename)
+}
+
+// parseGemfile reads the gem declarations of a Gemfile with their version
+// requirements and the group they belong to
+func parseGemfile(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	var groups []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if match := gemfileGroup.FindStringSubmatch(line); match != nil {
+			group := strings.NewReplacer(":", "", " ", "").Replace(match[1])
+			groups = append(groups, group)
+			continue
+		}
+		// Blocks other than groups (platforms, source, git) also end with end
+		if strings.HasSuffix(line, " do") || strings.HasSuffix(line, " do |") {
+			groups = append(groups, "")
+			continue
+		}
+		if line == "end" && len(groups) > 0 {
+			groups = groups[:len(groups)-1]
+			continue
+		}
+
+		match := gemfileGem.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		var requirements []string
+		for requirement := range strings.SplitSeq(match[2], ",") {
+			if requirement = strings.Trim(strings.TrimSpace(requirement), `"'`); requirement != "" {
+				requirements = append(requirements, requirement)
+			}
+		}
+
+		group := "default"
+		for i := len(groups) - 1; i >= 0; i-- {
+			if groups[i] != "" {
+				group = groups[i]
+				break
+			}
+		}
+		packages = append(packages, Package{Path: match[1], Version: strings.Join(requirements, ", "), Group: group})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+
+	name, err := gemProjectName(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
+
+// parseGemfileLock parses the GEM and GIT sections of a Gemfile.lock. Gems not listed
+// under DEPENDENCIES are only required by other gems and are marked indirect; gems
+// from PATH sections belong to the project itself.
+func parseGemfileLock(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	seen := make(map[string]bool)
+	direct := make(map[string]bool)
+	section, remote := "", ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" {
+			continue
+		}
+		if line[0] != ' ' {
+			section, remote = line, ""
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT":
+			if value, ok := strings.CutPrefix(line, "  remote: "); ok {
+				remote = value
+				continue
+			}
+			match := gemLockSpec.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			// Native gems carry their platform: nokogiri (1.15.4-x86_64-linux)
+			version, _, _ := strings.Cut(match[2], "-")
+			if seen[match[1]+"@"+version] {
+				continue
+			}
+			seen[match[1]+"@"+version] = true
+			pkg := Package{Path: match[1], Version: version}
+			if section == "GIT" {
+				pkg.Registry = remote
+				pkg.Homepage = remote
+			}
+			packages = append(packages, pkg)
+		case "DEPENDENCIES":
+			// rails (~> 7.0), or mygem! for gems from a git or path source
+			name, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+			direct[strings.TrimSuffix(name, "!")] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+
+	for i := range packages {
+		packages[i].Indirect = !direct[packages[i].Path]
+	}
+
+	name, err := gemProjectName(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name, nil
+}
+
+var (
+	// gemfileGem matches gem 'name', '~> 1.0', '>= 1.0.1' in a Gemfile
+	gemfileGem = regexp.MustCompile(`^gem\s+["']([^"']+)["']((?:\s*,\s*["'][^"']*["'])*)`)
+	// gemfileGroup matches the start of a group :development, :test do block
+	gemfileGroup = regexp.MustCompile(`^group\s+(.+?)\s+do\b`)
+	// gemLockSpec matches a gem of a Gemfile.lock specs list: four spaces, name (version)
+	gemLockSpec = regexp.MustCompile(`^    ([^\s(]+) \(([^)]+)\)$`)
+)
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// scanSkipDirs are never searched for manifests: installed or vendored dependencies,
+// build output, virtual environments and tool state, including the .license_fetcher
+// metadata cache
+var scanSkipDirs = map[string]bool{
+	".git": true, ".hg": true, ".svn": true, ".idea": true, ".vscode": true, ".gradle": true,
+	".license_fetcher": true, "node_modules": true, "bower_components": true, "vendor": true,
+	"target": true, "build": true, "dist": true, "bin": true, "obj": true,
+	".venv": true, "venv": true, "__pycache__": true, ".tox": true,
+}
+
+// scanSupersededBy lists, for each manifest, the sibling files that make it redundant:
+// lockfiles the manifest's parser already prefers, or lockfiles with exact versions
+// where the manifest only has ranges
+var scanSupersededBy = map[string][]string{
+	"package.json":    {"package-lock.json", "npm-shrinkwrap.json", "yarn.lock"},
+	"poetry.lock":     {"pyproject.toml"},
+	"Pipfile.lock":    {"Pipfile"},
+	"Cargo.lock":      {"Cargo.toml"},
+	"Gemfile.lock":    {"Gemfile"},
+	"gems.locked":     {"gems.rb"},
+	"composer.lock":   {"composer.json"},
+	"gradle.lockfile": {"build.gradle", "build.gradle.kts"},
+}
+
+// IsScanManifest reports whether a folder scan picks up filename. System databases,
+// binaries and distribution archives are only analyzed when selected directly. Every
+// file a registered parser detects is picked up.
+func IsScanManifest(filename string) bool {
+	for _, p := range registered {
+		if p.Detect(filename) {
+			return true
+		}
+	}
+	base := filepath.Base(filename)
+	switch base {
+	case "go.mod", "package.json", "pyproject.toml", "Pipfile", "Cargo.toml", "pom.xml",
+		"build.gradle", "build.gradle.kts", "Gemfile", "gems.rb", "composer.json":
+		return true
+	}
+	// Directory.Packages.props only supplies versions to the projects next to it
+	return base != "Directory.Packages.props" && (isPackageLock(base) || isYarnLock(base) ||
+		isPoetryLock(base) || isPipfile(base) || isRequirementsTxt(base) || isCargoManifest(base) ||
+		isGradleBuild(base) || isNuGetProject(base) || isGemfile(base) || isComposerManifest(base))
+}
+
+// FindManifests walks root and returns every manifest a folder scan analyzes, leaving
+// out those superseded by a sibling lockfile. The walk stops when ctx is cancelled.
+func FindManifests(ctx context.Context, root string) ([]string, error) {
+	byDir := make(map[string][]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && scanSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if IsScanManifest(path) {
+			byDir[filepath.Dir(path)] = append(byDir[filepath.Dir(path)], d.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []string
+	for dir, names := range byDir {
+		present := make(map[string]bool)
+		hasProject := false
+		for _, name := range names {
+			present[name] = true
+			if ext := strings.ToLower(filepath.Ext(name)); ext == ".csproj" || ext == ".fsproj" || ext == ".vbproj" {
+				hasProject = true
+			}
+		}
+
+		for _, name := range names {
+			superseded := false
+			for _, other := range scanSupersededBy[name] {
+				superseded = superseded || present[other]
+			}
+			// Project files read the packages.lock.json next to them
+			if name == "packages.lock.json" && hasProject {
+				superseded = true
+			}
+			if !superseded {
+				manifests = append(manifests, filepath.Join(dir, name))
+			}
+		}
+	}
+	sort.Strings(manifests)
+	return manifests, nil
+}
+
+// ScanFolder parses every manifest below root into one list of packages. A package used
+// by several projects is listed once, with all the projects in its Project field.
+// progress is told which manifest is parsed; a manifest that fails to parse is reported
+// to failed and skipped, so one broken file does not fail the scan of a whole repository.
+// The name returned is that of the folder. Cancelling ctx stops the scan with ctx's error.
+func ScanFolder(ctx context.Context, root string, progress func(text string), failed func(project string, err error)) ([]Package, string, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, "", err
+	}
+	manifests, err := FindManifests(ctx, abs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	index := make(map[string]int)
+	for _, filename := range manifests {
+		project, err := filepath.Rel(abs, filename)
+		if err != nil {
+			return nil, "", err
+		}
+		project = filepath.ToSlash(project)
+		if err := ctx.Err(); err != nil {
+			return nil, "", err
+		}
+		progress("Parsing " + project + "...")
+
+		parsed, err := Parse(filename)
+		if err != nil {
+			failed(project, err)
+			continue
+		}
+
+		for _, pkg := range parsed.Packages {
+			pkg.RepositoryType = parsed.Ecosystem
+			pkg.Project = project
+
+			key := pkg.RepositoryType + "\x00" + strings.ToLower(pkg.Path) + "\x00" + pkg.Version
+			if i, ok := index[key]; ok {
+				packages[i].Project += "; " + project
+				packages[i].Indirect = packages[i].Indirect && pkg.Indirect
+				continue
+			}
+			index[key] = len(packages)
+			packages = append(packages, pkg)
+		}
+	}
+
+	return packages, filepath.Base(abs), nil
+}
@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// isDpkgStatus reports whether filename is a Debian dpkg status database
+func isDpkgStatus(filename string) bool {
+	return filepath.Base(filename) == "status"
+}
+
+// isApkInstalled reports whether filename is an Alpine apk installed database
+func isApkInstalled(filename string) bool {
+	return filepath.Base(filename) == "installed"
+}
+
+// readStanzas splits an RFC 822 style file into blank-line separated stanzas.
+// Continuation lines (starting with whitespace) are appended to the previous
+// field, separated by a newline.
+func readStanzas(filename string) ([]map[string]string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var stanzas []map[string]string
+	current := make(map[string]string)
+	lastKey := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				stanzas = append(stanzas, current)
+				current = make(map[string]string)
+			}
+			lastKey = ""
+			continue
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && lastKey != "" {
+			current[lastKey] += "\n" + strings.TrimSpace(line)
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		lastKey = strings.TrimSpace(key)
+		current[lastKey] = strings.TrimSpace(value)
+	}
+	if len(current) > 0 {
+		stanzas = append(stanzas, current)
+	}
+
+	return stanzas, scanner.Err()
+}
+
+// rootfsName derives a report name from the root filesystem a database lives in
+func rootfsName(root string) string {
+	name := filepath.Base(filepath.Clean(root))
+	if name == "/" || name == "." || name == string(filepath.Separator) {
+		return "system"
+	}
+	return name
+}
+
+// Parse Debian dpkg status file (var/lib/dpkg/status)
+func parseDpkgStatus(filename string) ([]Package, string, error) {
+	stanzas, err := readStanzas(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// status lives in <rootfs>/var/lib/dpkg/status
+	root := filepath.Join(filepath.Dir(filename), "..", "..", "..")
+
+	var packages []Package
+	for _, stanza := range stanzas {
+		// Skip removed packages that only left config files behind
+		if status := stanza["Status"]; status != "" && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+		if stanza["Package"] == "" {
+			continue
+		}
+
+		description, _, _ := strings.Cut(stanza["Description"], "\n")
+		pkg := Package{
+			Path:        stanza["Package"],
+			Version:     stanza["Version"],
+			Author:      stanza["Maintainer"],
+			Description: description,
+			Homepage:    stanza["Homepage"],
+		}
+
+		copyright := filepath.Join(root, "usr", "share", "doc", pkg.Path, "copyright")
+		pkg.License, pkg.Copyright = parseDebianCopyright(copyright)
+
+		packages = append(packages, pkg)
+	}
+
+	return packages, rootfsName(root) + "-deb", nil
+}
+
+// Parse Alpine apk installed database (lib/apk/db/installed)
+func parseApkInstalled(filename string) ([]Package, string, error) {
+	stanzas, err := readStanzas(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for _, stanza := range stanzas {
+		if stanza["P"] == "" {
+			continue
+		}
+		packages = append(packages, Package{
+			Path:        stanza["P"],
+			Version:     stanza["V"],
+			License:     stanza["L"],
+			Author:      stanza["m"],
+			Description: stanza["T"],
+			Homepage:    stanza["U"],
+		})
+	}
+
+	// installed lives in <rootfs>/lib/apk/db/installed
+	root := filepath.Join(filepath.Dir(filename), "..", "..", "..")
+	return packages, rootfsName(root) + "-apk", nil
+}
+
+// parseDebianCopyright extracts the primary license and copyright holder from
+// a /usr/share/doc/<pkg>/copyright file. Machine-readable (DEP-5) files are
+// parsed properly, free-form files are searched for common-licenses references.
+func parseDebianCopyright(filename string) (string, string) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return "", ""
+	}
+	text := string(data)
+
+	if strings.HasPrefix(text, "Format:") {
+		stanzas, err := readStanzas(filename)
+		if err == nil {
+			license, copyright := "", ""
+			for _, stanza := range stanzas {
+				files, ok := stanza["Files"]
+				if !ok {
+					continue
+				}
+				stanzaLicense, _, _ := strings.Cut(stanza["License"], "\n")
+				stanzaCopyright, _, _ := strings.Cut(stanza["Copyright"], "\n")
+				// The "Files: *" stanza describes the package as a whole
+				if strings.TrimSpace(files) == "*" || license == "" {
+					license = strings.TrimSpace(stanzaLicense)
+					copyright = strings.TrimSpace(stanzaCopyright)
+				}
+				if strings.TrimSpace(files) == "*" {
+					break
+				}
+			}
+			if license != "" {
+				if copyright != "" && !strings.HasPrefix(strings.ToLower(copyright), "copyright") {
+					copyright = "Copyright " + copyright
+				}
+				return license, copyright
+			}
+		}
+	}
+
+	// Free-form copyright files usually point to /usr/share/common-licenses
+	for _, name := range []string{"GPL-3", "GPL-2", "LGPL-3", "LGPL-2.1", "LGPL-2", "Apache-2.0", "MPL-2.0", "Artistic", "BSD"} {
+		if strings.Contains(text, "common-licenses/"+name) {
+			copyright := ""
+			for line := range strings.SplitSeq(text, "\n") {
+				if strings.HasPrefix(strings.TrimSpace(strings.ToLower(line)), "copyright") {
+					copyright = strings.TrimSpace(line)
+					break
+				}
+			}
+			return name, copyright
+		}
+	}
+
+	return "", ""
+}
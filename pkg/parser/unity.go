@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// UnityRegistryURL is the default UPM registry used by the Unity editor
+const UnityRegistryURL = "https://packages.unity.com"
+
+// isUnityManifest reports whether filename is a Unity Packages/manifest.json or packages-lock.json
+func isUnityManifest(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "manifest.json" || base == "packages-lock.json"
+}
+
+// unityScopedRegistry maps package name prefixes to a custom registry
+type unityScopedRegistry struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Scopes []string `json:"scopes"`
+}
+
+// unityRegistryFor returns the registry a package resolves from, honouring scoped registries
+func unityRegistryFor(name string, registries []unityScopedRegistry) string {
+	registry := UnityRegistryURL
+	longest := 0
+	for _, scoped := range registries {
+		for _, scope := range scoped.Scopes {
+			if (name == scope || strings.HasPrefix(name, scope+".")) && len(scope) > longest {
+				registry = scoped.URL
+				longest = len(scope)
+			}
+		}
+	}
+	return strings.TrimSuffix(registry, "/")
+}
+
+// Parse Unity Packages/manifest.json, preferring the sibling packages-lock.json
+// because it records the resolved version and source of every package
+func parseUnityManifest(filename string) ([]Package, string, error) {
+	dir := filepath.Dir(filename)
+	// The project is the folder containing Packages/
+	projectName := filepath.Base(filepath.Dir(dir)) + "-upm"
+
+	var manifest struct {
+		Dependencies     map[string]string     `json:"dependencies"`
+		ScopedRegistries []unityScopedRegistry `json:"scopedRegistries"`
+	}
+	manifestPath := filepath.Join(dir, "manifest.json")
+	if data, err := ReadManifest(manifestPath); err == nil {
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, "", err
+		}
+	} else if filepath.Base(filename) == "manifest.json" {
+		return nil, "", err
+	}
+
+	lockPath := filepath.Join(dir, "packages-lock.json")
+	if data, err := ReadManifest(lockPath); err == nil {
+		var lock struct {
+			Dependencies map[string]struct {
+				Version string `json:"version"`
+				Source  string `json:"source"`
+				URL     string `json:"url"`
+				Hash    string `json:"hash"`
+			} `json:"dependencies"`
+		}
+		if err := json.Unmarshal(data, &lock); err != nil {
+			return nil, "", err
+		}
+
+		var packages []Package
+		for name, dep := range lock.Dependencies {
+			pkg := Package{Path: name, Version: dep.Version}
+			switch dep.Source {
+			case "builtin":
+				// Engine modules ship with the Unity editor itself
+				continue
+			case "registry":
+				pkg.Registry = strings.TrimSuffix(dep.URL, "/")
+			case "git":
+				pkg.Registry = dep.Version
+				pkg.Version = dep.Hash
+			default:
+				// embedded and local packages live inside the project
+				pkg.Registry = ""
+			}
+			packages = append(packages, pkg)
+		}
+		return packages, projectName, nil
+	} else if filepath.Base(filename) == "packages-lock.json" {
+		return nil, "", err
+	}
+
+	var packages []Package
+	for name, version := range manifest.Dependencies {
+		if strings.HasPrefix(name, "com.unity.modules.") {
+			continue
+		}
+		pkg := Package{Path: name, Version: version}
+		switch {
+		case strings.HasPrefix(version, "file:"):
+			// Local package, nothing to resolve
+		case IsUnityGitURL(version):
+			pkg.Registry = version
+			if _, rev, ok := strings.Cut(version, "#"); ok {
+				pkg.Version = rev
+			} else {
+				pkg.Version = ""
+			}
+		default:
+			pkg.Registry = unityRegistryFor(name, manifest.ScopedRegistries)
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, projectName, nil
+}
+
+// IsUnityGitURL reports whether a dependency source points at a git repository
+func IsUnityGitURL(source string) bool {
+	repo, _, _ := strings.Cut(source, "#")
+	repo, _, _ = strings.Cut(repo, "?")
+	return strings.HasPrefix(repo, "git@") || strings.HasPrefix(repo, "git+") ||
+		strings.HasPrefix(repo, "git://") || strings.HasSuffix(repo, ".git")
+}
@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// isYarnLock reports whether filename is a Yarn lockfile
+func isYarnLock(filename string) bool {
+	return filepath.Base(filename) == "yarn.lock"
+}
+
+// splitYarnDescriptor splits name@range at the first @ after a scope prefix:
+// @scope/pkg@^1.0.0 is @scope/pkg and ^1.0.0, alias@npm:real@^1.0.0 is alias and npm:real@^1.0.0
+func splitYarnDescriptor(descriptor string) (string, string) {
+	if descriptor == "" {
+		return "", ""
+	}
+	idx := strings.Index(descriptor[1:], "@")
+	if idx < 0 {
+		return descriptor, ""
+	}
+	return descriptor[:idx+1], descriptor[idx+2:]
+}
+
+// yarnPackageName returns the registry package an entry resolves to, following
+// npm: aliases. ok is false for workspace, link, portal, patch and other non-registry
+// sources.
+func yarnPackageName(descriptor string) (string, bool) {
+	name, reference := splitYarnDescriptor(descriptor)
+	if rest, isNPM := strings.CutPrefix(reference, "npm:"); isNPM {
+		// An alias names the real package: alias@npm:real@^1.0.0
+		if real, version := splitYarnDescriptor(rest); version != "" {
+			return real, true
+		}
+		return name, true
+	}
+	// workspace:, link:, portal:, patch:, file:, git+ssh: ... but not a plain range
+	if idx := strings.Index(reference, ":"); idx > 0 {
+		return name, false
+	}
+	return name, true
+}
+
+// yarnProjectName returns the name of the package.json next to a lockfile, falling
+// back to the directory name
+func yarnProjectName(filename string) (string, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(abs)
+
+	if data, err := ReadManifest(filepath.Join(dir, "package.json")); err == nil {
+		var manifest struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &manifest) == nil && manifest.Name != "" {
+			return manifest.Name, nil
+		}
+	}
+	return filepath.Base(dir), nil
+}
+
+// parseYarnLock parses a Yarn lockfile, both the classic v1 format and the YAML
+// lockfile of Yarn 2+ (Berry), into resolved package/version pairs
+func parseYarnLock(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	seen := make(map[string]bool)
+	var descriptor, version, resolution string
+	flush := func() {
+		defer func() { descriptor, version, resolution = "", "", "" }()
+		if descriptor == "" || version == "" {
+			return
+		}
+		// Berry records what the entry resolved to, which is more precise than the ranges
+		source := descriptor
+		if resolution != "" {
+			source = resolution
+		}
+		name, ok := yarnPackageName(source)
+		if !ok || seen[name+"@"+version] {
+			return
+		}
+		seen[name+"@"+version] = true
+		packages = append(packages, Package{Path: name, Version: version})
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// An unindented line starts the entry of one or more descriptors:
+		// "a@^1.0.0", "a@^1.1.0": (classic) or "a@npm:^1.0.0, a@npm:^1.1.0": (Berry)
+		if line[0] != ' ' && line[0] != '\t' {
+			flush()
+			key := strings.TrimSuffix(trimmed, ":")
+			if key == "__metadata" {
+				continue
+			}
+			first, _, _ := strings.Cut(key, ",")
+			descriptor = strings.Trim(strings.TrimSpace(first), `"`)
+			continue
+		}
+
+		// Only the entry's own fields are of interest, not nested dependency maps
+		if descriptor == "" || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t\t") {
+			continue
+		}
+		field, value, found := strings.Cut(trimmed, " ")
+		field = strings.TrimSuffix(field, ":")
+		if !found {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch field {
+		case "version":
+			version = value
+		case "resolution":
+			resolution = value
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+
+	name, err := yarnProjectName(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return packages, name + "-ui", nil
+}
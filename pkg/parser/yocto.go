@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// yoctoSniffLines is how many lines isYoctoManifest checks
+const yoctoSniffLines = 20
+
+// isYoctoManifest reports whether filename is a Yocto/BitBake manifest. Other tools
+// name files *.manifest too, such as Windows side-by-side and application manifests,
+// so the first lines must be license.manifest fields or "<package> <arch> <version>".
+func isYoctoManifest(filename string) bool {
+	if !strings.HasSuffix(filename, ".manifest") {
+		return false
+	}
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return false
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lines < yoctoSniffLines && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "PACKAGE NAME:") {
+			return true
+		}
+		if len(strings.Fields(line)) != 3 || strings.ContainsAny(line, "<>{}=\"") {
+			return false
+		}
+		lines++
+	}
+	return lines > 0
+}
+
+// yoctoLicense rewrites the BitBake license operators & and | into the AND and OR of
+// SPDX expressions, e.g. "GPL-2.0-only & bzip2-1.0.6" into "GPL-2.0-only AND bzip2-1.0.6"
+func yoctoLicense(license string) string {
+	license = strings.ReplaceAll(license, "&", " AND ")
+	license = strings.ReplaceAll(license, "|", " OR ")
+	return strings.Join(strings.Fields(license), " ")
+}
+
+// parseYoctoLicenseManifest reads a license.manifest produced by BitBake.
+// Each package is a block of "KEY: value" lines separated by blank lines:
+//
+//	PACKAGE NAME: busybox
+//	PACKAGE VERSION: 1.36.1
+//	RECIPE NAME: busybox
+//	LICENSE: GPL-2.0-only & bzip2-1.0.6
+//
+// Licenses are returned as SPDX expressions.
+func parseYoctoLicenseManifest(filename string) ([]Package, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []Package
+	var current Package
+
+	flush := func() {
+		if current.Path != "" {
+			packages = append(packages, current)
+		}
+		current = Package{}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "PACKAGE NAME":
+			// A new block may start without a blank separator line
+			if current.Path != "" {
+				flush()
+			}
+			current.Path = value
+		case "PACKAGE VERSION":
+			current.Version = value
+		case "RECIPE NAME":
+			current.Recipe = value
+		case "LICENSE":
+			current.License = yoctoLicense(value)
+		}
+	}
+	flush()
+
+	return packages, scanner.Err()
+}
+
+// parseYoctoImageManifest reads an image manifest (<image>.manifest), where
+// every line is "<package> <arch> <version>". Licenses are taken from a
+// license.manifest in the same directory when one is present.
+func parseYoctoImageManifest(filename string) ([]Package, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	licenses := make(map[string]Package)
+	sibling := filepath.Join(filepath.Dir(filename), "license.manifest")
+	if licensed, err := parseYoctoLicenseManifest(sibling); err == nil {
+		for _, pkg := range licensed {
+			licenses[pkg.Path] = pkg
+		}
+	}
+
+	var packages []Package
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		pkg := Package{
+			Path:    fields[0],
+			Version: fields[2],
+		}
+		if licensed, ok := licenses[pkg.Path]; ok {
+			pkg.License = licensed.License
+			pkg.Recipe = licensed.Recipe
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, scanner.Err()
+}
+
+// Parse Yocto license.manifest or image manifest file
+func parseYoctoManifest(filename string) ([]Package, string, error) {
+	data, err := ReadManifest(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packages []Package
+	var imageName string
+	if strings.Contains(string(data), "PACKAGE NAME:") {
+		packages, err = parseYoctoLicenseManifest(filename)
+		// license.manifest lives in a directory named after the image
+		imageName = filepath.Base(filepath.Dir(filename))
+	} else {
+		packages, err = parseYoctoImageManifest(filename)
+		imageName = strings.TrimSuffix(filepath.Base(filename), ".manifest")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return packages, imageName + "-yocto", nil
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsYoctoManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"license.manifest", "PACKAGE NAME: busybox\nPACKAGE VERSION: 1.36.1\nRECIPE NAME: busybox\nLICENSE: GPL-2.0-only & bzip2-1.0.6\n", true},
+		{"core-image-minimal.manifest", "base-files qemux86_64 3.0.14\nbusybox core2_64 1.36.1\n", true},
+		{"app.manifest", "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>\n<assembly xmlns=\"urn:schemas-microsoft-com:asm.v1\" manifestVersion=\"1.0\">\n", false},
+		{"Microsoft.Windows.Common-Controls.manifest", "<assembly manifestVersion=\"1.0\">\n  <assemblyIdentity type=\"win32\" name=\"Microsoft.Windows.Common-Controls\" version=\"6.0.0.0\"/>\n</assembly>\n", false},
+		{"empty.manifest", "", false},
+	}
+	for _, tt := range tests {
+		if got := isYoctoManifest(writeFile(t, tt.name, tt.content)); got != tt.want {
+			t.Errorf("isYoctoManifest(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseYoctoImageManifest(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "core-image-minimal")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"license.manifest":            "PACKAGE NAME: busybox\nPACKAGE VERSION: 1.36.1\nRECIPE NAME: busybox\nLICENSE: GPL-2.0-only & bzip2-1.0.6\n\nPACKAGE NAME: libc6\nPACKAGE VERSION: 2.38\nRECIPE NAME: glibc\nLICENSE: GPL-2.0-only | LGPL-2.1-only\n",
+		"core-image-minimal.manifest": "busybox core2_64 1.36.1\nlibc6 core2_64 2.38\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	project, err := Parse(filepath.Join(dir, "core-image-minimal.manifest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Ecosystem != "yocto" || project.Name != "core-image-minimal-yocto" {
+		t.Errorf("got ecosystem %q and name %q", project.Ecosystem, project.Name)
+	}
+	want := map[string]string{"busybox": "GPL-2.0-only AND bzip2-1.0.6", "libc6": "GPL-2.0-only OR LGPL-2.1-only"}
+	for _, pkg := range project.Packages {
+		if pkg.License != want[pkg.Path] {
+			t.Errorf("%s: license %q, want %q", pkg.Path, pkg.License, want[pkg.Path])
+		}
+	}
+	if len(project.Packages) != len(want) {
+		t.Errorf("got %d packages, want %d", len(project.Packages), len(want))
+	}
+}
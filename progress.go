@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fetchStats follows the fetch loop for the progress text: how fast packages go, how
+// long the rest should take, how many got a license and where their metadata came from
+type fetchStats struct {
+	start   time.Time
+	total   int
+	done    int
+	found   int // packages with a license
+	failed  int // packages left without one
+	origins map[string]int
+}
+
+// newFetchStats starts following a loop over total packages
+func newFetchStats(total int) *fetchStats {
+	return &fetchStats{start: time.Now(), total: total, origins: make(map[string]int)}
+}
+
+// catchUp counts the packages of infos added since the last call, origins being their
+// sources indexed the same way and at least as long
+func (s *fetchStats) catchUp(infos []PackageInfo, origins []string) {
+	for ; s.done < len(infos); s.done++ {
+		if infos[s.done].License != "" {
+			s.found++
+		} else {
+			s.failed++
+		}
+		switch origin := origins[s.done]; origin {
+		case "":
+		case originNone:
+			s.origins["not cached"]++
+		default:
+			s.origins[origin]++
+		}
+	}
+}
+
+// rate returns the packages handled per second so far
+func (s *fetchStats) rate() float64 {
+	elapsed := time.Since(s.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.done) / elapsed
+}
+
+// remaining estimates the time the packages left will take at the rate so far
+func (s *fetchStats) remaining() time.Duration {
+	rate := s.rate()
+	if rate == 0 {
+		return 0
+	}
+	return time.Duration(float64(s.total-s.done) / rate * float64(time.Second)).Round(time.Second)
+}
+
+// String summarizes the progress, e.g. "12/340, 3.4/s, 1m35s left; 10 found, 2 failed;
+// registry 8, user cache 4". The rate and time left show once a package is done.
+func (s *fetchStats) String() string {
+	parts := []string{fmt.Sprintf("%d/%d", s.done, s.total)}
+	if s.done > 0 {
+		parts[0] += fmt.Sprintf(", %.1f/s, %s left", s.rate(), s.remaining())
+		parts = append(parts, fmt.Sprintf("%d found, %d failed", s.found, s.failed))
+	}
+
+	var origins []string
+	for origin := range s.origins {
+		origins = append(origins, origin)
+	}
+	sort.Strings(origins)
+	for i, origin := range origins {
+		origins[i] = fmt.Sprintf("%s %d", origin, s.origins[origin])
+	}
+	if len(origins) > 0 {
+		parts = append(parts, strings.Join(origins, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
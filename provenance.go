@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// Confidence levels of the License Confidence column
+const (
+	confidenceHigh   = "high"   // an SPDX expression the package or its host declares
+	confidenceMedium = "medium" // a name or classifier mapped to SPDX, or a license text matched
+	confidenceLow    = "low"    // scraped from a web page, inferred, or a partial text match
+)
+
+// setLicenseSource records how the license of info was determined, e.g. "npm license
+// field", and how far it can be trusted
+func (info *PackageInfo) setLicenseSource(source string, confidence string) {
+	info.LicenseSource = source
+	info.LicenseConfidence = confidence
+}
+
+// licenseFamilies are names that are valid identifiers but leave the version or variant
+// open, such as "BSD" or "GPL"
+var licenseFamilies = []string{"AGPL", "Apache", "Artistic", "BSD", "CC-BY", "CDDL", "EPL", "EUPL", "GPL", "LGPL", "MPL"}
+
+// declaredConfidence rates a license a package declares: a valid SPDX expression is
+// taken as is, anything else had to be interpreted
+func declaredConfidence(license string) string {
+	expr, err := parseLicenseExpression(license)
+	if err != nil {
+		return confidenceMedium
+	}
+	for _, component := range expr.components() {
+		if slices.ContainsFunc(licenseFamilies, func(family string) bool { return strings.EqualFold(component, family) }) {
+			return confidenceMedium
+		}
+	}
+	return confidenceHigh
+}
+
+// detectedConfidence rates a license classified from its text by how closely the text
+// matched
+func detectedConfidence(confidence float64) string {
+	switch {
+	case confidence >= 0.95:
+		return confidenceHigh
+	case confidence >= customLicenseThreshold:
+		return confidenceMedium
+	}
+	return confidenceLow
+}
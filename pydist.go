@@ -0,0 +1,6 @@
+package main
+
+// Get metadata for a locally built Python distribution
+func getPythonDistMetadata(pkg *Package) PackageInfo {
+	return getDeclaredMetadata(pkg, "pypi")
+}
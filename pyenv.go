@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+)
+
+// pythonPathScript prints the prefix of an interpreter, then the folders it imports
+// from, one per line
+const pythonPathScript = "import sys\nprint(sys.prefix)\nfor p in sys.path:\n    print(p)"
+
+// pythonEnvironment lists the distributions an interpreter imports, from the
+// site-packages folders on its sys.path in import order, so the report covers what is
+// deployed rather than what the manifest asks for. The name returned is that of the
+// environment's project folder.
+func pythonEnvironment(ctx context.Context, interpreter string) ([]Package, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, interpreter, "-c", pythonPathScript)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w: %s", interpreter, err, strings.TrimSpace(stderr.String()))
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	prefix := strings.TrimSpace(lines[0])
+	var folders []string
+	for _, line := range lines[1:] {
+		folder := strings.TrimSpace(line)
+		if base := filepath.Base(folder); base != "site-packages" && base != "dist-packages" {
+			continue
+		}
+		if info, err := os.Stat(folder); err == nil && info.IsDir() && !slices.Contains(folders, folder) {
+			folders = append(folders, folder)
+		}
+	}
+	if len(folders) == 0 {
+		return nil, "", fmt.Errorf("%s imports from no site-packages folder", interpreter)
+	}
+
+	packages, err := parser.ParseSitePackages(folders...)
+	if err != nil {
+		return nil, "", err
+	}
+	// Environments kept in the project are named after the project
+	name := filepath.Base(prefix)
+	if slices.Contains([]string{".venv", "venv", "env", ".env"}, name) {
+		name = filepath.Base(filepath.Dir(prefix))
+	}
+	return packages, name + "-py", nil
+}
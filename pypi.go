@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// pypiProject is a document of the PyPI JSON API: /pypi/<name>/json describes the
+// latest release and lists every release, /pypi/<name>/<version>/json describes one
+type pypiProject struct {
+	Info struct {
+		Version      string            `json:"version"`
+		Author       string            `json:"author"`
+		AuthorEmail  string            `json:"author_email"`
+		Classifiers  []string          `json:"classifiers"`
+		Description  string            `json:"description"`
+		Summary      string            `json:"summary"`
+		Home_page    string            `json:"home_page"`
+		License      string            `json:"license"`
+		Project_urls map[string]string `json:"project_urls"`
+		// Set on the document of a yanked release
+		Yanked       bool   `json:"yanked"`
+		YankedReason string `json:"yanked_reason"`
+	} `json:"info"`
+	Releases map[string][]pypiFile `json:"releases"`
+}
+
+// pypiFile is one file of a release, a wheel or an sdist
+type pypiFile struct {
+	PythonVersion string `json:"python_version"`
+	UploadTime    string `json:"upload_time"`
+	Yanked        bool   `json:"yanked"`
+}
+
+// releases lists the releases that have files, for resolvePythonVersion
+func (p *pypiProject) releases() []pythonRelease {
+	releases := make([]pythonRelease, 0, len(p.Releases))
+	for version, files := range p.Releases {
+		if len(files) == 0 {
+			continue
+		}
+		yanked := !slices.ContainsFunc(files, func(file pypiFile) bool { return !file.Yanked })
+		releases = append(releases, pythonRelease{version, yanked})
+	}
+	return releases
+}
+
+// getPyPIJSON fetches a document of the PyPI JSON API, such as "requests/json", from
+// the configured indexes in turn; found is false when none knows the project or release
+func getPyPIJSON(ctx context.Context, client *http.Client, path string, v any) (found bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := registryGet(ctx, client, registries.pypiJSONURLs(), path)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == 404 {
+		return false, nil
+	}
+	if resp.StatusCode != 200 {
+		return false, fmt.Errorf("PyPI returned status %d", resp.StatusCode)
+	}
+	return true, json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,644 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// Public registries queried unless the project or user configures others
+const (
+	defaultNPMRegistry = "https://registry.npmjs.org/"
+	defaultPyPIIndex   = "https://pypi.org/simple"
+	defaultGoProxy     = "https://proxy.golang.org"
+)
+
+// registryCredential is the Authorization header sent with requests below prefix, a
+// scheme-less URL such as //npm.corp.example/repository/npm/
+type registryCredential struct {
+	prefix string
+	header string
+}
+
+// goProxy is an entry of GOPROXY: a module proxy URL, direct or off
+type goProxy struct {
+	url string
+	// anyError is set when a "|" follows the entry, so the next one is tried after any
+	// failure rather than only after a 404 or 410 answer
+	anyError bool
+}
+
+// registryConfig holds the npm registry, PyPI index and Go module proxy to query and
+// the credentials of private ones, read from .npmrc, pip.conf and the Go environment
+type registryConfig struct {
+	npmRegistry string
+	npmScopes   map[string]string // @scope -> registry
+	pypiIndex   string
+	pypiExtra   []string  // extra-index-url entries, asked after pypiIndex
+	goProxies   []goProxy // GOPROXY entries, including direct and off
+	goNoProxy   string    // module patterns fetched directly, never through a proxy
+	goPrivate   string    // module patterns unknown to public services such as pkg.go.dev
+	credentials []registryCredential
+
+	// publicFallback asks the public npm registry and PyPI for the packages a private
+	// registry does not know, such as those a mirror does not pass through
+	publicFallback bool
+}
+
+// registries is the configuration loaded for the project being processed
+var registries = &registryConfig{
+	npmRegistry:    defaultNPMRegistry,
+	npmScopes:      map[string]string{},
+	pypiIndex:      defaultPyPIIndex,
+	goProxies:      []goProxy{{url: defaultGoProxy}},
+	publicFallback: true,
+}
+
+// registryMirrors are the npm registry and PyPI index given on the command line, such
+// as an Artifactory or Nexus repository, which replace those of the configuration files,
+// and the regional mirrors of -mirror, which only replace the public registries
+type registryMirrors struct {
+	npmRegistry    string
+	pypiIndex      string
+	regional       regionalMirror
+	publicFallback bool
+}
+
+// regionalMirror is a public mirror of the npm registry, PyPI or the Go module proxy
+// closer to the user; empty fields leave that registry alone
+type regionalMirror struct {
+	npm     string
+	pypi    string
+	goProxy string
+}
+
+// regionalMirrors are the mirrors -mirror selects by name
+var regionalMirrors = map[string]regionalMirror{
+	"npmmirror":  {npm: "https://registry.npmmirror.com/"},
+	"tuna":       {pypi: "https://pypi.tuna.tsinghua.edu.cn/simple"},
+	"aliyun":     {pypi: "https://mirrors.aliyun.com/pypi/simple", goProxy: "https://mirrors.aliyun.com/goproxy"},
+	"goproxy.cn": {goProxy: "https://goproxy.cn"},
+	"cn":         {npm: "https://registry.npmmirror.com/", pypi: "https://pypi.tuna.tsinghua.edu.cn/simple", goProxy: "https://goproxy.cn"},
+}
+
+// parseRegionalMirrors reads the comma-separated mirror names of -mirror, later names
+// replacing the registries of earlier ones
+func parseRegionalMirrors(value string) (regionalMirror, error) {
+	var mirror regionalMirror
+	for name := range strings.SplitSeq(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		preset, ok := regionalMirrors[name]
+		if !ok {
+			names := slices.Sorted(maps.Keys(regionalMirrors))
+			return regionalMirror{}, fmt.Errorf("unknown mirror %q (expected %s)", name, strings.Join(names, ", "))
+		}
+		mirror.npm = cmp.Or(preset.npm, mirror.npm)
+		mirror.pypi = cmp.Or(preset.pypi, mirror.pypi)
+		mirror.goProxy = cmp.Or(preset.goProxy, mirror.goProxy)
+	}
+	return mirror, nil
+}
+
+// loadRegistryConfig reads the registry configuration that applies to a project in dir:
+// the project and user .npmrc, pip.conf and the GOPROXY, GOPRIVATE, GONOPROXY and
+// GONOSUMDB (or GONOSUMCHECK) settings, with the environment taking precedence, and
+// the command line over both
+func loadRegistryConfig(ctx context.Context, dir string, mirrors registryMirrors) *registryConfig {
+	c := &registryConfig{
+		npmRegistry:    defaultNPMRegistry,
+		npmScopes:      map[string]string{},
+		pypiIndex:      defaultPyPIIndex,
+		publicFallback: mirrors.publicFallback,
+	}
+	c.loadNPMConfig(dir)
+	c.loadPipConfig()
+	// Regional mirrors stand in for the public registries, never for private ones
+	if mirrors.regional.npm != "" && c.npmRegistry == defaultNPMRegistry {
+		c.npmRegistry = mirrors.regional.npm
+	}
+	if mirrors.regional.pypi != "" && strings.TrimSuffix(c.pypiIndex, "/") == defaultPyPIIndex {
+		c.pypiIndex = mirrors.regional.pypi
+	}
+	if mirrors.npmRegistry != "" {
+		c.npmRegistry = strings.TrimSuffix(c.stripUserinfo(mirrors.npmRegistry), "/") + "/"
+	}
+	if mirrors.pypiIndex != "" {
+		c.pypiIndex = c.stripUserinfo(mirrors.pypiIndex)
+	}
+	c.loadGoConfig(ctx)
+	if mirrors.regional.goProxy != "" {
+		c.addGoProxyMirror(mirrors.regional.goProxy)
+	}
+	c.loadNetrc()
+	return c
+}
+
+// addGoProxyMirror asks a mirror of the public Go module proxy before it, which is
+// still asked for the modules the mirror answers 404 or 410 for
+func (c *registryConfig) addGoProxyMirror(mirror string) {
+	i := slices.IndexFunc(c.goProxies, func(proxy goProxy) bool { return proxy.url == defaultGoProxy })
+	if i >= 0 {
+		c.goProxies = slices.Insert(c.goProxies, i, goProxy{url: mirror})
+	}
+}
+
+// expandConfigValue replaces ${VAR} references the way npm does for .npmrc values
+func expandConfigValue(value string) string {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	return os.Expand(value, func(name string) string {
+		return os.Getenv(name)
+	})
+}
+
+// addCredential registers the Authorization header for requests below prefix, keeping
+// the first one added for a prefix
+func (c *registryConfig) addCredential(prefix string, header string) {
+	if prefix == "" || header == "" {
+		return
+	}
+	for _, cred := range c.credentials {
+		if cred.prefix == prefix {
+			return
+		}
+	}
+	c.credentials = append(c.credentials, registryCredential{prefix: prefix, header: header})
+}
+
+// basicAuth renders an Authorization header for a user name and password
+func basicAuth(user string, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+// stripUserinfo removes the credentials from a registry URL, registering them as basic
+// authentication for its host: a PyPI index serves its JSON API beside the simple index
+func (c *registryConfig) stripUserinfo(registry string) string {
+	u, err := url.Parse(registry)
+	if err != nil || u.User == nil {
+		return registry
+	}
+	password, _ := u.User.Password()
+	user := u.User.Username()
+	u.User = nil
+	c.addCredential("//"+u.Host+"/", basicAuth(user, password))
+	return u.String()
+}
+
+// loadNPMConfig reads registry, @scope:registry and the per-registry _authToken, _auth
+// or username and _password settings of the project .npmrc, then the user one
+func (c *registryConfig) loadNPMConfig(dir string) {
+	userConfig := os.Getenv("NPM_CONFIG_USERCONFIG")
+	if userConfig == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			userConfig = filepath.Join(home, ".npmrc")
+		}
+	}
+
+	settings := map[string]string{}
+	for _, name := range []string{filepath.Join(dir, ".npmrc"), userConfig} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		for line := range strings.SplitSeq(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				continue
+			}
+			// The project file is read first and wins
+			if _, seen := settings[key]; !seen {
+				settings[key] = expandConfigValue(value)
+			}
+		}
+	}
+	if registry := os.Getenv("npm_config_registry"); registry != "" {
+		settings["registry"] = registry
+	}
+
+	if registry := settings["registry"]; registry != "" {
+		c.npmRegistry = strings.TrimSuffix(c.stripUserinfo(registry), "/") + "/"
+	}
+	// The former Taobao registry moved to npmmirror, still the registry of many setups
+	if c.npmRegistry == "https://registry.npm.taobao.org/" || c.npmRegistry == "http://registry.npm.taobao.org/" {
+		c.npmRegistry = regionalMirrors["npmmirror"].npm
+	}
+	users := map[string]string{}
+	for key, value := range settings {
+		switch {
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			c.npmScopes[strings.TrimSuffix(key, ":registry")] = strings.TrimSuffix(c.stripUserinfo(value), "/") + "/"
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_authToken"):
+			c.addCredential(strings.TrimSuffix(strings.TrimSuffix(key, ":_authToken"), "/")+"/", "Bearer "+value)
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_auth"):
+			c.addCredential(strings.TrimSuffix(strings.TrimSuffix(key, ":_auth"), "/")+"/", "Basic "+value)
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":username"):
+			users[strings.TrimSuffix(key, ":username")] = value
+		}
+	}
+	for prefix, user := range users {
+		// npm stores the password base64 encoded
+		password, err := base64.StdEncoding.DecodeString(settings[prefix+":_password"])
+		if err == nil {
+			c.addCredential(strings.TrimSuffix(prefix, "/")+"/", basicAuth(user, string(password)))
+		}
+	}
+}
+
+// pipConfigFiles lists the pip configuration files from the most to the least specific
+func pipConfigFiles() []string {
+	if name := os.Getenv("PIP_CONFIG_FILE"); name != "" {
+		return []string{name}
+	}
+
+	var files []string
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			files = append(files, filepath.Join(appData, "pip", "pip.ini"))
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			files = append(files, filepath.Join(home, "pip", "pip.ini"))
+		}
+		return append(files, filepath.Join(os.Getenv("ProgramData"), "pip", "pip.ini"))
+	}
+	if configHome, err := os.UserConfigDir(); err == nil {
+		files = append(files, filepath.Join(configHome, "pip", "pip.conf"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".pip", "pip.conf"))
+	}
+	return append(files, "/etc/xdg/pip/pip.conf", "/etc/pip.conf")
+}
+
+// pipSetting returns the value of a key in the [global] or [install] section of a pip
+// configuration file, with its continuation lines, or ""
+func pipSetting(name string, key string) string {
+	file, err := os.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	section := ""
+	var value []string
+	found := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		// Indented lines continue the value of the key above, as extra-index-url often is
+		if found && line != "" && (raw[0] == ' ' || raw[0] == '\t') {
+			value = append(value, expandConfigValue(line))
+			continue
+		}
+		if found {
+			break
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+		if section != "global" && section != "install" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(k) == key {
+			found = true
+			if v := expandConfigValue(v); v != "" {
+				value = append(value, v)
+			}
+		}
+	}
+	return strings.Join(value, " ")
+}
+
+// loadPipConfig reads the index URL and extra index URLs, with their basic
+// authentication, from PIP_INDEX_URL and PIP_EXTRA_INDEX_URL or the first pip
+// configuration file setting them
+func (c *registryConfig) loadPipConfig() {
+	index := os.Getenv("PIP_INDEX_URL")
+	extra := os.Getenv("PIP_EXTRA_INDEX_URL")
+	for _, name := range pipConfigFiles() {
+		if index == "" {
+			index = pipSetting(name, "index-url")
+		}
+		if extra == "" {
+			extra = pipSetting(name, "extra-index-url")
+		}
+	}
+	if index != "" {
+		c.pypiIndex = c.stripUserinfo(index)
+	}
+	for _, extraIndex := range strings.Fields(extra) {
+		c.pypiExtra = append(c.pypiExtra, c.stripUserinfo(extraIndex))
+	}
+}
+
+// goEnv returns the Go settings the go command would use, including those written by
+// go env -w, falling back to the environment when Go is not installed
+func goEnv(ctx context.Context, names ...string) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	env := map[string]string{}
+	out, err := exec.CommandContext(ctx, "go", append([]string{"env", "-json"}, names...)...).Output()
+	if err == nil && json.Unmarshal(out, &env) == nil {
+		return env
+	}
+	for _, name := range names {
+		env[name] = os.Getenv(name)
+	}
+	if env["GOPROXY"] == "" {
+		env["GOPROXY"] = defaultGoProxy + ",direct"
+	}
+	return env
+}
+
+// loadGoConfig reads the module proxies and the private module patterns
+func (c *registryConfig) loadGoConfig(ctx context.Context) {
+	env := goEnv(ctx, "GOPROXY", "GOPRIVATE", "GONOPROXY", "GONOSUMDB")
+
+	// Entries are separated by "," to fall back after a 404 or 410, or by "|" to fall
+	// back after any error
+	list := env["GOPROXY"]
+	for list != "" {
+		entry := list
+		anyError := false
+		if i := strings.IndexAny(list, ",|"); i >= 0 {
+			entry, anyError, list = list[:i], list[i] == '|', list[i+1:]
+		} else {
+			list = ""
+		}
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if entry != "direct" && entry != "off" {
+			entry = strings.TrimSuffix(c.stripUserinfo(entry), "/")
+		}
+		c.goProxies = append(c.goProxies, goProxy{url: entry, anyError: anyError})
+	}
+
+	c.goNoProxy = env["GONOPROXY"]
+	if c.goNoProxy == "" {
+		c.goNoProxy = env["GOPRIVATE"]
+	}
+	// GONOSUMCHECK is the older name some setups still export for GONOSUMDB
+	var private []string
+	for _, patterns := range []string{env["GOPRIVATE"], env["GONOSUMDB"], os.Getenv("GONOSUMCHECK")} {
+		if patterns != "" {
+			private = append(private, patterns)
+		}
+	}
+	c.goPrivate = strings.Join(private, ",")
+}
+
+// netrcFile returns the path of the user's netrc file
+func netrcFile() string {
+	if name := os.Getenv("NETRC"); name != "" {
+		return name
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// loadNetrc adds the netrc login of every configured private registry without
+// credentials of its own, as the go command and pip do
+func (c *registryConfig) loadNetrc() {
+	data, err := os.ReadFile(netrcFile())
+	if err != nil {
+		return
+	}
+
+	logins := map[string][2]string{}
+	var machine string
+	fields := strings.Fields(string(data))
+	for i := 0; i+1 < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			machine = fields[i+1]
+			i++
+		case "default":
+			machine = ""
+		case "login", "password":
+			login := logins[machine]
+			if fields[i] == "login" {
+				login[0] = fields[i+1]
+			} else {
+				login[1] = fields[i+1]
+			}
+			logins[machine] = login
+			i++
+		}
+	}
+
+	registries := append([]string{c.npmRegistry, c.pypiIndex}, c.pypiExtra...)
+	for _, proxy := range c.goProxies {
+		registries = append(registries, proxy.url)
+	}
+	for _, registry := range c.npmScopes {
+		registries = append(registries, registry)
+	}
+	for _, registry := range registries {
+		u, err := url.Parse(registry)
+		if err != nil || u.Host == "" || c.authorization(u) != "" {
+			continue
+		}
+		if login, ok := logins[u.Hostname()]; ok {
+			c.addCredential("//"+u.Host+"/", basicAuth(login[0], login[1]))
+		}
+	}
+}
+
+// authorization returns the Authorization header configured for a request URL, that of
+// the longest matching registry prefix, or ""
+func (c *registryConfig) authorization(u *url.URL) string {
+	target := "//" + u.Host + u.Path
+	header, longest := "", 0
+	for _, cred := range c.credentials {
+		if strings.HasPrefix(target, cred.prefix) && len(cred.prefix) > longest {
+			header, longest = cred.header, len(cred.prefix)
+		}
+	}
+	return header
+}
+
+// npmRegistryURL returns the registry serving an npm package, ending in a slash:
+// the registry of its scope, or the default registry
+func (c *registryConfig) npmRegistryURL(name string) string {
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		if registry, ok := c.npmScopes[scope]; ok {
+			return registry
+		}
+	}
+	return c.npmRegistry
+}
+
+// npmRegistryURLs returns the registries asked for an npm package in turn: the registry
+// of its scope alone, as scoped registries hold internal packages, or the configured
+// registry followed by the public one, for the packages a mirror does not pass through
+func (c *registryConfig) npmRegistryURLs(name string) []string {
+	registry := c.npmRegistryURL(name)
+	if registry != c.npmRegistry || registry == defaultNPMRegistry || !c.publicFallback {
+		return []string{registry}
+	}
+	return []string{registry, defaultNPMRegistry}
+}
+
+// pypiJSONURLs returns the bases of the PyPI JSON API asked in turn: that of the index,
+// those of the extra indexes, and public PyPI for the projects none of them knows
+func (c *registryConfig) pypiJSONURLs() []string {
+	indexes := append([]string{c.pypiIndex}, c.pypiExtra...)
+	if c.publicFallback {
+		indexes = append(indexes, defaultPyPIIndex)
+	}
+	var bases []string
+	for _, index := range indexes {
+		if base := pypiJSONBase(index); !slices.Contains(bases, base) {
+			bases = append(bases, base)
+		}
+	}
+	return bases
+}
+
+// pypiJSONBase returns the base of the PyPI JSON API, ending in /pypi/. Indexes such as
+// devpi, Nexus or Artifactory serve it next to the simple index configured for pip.
+func pypiJSONBase(index string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(index, "/"), "/simple") + "/pypi/"
+}
+
+// registryGet requests path below each registry base in turn, asking the next one after
+// a 404 or 410 answer, as a mirror gives for packages it neither hosts nor passes
+// through. It returns the last answer, which the caller closes.
+func registryGet(ctx context.Context, client *http.Client, bases []string, path string) (*http.Response, error) {
+	for i, base := range bases {
+		req, err := http.NewRequestWithContext(ctx, "GET", base+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if (resp.StatusCode == 404 || resp.StatusCode == 410) && i < len(bases)-1 {
+			resp.Body.Close()
+			slog.Debug("not found on registry, asking the next one", "url", base+path, "next", bases[i+1])
+			continue
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("%s: no registry configured", path)
+}
+
+// goProxyURLs returns the module proxies a module is fetched from, in the order of
+// GOPROXY up to direct or off, with whether each falls back on any error; none when
+// the module matches GONOPROXY or GOPRIVATE
+func (c *registryConfig) goProxyURLs(modulePath string) []goProxy {
+	if module.MatchPrefixPatterns(c.goNoProxy, modulePath) {
+		return nil
+	}
+	var proxies []goProxy
+	for _, proxy := range c.goProxies {
+		if proxy.url == "direct" || proxy.url == "off" {
+			break
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+// goProxyURL returns the first module proxy a module is fetched from, or "" when it is
+// fetched directly from version control or GOPROXY is off
+func (c *registryConfig) goProxyURL(modulePath string) string {
+	if proxies := c.goProxyURLs(modulePath); len(proxies) > 0 {
+		return proxies[0].url
+	}
+	return ""
+}
+
+// goProxyGet requests a file of a module, such as "@v/v1.0.0.info", from its module
+// proxies the way the go command does: the next proxy is asked after a 404 or 410, or
+// after any failure of a proxy followed by "|". It returns the last answer, which the
+// caller closes, or an error when no proxy answered.
+func goProxyGet(ctx context.Context, client *http.Client, modulePath string, file string) (*http.Response, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	proxies := registries.goProxyURLs(modulePath)
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%s: not fetched through a module proxy", modulePath)
+	}
+
+	for i, proxy := range proxies {
+		last := i == len(proxies)-1
+		req, err := http.NewRequestWithContext(ctx, "GET", proxy.url+"/"+escapedPath+"/"+file, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			if proxy.anyError && !last && ctx.Err() == nil {
+				continue
+			}
+			return nil, err
+		}
+		fallBack := resp.StatusCode == 404 || resp.StatusCode == 410 || (proxy.anyError && resp.StatusCode != 200)
+		if fallBack && !last {
+			resp.Body.Close()
+			continue
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("%s: no module proxy answered", modulePath)
+}
+
+// isPrivateGoModule reports whether a module matches GOPRIVATE, GONOSUMDB or
+// GONOSUMCHECK, so public services such as pkg.go.dev cannot know it
+func (c *registryConfig) isPrivateGoModule(modulePath string) bool {
+	return module.MatchPrefixPatterns(c.goPrivate, modulePath)
+}
+
+// registryTransport adds the configured registry credentials to requests that carry
+// no Authorization header of their own
+type registryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *registryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		if header := registries.authorization(req.URL); header != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", header)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
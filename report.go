@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+	"github.com/xuri/excelize/v2"
+)
+
+// reportColumnAliases lists the header names each field uses across the report layouts
+var reportColumnAliases = map[string][]string{
+	"name":           {"Module Name (No Version)", "Name", "Package Name"},
+	"version":        {"PackageVersion", "Version"},
+	"license":        {"License"},
+	"licenseURL":     {"LicenseURL", "License URL"},
+	"author":         {"Author"},
+	"description":    {"Description"},
+	"copyright":      {"Copyright"},
+	"packageURL":     {"PackageURL", "Package URL"},
+	"repository":     {"Repository"},
+	"githubURL":      {"GitHubURL", "GitHub URL"},
+	"repositoryType": {"Ecosystem", "RepositoryType", "Repository Type"},
+}
+
+// reportRow is a data row of a previously generated report
+type reportRow struct {
+	Index int // 0-based row index across the dependency sheets, the header being row 0
+	Info  PackageInfo
+	Cells []string
+}
+
+// reportSheet is the parsed dependency list of a previously generated report
+type reportSheet struct {
+	Header  []string
+	Rows    []reportRow
+	columns map[string]int
+}
+
+// column returns the index of a logical field in the header, or -1
+func (r *reportSheet) column(field string) int {
+	for _, alias := range reportColumnAliases[field] {
+		if i, ok := r.columns[alias]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// readReport loads the dependency sheets of a report generated by this tool
+func readReport(filename string) (*reportSheet, error) {
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := readDependencyRows(f)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &reportSheet{columns: make(map[string]int)}
+	if len(rows) == 0 {
+		return sheet, nil
+	}
+
+	sheet.Header = rows[0]
+	for i, name := range rows[0] {
+		sheet.columns[columnName(name)] = i
+	}
+
+	get := func(row []string, field string) string {
+		if i := sheet.column(field); i >= 0 && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	for i, row := range rows[1:] {
+		info := PackageInfo{
+			Name:           get(row, "name"),
+			Version:        get(row, "version"),
+			License:        get(row, "license"),
+			LicenseURL:     get(row, "licenseURL"),
+			Author:         get(row, "author"),
+			Description:    get(row, "description"),
+			Copyright:      get(row, "copyright"),
+			PackageURL:     get(row, "packageURL"),
+			Repository:     get(row, "repository"),
+			GitHubURL:      get(row, "githubURL"),
+			RepositoryType: get(row, "repositoryType"),
+		}
+		// The npm layout has no repository type column
+		if info.RepositoryType == "" && sheet.column("name") >= 0 && sheet.Header[sheet.column("name")] == "Module Name (No Version)" {
+			info.RepositoryType = "npm"
+		}
+		info.ModuleNameNoVer = info.Name
+		if info.Name == "" {
+			continue
+		}
+		sheet.Rows = append(sheet.Rows, reportRow{Index: i + 1, Info: info, Cells: row})
+	}
+
+	return sheet, nil
+}
+
+// metadataGetter returns the fetcher chain of a repository type, or nil for ecosystems
+// whose metadata only exists in the original manifest
+func metadataGetter(repositoryType string) func(context.Context, *Package) PackageInfo {
+	chain := fetchersFor(repositoryType)
+	if !slices.ContainsFunc(chain.fetchers, func(f MetadataFetcher) bool { return f.Kind() != fetchDeclared }) {
+		return nil
+	}
+	if repositoryType == "upm" {
+		// Reports do not keep the registry of a package; rows are checked against Unity's
+		return func(ctx context.Context, pkg *Package) PackageInfo {
+			pkg.Registry = parser.UnityRegistryURL
+			return chain.fetch(ctx, pkg)
+		}
+	}
+	return chain.fetch
+}
+
+// normalizeRepositoryURL strips cosmetic differences so repository links can be compared
+func normalizeRepositoryURL(repoURL string) string {
+	repoURL = strings.ToLower(canonicalRepositoryURL(repoURL))
+	repoURL = strings.TrimPrefix(repoURL, "https://")
+	return strings.TrimPrefix(repoURL, "http://")
+}
+
+// primaryRepository returns the repository link used when comparing rows
+func primaryRepository(info PackageInfo) string {
+	if info.Repository != "" {
+		return info.Repository
+	}
+	return info.GitHubURL
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestMetadataGetter(t *testing.T) {
+	for repositoryType := range ecosystemFetchers {
+		getter := metadataGetter(repositoryType)
+		switch repositoryType {
+		case "yocto", "deb", "apk":
+			if getter != nil {
+				t.Errorf("%s: metadata only exists in the manifest, want no getter", repositoryType)
+			}
+		default:
+			if getter == nil {
+				t.Errorf("%s: no getter for an ecosystem with a registry", repositoryType)
+			}
+		}
+	}
+	if metadataGetter("conan") != nil {
+		t.Error("conan: want no getter for an ecosystem without a registry")
+	}
+}
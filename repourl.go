@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// repositoryShorthands are the hosts of the "host:user/repo" shorthands npm accepts
+var repositoryShorthands = map[string]string{
+	"github":    "https://github.com/",
+	"gitlab":    "https://gitlab.com/",
+	"bitbucket": "https://bitbucket.org/",
+	"gist":      "https://gist.github.com/",
+}
+
+// repositoryHosts are code hosts whose pages are always served over https
+var repositoryHosts = []string{"github.com", "www.github.com", "gitlab.com", "bitbucket.org", "gist.github.com", "gitee.com"}
+
+// canonicalRepositoryURL turns the many ways registries spell a repository, such as
+// git+ssh://git@github.com/user/repo.git, git://github.com/user/repo,
+// git@github.com:user/repo.git or github:user/repo, into its https URL without the .git
+// suffix. Values that are not URLs are returned unchanged.
+func canonicalRepositoryURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	// Shorthands: github:user/repo, or user/repo for GitHub
+	if host, path, ok := strings.Cut(raw, ":"); ok && repositoryShorthands[host] != "" && !strings.HasPrefix(path, "//") {
+		return strings.TrimSuffix(repositoryShorthands[host]+path, ".git")
+	}
+	if user, repo, ok := strings.Cut(raw, "/"); ok && user != "" && repo != "" && !strings.ContainsAny(raw, ":@. ") && !strings.Contains(repo, "/") {
+		return "https://github.com/" + raw
+	}
+
+	// scp-like syntax: git@host:user/repo.git
+	if !strings.Contains(raw, "://") {
+		if at, path, ok := strings.Cut(raw, ":"); ok && strings.Contains(at, "@") && !strings.HasPrefix(path, "/") {
+			_, host, _ := strings.Cut(at, "@")
+			raw = "ssh://" + host + "/" + path
+		}
+	}
+
+	u, err := url.Parse(strings.TrimPrefix(raw, "git+"))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	switch u.Scheme {
+	case "https":
+	case "git", "ssh":
+		// Repository pages are served over https whatever protocol clones them, and
+		// not on the port of the ssh or git daemon
+		u.Scheme = "https"
+		u.Host = u.Hostname()
+	case "http":
+		// Only the code hosts are known to serve https; other sites keep their URL
+		if slices.Contains(repositoryHosts, strings.ToLower(u.Hostname())) {
+			u.Scheme = "https"
+		}
+	default:
+		return raw
+	}
+	u.User = nil
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+	u.RawPath = ""
+	return u.String()
+}
+
+// canonicalizeRepositories rewrites the repository links of info in canonical form
+func canonicalizeRepositories(info *PackageInfo) {
+	info.Repository = canonicalRepositoryURL(info.Repository)
+	info.GitHubURL = canonicalRepositoryURL(info.GitHubURL)
+}
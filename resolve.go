@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ncruces/zenity"
+)
+
+// commonLicenses are always offered when resolving an unknown license
+var commonLicenses = []string{"MIT", "Apache-2.0", "BSD-3-Clause", "BSD-2-Clause", "ISC", "MPL-2.0", "LGPL-3.0", "GPL-3.0", "GPL-2.0", "Unlicense"}
+
+// spdxMentionPattern finds SPDX-like license identifiers mentioned in free text
+var spdxMentionPattern = regexp.MustCompile(`\b(MIT|ISC|Apache-2\.0|BSD-[23]-Clause|MPL-2\.0|[AL]?GPL-[23]\.0(?:-only|-or-later)?|LGPL-2\.1|Unlicense|CC0-1\.0|0BSD|Zlib|BSL-1\.0|EPL-[12]\.0)\b`)
+
+// Choices offered besides the detected candidates
+const (
+	resolveOther = "Other (enter manually)..."
+	resolveSkip  = "Leave unknown"
+)
+
+// githubOwnerRepo extracts "owner/repo" from any form of GitHub repository URL
+func githubOwnerRepo(repoURL string) string {
+	idx := strings.Index(repoURL, "github.com")
+	if idx < 0 {
+		return ""
+	}
+	rest := strings.TrimLeft(repoURL[idx+len("github.com"):], ":/")
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	repo, _, _ := strings.Cut(parts[1], "#")
+	repo = strings.TrimSuffix(repo, ".git")
+	return parts[0] + "/" + repo
+}
+
+// fetchText downloads a small text document, returning "" on any failure
+func fetchText(ctx context.Context, textURL string) string {
+	client := createHTTPClient()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", textURL, nil)
+	if err != nil {
+		return ""
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return ""
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// infoGitHubRepo returns the "owner/repo" of a package hosted on GitHub, or ""
+func infoGitHubRepo(info PackageInfo) string {
+	if repo := githubOwnerRepo(info.GitHubURL); repo != "" {
+		return repo
+	}
+	return githubOwnerRepo(info.Repository)
+}
+
+// fetchRepositoryLicense downloads the LICENSE file of a GitHub repository's default
+// branch, returning "" when there is none
+func fetchRepositoryLicense(ctx context.Context, repo string) string {
+	return fetchRepositoryLicenseAt(ctx, repo, "HEAD", "")
+}
+
+// fetchRepositoryLicenseAt downloads the LICENSE file in a directory of a GitHub
+// repository at a branch, tag or commit, returning "" when there is none
+func fetchRepositoryLicenseAt(ctx context.Context, repo string, ref string, dir string) string {
+	raw := "https://raw.githubusercontent.com/" + repo + "/" + ref + "/"
+	if dir != "" {
+		raw += dir + "/"
+	}
+	for _, name := range repositoryLicenseNames {
+		if text := fetchText(ctx, raw+name); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// fetchPackageLicense downloads the LICENSE file of a package's GitHub, GitLab or
+// Bitbucket repository. Go modules on GitHub are read at the tag or commit of their
+// version and in their directory, since the default branch may have changed its license
+// since; other packages, and modules whose file is not found there, read the default
+// branch.
+func fetchPackageLicense(ctx context.Context, info PackageInfo) string {
+	repo := infoGitHubRepo(info)
+	if repo == "" {
+		if hosted, ok := infoHostedRepo(info); ok {
+			_, text := hosted.fetchLicenseFile(ctx, "HEAD")
+			return text
+		}
+		return ""
+	}
+	if info.RepositoryType == "go" {
+		repoURL, dir := goModuleRepository(info.Name)
+		ref := goModuleRef(info.Name, exactVersion(info))
+		if strings.EqualFold(repoURL, "https://github.com/"+repo) && ref != "" {
+			if text := fetchRepositoryLicenseAt(ctx, repo, ref, dir); text != "" {
+				return text
+			}
+		}
+	}
+	return fetchRepositoryLicense(ctx, repo)
+}
+
+// licenseCandidates gathers likely licenses for a package from its deep scan result,
+// the LICENSE file of its GitHub, GitLab or Bitbucket repository and license mentions
+// in its README
+func licenseCandidates(ctx context.Context, info PackageInfo) []string {
+	seen := make(map[string]bool)
+	var candidates []string
+	add := func(license string) {
+		if license != "" && !seen[license] {
+			seen[license] = true
+			candidates = append(candidates, license)
+		}
+	}
+
+	add(info.DetectedLicense)
+
+	readmeURL := ""
+	if repo := infoGitHubRepo(info); repo != "" {
+		readmeURL = "https://raw.githubusercontent.com/" + repo + "/HEAD/README.md"
+	} else if hosted, ok := infoHostedRepo(info); ok {
+		readmeURL = hosted.rawURL("HEAD", "README.md")
+	}
+	if readmeURL != "" {
+		add(classifyLicenseText(fetchPackageLicense(ctx, info)))
+
+		readme := fetchText(ctx, readmeURL)
+		// Prefer mentions inside the license section of the README
+		if idx := strings.LastIndex(strings.ToLower(readme), "license"); idx >= 0 {
+			for _, match := range spdxMentionPattern.FindAllString(readme[idx:], -1) {
+				add(match)
+			}
+		}
+		for _, match := range spdxMentionPattern.FindAllString(readme, -1) {
+			add(match)
+		}
+	}
+
+	return candidates
+}
+
+// hasUnknownLicense reports whether the license of a package is still to be determined
+func hasUnknownLicense(info PackageInfo) bool {
+	return info.License == "" || strings.EqualFold(info.License, "UNKNOWN") || info.License == spdxNoAssertion
+}
+
+// licenseChoices returns the licenses offered for a package: the candidates found for
+// it, then the common licenses
+func licenseChoices(candidates []string) []string {
+	items := append([]string{}, candidates...)
+	for _, license := range commonLicenses {
+		if !slices.Contains(items, license) {
+			items = append(items, license)
+		}
+	}
+	return items
+}
+
+// resolveUnknownLicenses lists the packages whose license could not be determined and
+// asks for the license of each, in dialogs or, when prompt is set, on the terminal. It
+// returns the answers as overrides, to be applied and saved for later runs.
+func resolveUnknownLicenses(ctx context.Context, infos []PackageInfo, prompt io.Reader, progress func(name string)) []licenseOverride {
+	var unknown []string
+	byName := make(map[string]PackageInfo)
+	for _, info := range infos {
+		name := strings.TrimSpace(info.Name + " " + info.Version)
+		if !hasUnknownLicense(info) || byName[name].Name != "" {
+			continue
+		}
+		unknown = append(unknown, name)
+		byName[name] = info
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	var in *bufio.Reader
+	selected := unknown
+	if prompt != nil {
+		in = bufio.NewReader(prompt)
+		fmt.Fprintf(os.Stderr, "\n%d packages have no known license:\n  %s\n", len(unknown), strings.Join(unknown, "\n  "))
+	} else {
+		var err error
+		selected, err = zenity.ListMultiple(fmt.Sprintf("%d packages have no known license. Select the ones to resolve now:", len(unknown)),
+			unknown, zenity.Title("Resolve unknown licenses"), zenity.DefaultItems(unknown...))
+		if err != nil {
+			return nil
+		}
+	}
+
+	var chosen []licenseOverride
+	for _, name := range selected {
+		if ctx.Err() != nil {
+			break
+		}
+		info := byName[name]
+		if progress != nil {
+			progress(info.Name)
+		}
+		candidates := licenseCandidates(ctx, info)
+		var license string
+		var ok bool
+		if in != nil {
+			license, ok = promptLicense(in, info, candidates)
+		} else {
+			license, ok = askLicense(info, candidates)
+		}
+		if !ok {
+			continue
+		}
+		chosen = append(chosen, licenseOverride{
+			Package:   info.Name,
+			Version:   info.Version,
+			Ecosystem: info.RepositoryType,
+			License:   license,
+			Reason:    "resolved with -resolve",
+		})
+	}
+	return chosen
+}
+
+// askLicense asks in a dialog for the license of a package whose license could not be
+// determined. It returns false if the user left it unknown.
+func askLicense(info PackageInfo, candidates []string) (string, bool) {
+	items := append(licenseChoices(candidates), resolveOther, resolveSkip)
+
+	text := "The license of " + info.Name + " " + info.Version + " could not be determined."
+	if len(candidates) > 0 {
+		text += "\nCandidates found in the repository: " + strings.Join(candidates, ", ")
+	}
+
+	choice, err := zenity.List(text, items, zenity.Title("Resolve unknown license"), zenity.DefaultItems(items[0]))
+	if err != nil || choice == "" || choice == resolveSkip {
+		return "", false
+	}
+
+	if choice == resolveOther {
+		choice, err = zenity.Entry("License of "+info.Name+":", zenity.Title("Resolve unknown license"))
+		if err != nil || strings.TrimSpace(choice) == "" {
+			return "", false
+		}
+	}
+	return strings.TrimSpace(choice), true
+}
+
+// promptLicense asks on the terminal for the license of a package whose license could
+// not be determined: the number of a listed license or any SPDX expression. It returns
+// false if the user left it unknown.
+func promptLicense(in *bufio.Reader, info PackageInfo, candidates []string) (string, bool) {
+	items := licenseChoices(candidates)
+	fmt.Fprintf(os.Stderr, "\nLicense of %s:\n", strings.TrimSpace(info.Name+" "+info.Version))
+	for i, license := range items {
+		note := ""
+		if i < len(candidates) {
+			note = " (found in the repository)"
+		}
+		fmt.Fprintf(os.Stderr, "  %2d) %s%s\n", i+1, license, note)
+	}
+	fmt.Fprint(os.Stderr, "Number or SPDX expression, empty to leave it unknown: ")
+
+	line, err := in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", false
+	}
+	if err != nil && err != io.EOF {
+		return "", false
+	}
+	if n, err := strconv.Atoi(line); err == nil {
+		if n < 1 || n > len(items) {
+			return "", false
+		}
+		return items[n-1], true
+	}
+	return line, true
+}
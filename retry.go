@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// httpAttempts is how often a request is sent before a transient failure is given up
+// on, set by the -retries flag
+var httpAttempts = 3
+
+// Backoff between attempts: retryBaseDelay doubles with every attempt, plus up to as
+// much again of random jitter, and no wait exceeds retryMaxDelay
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// fetchFailure is a request that failed: an error response, or a network error that
+// persisted through all attempts
+type fetchFailure struct {
+	URL      string
+	Status   int // 0 when no response was received
+	Err      string
+	Attempts int
+}
+
+func (f fetchFailure) String() string {
+	reason := f.Err
+	if f.Status != 0 {
+		reason = "HTTP " + strconv.Itoa(f.Status) + " " + f.Err
+	}
+	if f.Attempts > 1 {
+		reason += fmt.Sprintf(" after %d attempts", f.Attempts)
+	}
+	return f.URL + ": " + reason
+}
+
+// fetchFailures collects the failures since the last takeFetchFailures call; packages
+// are fetched one after another, so these belong to the package being fetched
+var fetchFailures []fetchFailure
+
+// takeFetchFailures returns and clears the recorded failures
+func takeFetchFailures() []fetchFailure {
+	failures := fetchFailures
+	fetchFailures = nil
+	return failures
+}
+
+// recordFetchFailure adds a failed request to fetchFailures
+func recordFetchFailure(req *http.Request, resp *http.Response, err error, attempts int) {
+	failure := fetchFailure{URL: req.URL.String(), Attempts: attempts}
+	if err != nil {
+		failure.Err = err.Error()
+	} else {
+		failure.Status = resp.StatusCode
+		failure.Err = http.StatusText(resp.StatusCode)
+	}
+	fetchFailures = append(fetchFailures, failure)
+	slog.Info("request failed", "url", failure.URL, "status", failure.Status, "error", failure.Err, "attempts", attempts)
+}
+
+// logRequest logs one attempt at a request at debug level
+func logRequest(req *http.Request, resp *http.Response, err error, attempt int, elapsed time.Duration) {
+	if !slog.Default().Enabled(req.Context(), slog.LevelDebug) {
+		return
+	}
+	attrs := []any{"method", req.Method, "url", req.URL.String(), "attempt", attempt, "duration", elapsed.Round(time.Millisecond)}
+	if err != nil {
+		slog.Debug("http request", append(attrs, "error", err.Error())...)
+		return
+	}
+	slog.Debug("http request", append(attrs, "status", resp.StatusCode)...)
+}
+
+// retryTransport retries requests that failed with a network error, 429 Too Many
+// Requests or a 5xx status, honoring Retry-After
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+// isTransientFailure reports whether a request is worth sending again
+func isTransientFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// retryDelay returns how long to wait before the next attempt, preferring the
+// server's Retry-After in seconds or as an HTTP date
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if after := resp.Header.Get("Retry-After"); after != "" {
+			if seconds, err := strconv.Atoi(after); err == nil {
+				return min(time.Duration(seconds)*time.Second, retryMaxDelay)
+			}
+			if at, err := http.ParseTime(after); err == nil {
+				return min(max(time.Until(at), 0), retryMaxDelay)
+			}
+		}
+	}
+	backoff := retryBaseDelay << (attempt - 1)
+	return min(backoff+rand.N(backoff), retryMaxDelay)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := t.base.RoundTrip(req)
+		logRequest(req, resp, err, attempt, time.Since(start))
+		if !isTransientFailure(resp, err) {
+			if err != nil || resp.StatusCode >= 400 {
+				recordFetchFailure(req, resp, err, attempt)
+			}
+			return resp, err
+		}
+
+		// A body that cannot be replayed rules out another attempt
+		canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if attempt >= httpAttempts || !canReplay {
+			recordFetchFailure(req, resp, err, attempt)
+			return resp, err
+		}
+
+		delay := retryDelay(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// getJSON fetches a JSON document with a client from createHTTPClient and decodes it
+// into v. found is false without an error when the server answers 404.
+func getJSON(ctx context.Context, client *http.Client, url string, v any) (found bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == 404:
+		return false, nil
+	case resp.StatusCode != 200:
+		return false, fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return true, json.NewDecoder(resp.Body).Decode(v)
+}
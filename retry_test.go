@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/found":
+			w.Write([]byte(`{"version": "1.2.3"}`))
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer takeFetchFailures()
+
+	client := createHTTPClient()
+	var doc struct {
+		Version string `json:"version"`
+	}
+	if found, err := getJSON(context.Background(), client, server.URL+"/found", &doc); err != nil || !found || doc.Version != "1.2.3" {
+		t.Errorf("found document: found=%v err=%v version=%q", found, err, doc.Version)
+	}
+	if found, err := getJSON(context.Background(), client, server.URL+"/missing", &doc); err != nil || found {
+		t.Errorf("/missing: found=%v err=%v, want not found without an error", found, err)
+	}
+	if _, err := getJSON(context.Background(), client, server.URL+"/forbidden", &doc); err == nil {
+		t.Error("/forbidden: want an error")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// getRubyGemsMetadata fetches licenses, authors, description and source URL of a gem
+// from the rubygems.org API
+func getRubyGemsMetadata(ctx context.Context, pkg *Package) PackageInfo {
+	info := PackageInfo{
+		Name:            pkg.Path,
+		Version:         pkg.Version,
+		ModuleNameNoVer: pkg.Path,
+		RepositoryType:  "rubygems",
+		Repository:      pkg.Homepage,
+	}
+
+	// Exact versions have their own endpoint, requirements fall back to the newest release
+	reqURL := "https://rubygems.org/api/v1/gems/" + pkg.Path + ".json"
+	if isPinnedVersion(pkg.Version) {
+		reqURL = "https://rubygems.org/api/v2/rubygems/" + pkg.Path + "/versions/" + pkg.Version + ".json"
+	}
+
+	client := createHTTPClient()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return info
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return info
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == 404 && isPinnedVersion(pkg.Version) {
+		info.VersionStatus = versionMissing
+	}
+	if resp.StatusCode != 200 {
+		return info
+	}
+	info.VersionStatus = versionFound
+
+	var gem struct {
+		Version       string   `json:"version"`
+		Licenses      []string `json:"licenses"`
+		Authors       string   `json:"authors"`
+		Info          string   `json:"info"`
+		HomepageURI   string   `json:"homepage_uri"`
+		SourceCodeURI string   `json:"source_code_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gem); err != nil {
+		return info
+	}
+
+	// Several licenses in a gemspec are alternatives
+	if len(gem.Licenses) > 0 {
+		info.License = strings.Join(gem.Licenses, " OR ")
+		info.LicenseURL = licenseURL(info.License)
+		info.setLicenseSource("gemspec licenses", declaredConfidence(info.License))
+	}
+	info.Author = gem.Authors
+	info.Description = strings.Join(strings.Fields(gem.Info), " ")
+	if gem.SourceCodeURI != "" {
+		info.Repository = gem.SourceCodeURI
+	} else if gem.HomepageURI != "" {
+		info.Repository = gem.HomepageURI
+	}
+	if strings.Contains(info.Repository, "github.com") {
+		info.GitHubURL = info.Repository
+	}
+	if pkg.Version == "" {
+		info.Version = gem.Version
+	}
+	info.Copyright = setCopyrightFromLicense(info.License)
+	return info
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// saveRetries is how often replacing a report is attempted while it is locked,
+// e.g. open in Excel on Windows, and saveRetryDelay the wait between attempts
+const (
+	saveRetries    = 5
+	saveRetryDelay = 2 * time.Second
+)
+
+// timestampedName inserts a timestamp before the extension: report_license.xlsx
+// becomes report_license_<label>20261015-153000.xlsx
+func timestampedName(filename string, label string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "_" + label + time.Now().Format("20060102-150405") + ext
+}
+
+// copyFile copies src to dst, used for backups because a file locked by Excel can
+// still be read but not renamed
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// saveWorkbook writes the workbook to a temporary file next to filename and renames it
+// into place, so an interrupted save never leaves a truncated report. With backup set
+// the previous report is kept under a timestamped name. When filename stays locked, the
+// workbook is saved under a timestamped alternate name instead. It returns the name written.
+func saveWorkbook(f *excelize.File, filename string, backup bool) (string, error) {
+	tmp, err := createTempFor(filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteTo(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return replaceFile(tmp.Name(), filename, backup)
+}
+
+// createTempFor creates the temporary file a report is written to before it replaces
+// filename; it lives in the same directory so the final rename is atomic
+func createTempFor(filename string) (*os.File, error) {
+	return os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+}
+
+// replaceFile moves a completely written temporary file to filename, backing up and
+// retrying as described for saveWorkbook. It returns the name written.
+func replaceFile(tmpName string, filename string, backup bool) (string, error) {
+	if _, err := os.Stat(filename); err == nil && backup {
+		if err := copyFile(filename, timestampedName(filename, "backup_")); err != nil {
+			os.Remove(tmpName)
+			return "", err
+		}
+	}
+
+	var err error
+	for attempt := 1; attempt <= saveRetries; attempt++ {
+		if err = os.Rename(tmpName, filename); err == nil {
+			return filename, nil
+		}
+		if attempt < saveRetries {
+			time.Sleep(saveRetryDelay)
+		}
+	}
+
+	alternate := timestampedName(filename, "")
+	if err := os.Rename(tmpName, alternate); err != nil {
+		os.Remove(tmpName)
+		return "", err
+	}
+	return alternate, nil
+}
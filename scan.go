@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+// getScannedMetadata fetches metadata from the sources of the package's ecosystem
+func getScannedMetadata(ctx context.Context, pkg *Package) PackageInfo {
+	return fetchersFor(pkg.RepositoryType).fetch(ctx, pkg)
+}
+
+// packageKey identifies a package version within its ecosystem, so the same package
+// listed several times is fetched once
+func packageKey(pkg Package, repositoryType string) string {
+	return packageRepositoryType(pkg, repositoryType) + "\x00" + strings.ToLower(pkg.Path) + "\x00" + pkg.Version
+}
+
+// packageRepositoryType returns the ecosystem of a package: its own for packages of a
+// folder scan, otherwise that of the whole report
+func packageRepositoryType(pkg Package, repositoryType string) string {
+	if pkg.RepositoryType != "" {
+		return pkg.RepositoryType
+	}
+	return repositoryType
+}
@@ -0,0 +1,130 @@
+package main
+
+import "slices"
+
+// reportEntry is a package with everything its report row shows
+type reportEntry struct {
+	Info     *PackageInfo
+	Package  Package
+	Verdict  compatibilityVerdict
+	Review   approval
+	Origin   string         // where the metadata came from, e.g. registry or user cache
+	Failures []fetchFailure // requests that failed while fetching the metadata
+}
+
+// reportColumn is a column of the dependency sheet: its header and how a package fills it
+type reportColumn struct {
+	Header string
+	Value  func(e *reportEntry) interface{}
+}
+
+// infoColumn is a column showing one field of the package metadata
+func infoColumn(header string, value func(info *PackageInfo) interface{}) reportColumn {
+	return reportColumn{header, func(e *reportEntry) interface{} { return value(e.Info) }}
+}
+
+// columnHeaders returns the header row of the columns
+func columnHeaders(columns []reportColumn) []string {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Header
+	}
+	return header
+}
+
+// columnValues returns the row of a package, in column order
+func columnValues(columns []reportColumn, e *reportEntry) []interface{} {
+	row := make([]interface{}, len(columns))
+	for i, col := range columns {
+		row[i] = col.Value(e)
+	}
+	return row
+}
+
+// Columns shared by the layouts, named by their header in the canonical layout
+var (
+	nameColumn        = infoColumn("Name", func(info *PackageInfo) interface{} { return info.Name })
+	versionColumn     = infoColumn("Version", func(info *PackageInfo) interface{} { return info.Version })
+	ecosystemColumn   = infoColumn("Ecosystem", func(info *PackageInfo) interface{} { return info.RepositoryType })
+	licenseColumn     = infoColumn("License", func(info *PackageInfo) interface{} { return info.License })
+	licenseURLColumn  = infoColumn("License URL", func(info *PackageInfo) interface{} { return info.LicenseURL })
+	authorColumn      = infoColumn("Author", func(info *PackageInfo) interface{} { return info.Author })
+	descriptionColumn = infoColumn("Description", func(info *PackageInfo) interface{} { return info.Description })
+	copyrightColumn   = infoColumn("Copyright", func(info *PackageInfo) interface{} { return info.Copyright })
+	repositoryColumn  = infoColumn("Repository", func(info *PackageInfo) interface{} { return info.Repository })
+	githubURLColumn   = infoColumn("GitHub URL", func(info *PackageInfo) interface{} { return info.GitHubURL })
+	packageURLColumn  = infoColumn("Package URL", func(info *PackageInfo) interface{} { return info.PackageURL })
+)
+
+// renamed returns a column under another header, for the legacy layouts
+func renamed(col reportColumn, header string) reportColumn {
+	col.Header = header
+	return col
+}
+
+// canonicalColumns is the column layout shared by every ecosystem, so reports of
+// different projects can be merged or compared column by column
+var canonicalColumns = []reportColumn{
+	nameColumn,
+	versionColumn,
+	ecosystemColumn,
+	licenseColumn,
+	licenseURLColumn,
+	authorColumn,
+	descriptionColumn,
+	copyrightColumn,
+	repositoryColumn,
+	githubURLColumn,
+	packageURLColumn,
+}
+
+// legacyLayout returns the per-ecosystem layout of earlier versions, kept for tooling
+// that reads their columns by position
+func legacyLayout(isGoMod bool, isPackageJSON bool) []reportColumn {
+	if isGoMod {
+		return []reportColumn{
+			nameColumn,
+			licenseColumn,
+			renamed(versionColumn, "PackageVersion"),
+			renamed(licenseURLColumn, "LicenseURL"),
+			authorColumn,
+			descriptionColumn,
+			copyrightColumn,
+			renamed(packageURLColumn, "PackageURL"),
+			renamed(githubURLColumn, "GitHubURL"),
+			renamed(ecosystemColumn, "RepositoryType"),
+		}
+	}
+	if isPackageJSON {
+		return []reportColumn{
+			infoColumn("Module Name", func(info *PackageInfo) interface{} { return info.Name + "@" + info.Version }),
+			licenseColumn,
+			repositoryColumn,
+			licenseURLColumn,
+			authorColumn,
+			descriptionColumn,
+			copyrightColumn,
+			githubURLColumn,
+			infoColumn("Module Name (No Version)", func(info *PackageInfo) interface{} { return info.ModuleNameNoVer }),
+			versionColumn,
+		}
+	}
+	return []reportColumn{
+		renamed(nameColumn, "Package Name"),
+		licenseColumn,
+		versionColumn,
+		licenseURLColumn,
+		authorColumn,
+		descriptionColumn,
+		copyrightColumn,
+		repositoryColumn,
+		githubURLColumn,
+		renamed(ecosystemColumn, "Repository Type"),
+	}
+}
+
+// isLegacyHeader reports whether a report was written with a legacy layout, which
+// annotate mode keeps rather than adding the canonical columns next to it
+func isLegacyHeader(header []string) bool {
+	return len(header) > 0 && !slices.Contains(header, "Ecosystem")
+}
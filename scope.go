@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ncruces/zenity"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+)
+
+// isRuntimePackage reports whether a package ships with the project. Packages whose
+// manifest does not tell their scope are taken to.
+func isRuntimePackage(pkg Package) bool {
+	return pkg.Scope == "" || pkg.Scope == parser.ScopeRuntime
+}
+
+// runtimePackages drops the dev, optional and peer dependencies
+func runtimePackages(packages []Package) []Package {
+	var selected []Package
+	for _, pkg := range packages {
+		if isRuntimePackage(pkg) {
+			selected = append(selected, pkg)
+		}
+	}
+	return selected
+}
+
+// askRuntimeOnly asks whether the dependencies that do not ship with the project are
+// left out of the report; count is how many there are
+func askRuntimeOnly(count int) (bool, error) {
+	if headless {
+		return false, nil
+	}
+	err := zenity.Question(fmt.Sprintf("%d dependencies are only used for development or are optional or peer dependencies.\nLeave them out of the report?", count),
+		zenity.Title("Dependency scope"), zenity.OKLabel("Runtime only"), zenity.CancelLabel("Include all"))
+	if errors.Is(err, zenity.ErrCanceled) {
+		return false, nil
+	}
+	return err == nil, err
+}
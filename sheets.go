@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// dependencySheetName is the first sheet of the report listing the dependencies
+const dependencySheetName = "Dependencies"
+
+// maxSheetDataRows is the number of data rows a worksheet can hold below its header
+const maxSheetDataRows = excelize.TotalRows - 1
+
+// ecosystemSheetNames names the sheet of each ecosystem when a polyglot scan is split
+var ecosystemSheetNames = map[string]string{
+	"go":       "Go",
+	"npm":      "npm",
+	"pypi":     "PyPI",
+	"cargo":    "Cargo",
+	"maven":    "Maven",
+	"nuget":    "NuGet",
+	"rubygems": "RubyGems",
+	"composer": "Composer",
+	"upm":      "Unity",
+	"yocto":    "Yocto",
+	"deb":      "Debian",
+	"apk":      "Alpine",
+}
+
+// ecosystemSheetName returns the sheet name for packages of a repository type
+func ecosystemSheetName(repositoryType string) string {
+	if name, ok := ecosystemSheetNames[repositoryType]; ok {
+		return name
+	}
+	if repositoryType == "" {
+		return "Other"
+	}
+	return repositoryType
+}
+
+// continuationSheetName names the n-th (1-based) sheet of a group: Dependencies,
+// Dependencies (2), ...
+func continuationSheetName(base string, n int) string {
+	if n == 1 {
+		return base
+	}
+	return fmt.Sprintf("%s (%d)", base, n)
+}
+
+// sheetGroup is a run of sheets holding the same kind of rows, the first one named
+// base and the rest its continuations
+type sheetGroup struct {
+	base   string
+	sheets int
+	sheet  string
+	row    int // next 1-based row of the current sheet
+}
+
+// sheetWriter writes report rows, starting a continuation sheet with the same header
+// whenever the current one holds maxRows data rows. Split by ecosystem, each ecosystem
+// gets sheets of its own.
+type sheetWriter struct {
+	f           *excelize.File
+	header      []string
+	maxRows     int
+	splitColumn int // column whose value picks the sheet group; -1 for one group
+	groups      map[string]*sheetGroup
+	sheets      []string       // every sheet written, in order
+	lastRow     map[string]int // sheet -> last 1-based row written
+	widths      []int          // widest value per column, in characters
+	linkColumns []bool         // columns whose URLs are written as hyperlinks
+	links       map[string]int // sheet -> hyperlinks written
+	linkStyle   int
+	stream      bool
+	streams     map[string]*excelize.StreamWriter // sheet -> its stream, when streaming
+	styles      map[rowStyleKey]int               // cell styles of streamed rows
+}
+
+// newSheetWriter renames the workbook's first sheet to Dependencies and writes the header.
+// maxRows is capped to what a worksheet can hold; 0 means no cap of our own.
+func newSheetWriter(f *excelize.File, header []string, maxRows int) (*sheetWriter, error) {
+	if maxRows <= 0 || maxRows > maxSheetDataRows {
+		maxRows = maxSheetDataRows
+	}
+
+	w := &sheetWriter{
+		f:           f,
+		header:      header,
+		maxRows:     maxRows,
+		splitColumn: -1,
+		groups:      make(map[string]*sheetGroup),
+		lastRow:     make(map[string]int),
+		widths:      make([]int, len(header)),
+	}
+	w.linkColumns = make([]bool, len(header))
+	w.links = make(map[string]int)
+	for i, col := range header {
+		w.widths[i] = utf8.RuneCountInString(col)
+		w.linkColumns[i] = slices.Contains(linkColumns, col)
+	}
+	linkStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "1265BE", Underline: "single"}})
+	if err != nil {
+		return nil, err
+	}
+	w.linkStyle = linkStyle
+	if err := f.SetSheetName(f.GetSheetName(0), dependencySheetName); err != nil {
+		return nil, err
+	}
+	return w, w.writeHeader(dependencySheetName)
+}
+
+// splitByEcosystem writes the rows of each ecosystem to a sheet named after it, the
+// first one taking the place of the Dependencies sheet
+func (w *sheetWriter) splitByEcosystem() {
+	for _, name := range []string{"Ecosystem", "Repository Type", "RepositoryType"} {
+		if i := slices.Index(w.header, name); i >= 0 {
+			w.splitColumn = i
+			return
+		}
+	}
+}
+
+// writeHeader writes the header on a sheet
+func (w *sheetWriter) writeHeader(sheet string) error {
+	if w.stream {
+		return w.openStream(sheet)
+	}
+	labels := displayHeader(w.header)
+	if err := w.f.SetSheetRow(sheet, "A1", &labels); err != nil {
+		return err
+	}
+	w.sheets = append(w.sheets, sheet)
+	w.lastRow[sheet] = 1
+	return nil
+}
+
+// group returns the sheet group a row belongs to, creating its first sheet
+func (w *sheetWriter) group(values []interface{}) (*sheetGroup, error) {
+	base := dependencySheetName
+	if w.splitColumn >= 0 && w.splitColumn < len(values) {
+		base = ecosystemSheetName(fmt.Sprint(values[w.splitColumn]))
+	}
+	if g, ok := w.groups[base]; ok {
+		return g, nil
+	}
+
+	g := &sheetGroup{base: base, sheets: 1, sheet: base, row: 2}
+	if len(w.groups) == 0 {
+		// The Dependencies sheet already holds the header
+		if err := w.f.SetSheetName(dependencySheetName, base); err != nil {
+			return nil, err
+		}
+		w.sheets[0] = base
+		delete(w.lastRow, dependencySheetName)
+		w.lastRow[base] = 1
+		if w.stream {
+			// The header written in memory is replaced by the streamed one
+			w.sheets = w.sheets[:0]
+			if err := w.openStream(base); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if _, err := w.f.NewSheet(base); err != nil {
+			return nil, err
+		}
+		if err := w.writeHeader(base); err != nil {
+			return nil, err
+		}
+	}
+	w.groups[base] = g
+	return g, nil
+}
+
+// write appends a data row, moving on to a new continuation sheet when the current one is full
+func (w *sheetWriter) write(values []interface{}) error {
+	g, err := w.group(values)
+	if err != nil {
+		return err
+	}
+	if g.row-1 > w.maxRows {
+		g.sheets++
+		g.sheet = continuationSheetName(g.base, g.sheets)
+		if _, err := w.f.NewSheet(g.sheet); err != nil {
+			return err
+		}
+		if err := w.writeHeader(g.sheet); err != nil {
+			return err
+		}
+		g.row = 2
+	}
+	if w.stream {
+		if err := w.streamRow(g, values); err != nil {
+			return err
+		}
+		w.lastRow[g.sheet] = g.row
+		g.row++
+		return nil
+	}
+
+	for j, val := range values {
+		cell, err := excelize.CoordinatesToCellName(j+1, g.row)
+		if err != nil {
+			return err
+		}
+		link := fmt.Sprint(val)
+		isLink := j < len(w.linkColumns) && w.linkColumns[j] && isWebURL(link) && w.links[g.sheet] < maxSheetHyperlinks
+		if isLink {
+			val = linkDisplayText(link)
+		}
+		if err := w.f.SetCellValue(g.sheet, cell, val); err != nil {
+			return err
+		}
+		if isLink {
+			if err := w.f.SetCellHyperLink(g.sheet, cell, link, "External", excelize.HyperlinkOpts{Tooltip: &link}); err != nil {
+				return err
+			}
+			if err := w.f.SetCellStyle(g.sheet, cell, cell, w.linkStyle); err != nil {
+				return err
+			}
+			w.links[g.sheet]++
+		}
+		if j < len(w.widths) {
+			w.widths[j] = max(w.widths[j], utf8.RuneCountInString(fmt.Sprint(val)))
+		}
+	}
+	w.lastRow[g.sheet] = g.row
+	g.row++
+	return nil
+}
+
+// Column widths in characters: columns are sized to their widest value within these
+// bounds, and the wrapped Description column always gets wrapWidth
+const (
+	minColumnWidth = 8
+	maxColumnWidth = 50
+	wrapWidth      = 60
+)
+
+// newHeaderStyle creates the bold, filled style of header rows
+func newHeaderStyle(f *excelize.File) (int, error) {
+	return f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"4472C4"}},
+		Alignment: &excelize.Alignment{Vertical: "center"},
+	})
+}
+
+// finish styles every sheet written: a bold, filled header row that stays in view,
+// an auto-filter over the rows, columns sized to their content, wrapped descriptions
+// and rows highlighted by license risk
+func (w *sheetWriter) finish() error {
+	if w.stream {
+		return w.finishStreams()
+	}
+	headerStyle, err := newHeaderStyle(w.f)
+	if err != nil {
+		return err
+	}
+	wrapStyle, err := w.f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{WrapText: true, Vertical: "top"}})
+	if err != nil {
+		return err
+	}
+	wrapColumn := slices.Index(w.header, "Description")
+	lastColumn, err := excelize.ColumnNumberToName(len(w.header))
+	if err != nil {
+		return err
+	}
+
+	for _, sheet := range w.sheets {
+		for i, width := range w.widths {
+			col, _ := excelize.ColumnNumberToName(i + 1)
+			size := float64(min(max(width, minColumnWidth), maxColumnWidth) + 2)
+			if i == wrapColumn {
+				size = wrapWidth
+				if err := w.f.SetColStyle(sheet, col, wrapStyle); err != nil {
+					return err
+				}
+			}
+			if err := w.f.SetColWidth(sheet, col, col, size); err != nil {
+				return err
+			}
+		}
+		if err := w.f.SetCellStyle(sheet, "A1", lastColumn+"1", headerStyle); err != nil {
+			return err
+		}
+		if err := w.f.AutoFilter(sheet, fmt.Sprintf("A1:%s%d", lastColumn, w.lastRow[sheet]), nil); err != nil {
+			return err
+		}
+		if err := highlightProblemRows(w.f, sheet, w.header, w.lastRow[sheet]); err != nil {
+			return err
+		}
+		if err := w.f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDependencyRows returns the header and data rows of a report, joining the first
+// sheet with every other sheet of the same header: its continuation sheets and, in a
+// report split by ecosystem, the sheets of the other ecosystems. Linked cells read as
+// their full URL.
+func readDependencyRows(f *excelize.File) ([][]string, error) {
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+	rows, err := f.GetRows(sheets[0])
+	if err != nil || len(rows) == 0 {
+		return rows, err
+	}
+	if err := resolveLinks(f, sheets[0], rows); err != nil {
+		return nil, err
+	}
+
+	for _, name := range sheets[1:] {
+		more, err := f.GetRows(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(more) > 1 && slices.Equal(more[0], rows[0]) {
+			if err := resolveLinks(f, name, more); err != nil {
+				return nil, err
+			}
+			rows = append(rows, more[1:]...)
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// spdxNoAssertion marks an SPDX field whose value was not determined
+const spdxNoAssertion = "NOASSERTION"
+
+// spdxIdentifierPattern matches a single license identifier of an SPDX expression
+var spdxIdentifierPattern = regexp.MustCompile(`^[A-Za-z0-9.+-]+$`)
+
+// spdxIDUnsafe matches the characters an SPDX element or LicenseRef id cannot contain
+var spdxIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxDocument is an SPDX 2.3 document; the JSON tags follow the SPDX JSON schema
+type spdxDocument struct {
+	SPDXVersion       string                 `json:"spdxVersion"`
+	DataLicense       string                 `json:"dataLicense"`
+	SPDXID            string                 `json:"SPDXID"`
+	Name              string                 `json:"name"`
+	DocumentNamespace string                 `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo       `json:"creationInfo"`
+	Packages          []spdxPackage          `json:"packages"`
+	Relationships     []spdxRelationship     `json:"relationships"`
+	ExtractedLicenses []spdxExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Homepage         string            `json:"homepage,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Description      string            `json:"description,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+type spdxExtractedLicense struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+// spdxLicense converts a report license to an SPDX license expression. Licenses that
+// are not valid expressions, such as "BSD License" or "Custom/Other", become a
+// LicenseRef, which is returned as ref so its text can be attached to the document.
+func spdxLicense(license string) (expression string, ref string) {
+	license = strings.TrimSpace(license)
+	if license == "" || strings.EqualFold(license, "UNKNOWN") || license == spdxNoAssertion {
+		return spdxNoAssertion, ""
+	}
+
+	if expr, err := parseLicenseExpression(license); err == nil {
+		return expr.String(), ""
+	}
+
+	ref = "LicenseRef-" + strings.Trim(spdxIDUnsafe.ReplaceAllString(license, "-"), "-")
+	return ref, ref
+}
+
+// spdxDownloadLocation returns where the package can be downloaded from: the registry
+// archive for exact npm and Go versions, otherwise the git repository if known
+func spdxDownloadLocation(info PackageInfo) string {
+	name := info.ModuleNameNoVer
+	if name == "" {
+		name = info.Name
+	}
+
+	switch info.RepositoryType {
+	case "go":
+		if info.Version != "" {
+			if location := goModuleZipURL(name, info.Version); location != "" {
+				return location
+			}
+		}
+	case "npm":
+		if version := exactVersion(info); isPinnedVersion(version) {
+			return npmTarballURL(name, strings.TrimPrefix(version, "="))
+		}
+	}
+
+	if repo := infoGitHubRepo(info); repo != "" {
+		return "git+https://github.com/" + repo + ".git"
+	}
+	return spdxNoAssertion
+}
+
+// spdxPackageURL returns the purl of the package, or "" for ecosystems without one
+func spdxPackageURL(info PackageInfo) string {
+	name := info.ModuleNameNoVer
+	if name == "" {
+		name = info.Name
+	}
+	version := ""
+	if exact := exactVersion(info); isPinnedVersion(exact) {
+		version = "@" + url.PathEscape(strings.TrimLeft(exact, "="))
+	}
+
+	switch info.RepositoryType {
+	case "go":
+		return "pkg:golang/" + name + version
+	case "npm":
+		// The scope of a scoped package is a percent-encoded namespace
+		return "pkg:npm/" + strings.Replace(name, "@", "%40", 1) + version
+	case "pypi":
+		return "pkg:pypi/" + strings.ToLower(strings.ReplaceAll(name, "_", "-")) + version
+	default:
+		return ""
+	}
+}
+
+// spdxText returns text, or NOASSERTION when it is empty
+func spdxText(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return spdxNoAssertion
+	}
+	return text
+}
+
+// spdxNamespace returns a unique document namespace URI
+func spdxNamespace(name string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	uuid := fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	return "https://spdx.org/spdxdocs/" + spdxIDUnsafe.ReplaceAllString(name, "-") + "-" + uuid
+}
+
+// buildSPDXDocument describes the project as a root package depending on every
+// dependency of the report
+func buildSPDXDocument(name string, infos []PackageInfo) spdxDocument {
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: spdxNamespace(name),
+		CreationInfo: spdxCreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: license_fetcher"},
+		},
+	}
+
+	rootID := "SPDXRef-Package-" + strings.Trim(spdxIDUnsafe.ReplaceAllString(name, "-"), "-")
+	doc.Packages = append(doc.Packages, spdxPackage{
+		Name:             name,
+		SPDXID:           rootID,
+		DownloadLocation: spdxNoAssertion,
+		LicenseConcluded: spdxNoAssertion,
+		LicenseDeclared:  spdxNoAssertion,
+		CopyrightText:    spdxNoAssertion,
+	})
+	doc.Relationships = append(doc.Relationships, spdxRelationship{doc.SPDXID, "DESCRIBES", rootID})
+
+	refs := make(map[string]bool)
+	addRef := func(ref string, license string, info PackageInfo) {
+		if ref == "" || refs[ref] {
+			return
+		}
+		refs[ref] = true
+		text := info.LicenseText
+		if text == "" {
+			text = "The license of " + info.Name + " is declared as \"" + license + "\"; its text was not retrieved."
+		}
+		doc.ExtractedLicenses = append(doc.ExtractedLicenses, spdxExtractedLicense{LicenseID: ref, ExtractedText: text, Name: license})
+	}
+
+	for i, info := range infos {
+		declared, ref := spdxLicense(info.License)
+		addRef(ref, info.License, info)
+
+		// Only a license read from the package's own files counts as concluded
+		concluded := spdxNoAssertion
+		if info.DetectedLicense != "" {
+			concluded, ref = spdxLicense(info.DetectedLicense)
+			addRef(ref, info.DetectedLicense, info)
+		}
+
+		copyright := spdxNoAssertion
+		if !isCopyrightPlaceholder(info) {
+			copyright = info.Copyright
+		}
+
+		homepage := info.Repository
+		if !strings.HasPrefix(homepage, "http") {
+			homepage = info.GitHubURL
+		}
+		if !strings.HasPrefix(homepage, "http") {
+			homepage = ""
+		}
+
+		pkg := spdxPackage{
+			Name:             info.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d-%s", i+1, strings.Trim(spdxIDUnsafe.ReplaceAllString(info.Name, "-"), "-")),
+			VersionInfo:      info.Version,
+			DownloadLocation: spdxDownloadLocation(info),
+			Homepage:         homepage,
+			LicenseConcluded: concluded,
+			LicenseDeclared:  declared,
+			CopyrightText:    copyright,
+			Description:      info.Description,
+		}
+		if purl := spdxPackageURL(info); purl != "" {
+			pkg.ExternalRefs = []spdxExternalRef{{"PACKAGE-MANAGER", "purl", purl}}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{rootID, "DEPENDS_ON", pkg.SPDXID})
+	}
+
+	return doc
+}
+
+// spdxTagText wraps multi-line values in the <text> delimiters of the tag-value format
+func spdxTagText(text string) string {
+	if strings.Contains(text, "\n") && !strings.HasPrefix(text, "<text>") {
+		return "<text>" + text + "</text>"
+	}
+	return text
+}
+
+// writeSPDXTagValue writes the document in the SPDX tag-value format
+func writeSPDXTagValue(filename string, doc spdxDocument) error {
+	var b strings.Builder
+	tag := func(name string, value string) {
+		b.WriteString(name + ": " + spdxTagText(value) + "\n")
+	}
+
+	tag("SPDXVersion", doc.SPDXVersion)
+	tag("DataLicense", doc.DataLicense)
+	tag("SPDXID", doc.SPDXID)
+	tag("DocumentName", doc.Name)
+	tag("DocumentNamespace", doc.DocumentNamespace)
+	for _, creator := range doc.CreationInfo.Creators {
+		tag("Creator", creator)
+	}
+	tag("Created", doc.CreationInfo.Created)
+
+	for _, pkg := range doc.Packages {
+		b.WriteString("\n")
+		tag("PackageName", pkg.Name)
+		tag("SPDXID", pkg.SPDXID)
+		if pkg.VersionInfo != "" {
+			tag("PackageVersion", pkg.VersionInfo)
+		}
+		tag("PackageDownloadLocation", pkg.DownloadLocation)
+		tag("FilesAnalyzed", fmt.Sprint(pkg.FilesAnalyzed))
+		if pkg.Homepage != "" {
+			tag("PackageHomePage", pkg.Homepage)
+		}
+		tag("PackageLicenseConcluded", pkg.LicenseConcluded)
+		tag("PackageLicenseDeclared", pkg.LicenseDeclared)
+		tag("PackageCopyrightText", spdxText(pkg.CopyrightText))
+		if pkg.Description != "" {
+			tag("PackageDescription", "<text>"+pkg.Description+"</text>")
+		}
+		for _, ref := range pkg.ExternalRefs {
+			tag("ExternalRef", ref.Category+" "+ref.Type+" "+ref.Locator)
+		}
+	}
+
+	b.WriteString("\n")
+	for _, rel := range doc.Relationships {
+		tag("Relationship", rel.Element+" "+rel.Type+" "+rel.Related)
+	}
+
+	for _, license := range doc.ExtractedLicenses {
+		b.WriteString("\n")
+		tag("LicenseID", license.LicenseID)
+		tag("ExtractedText", "<text>"+license.ExtractedText+"</text>")
+		tag("LicenseName", license.Name)
+	}
+
+	return os.WriteFile(filename, []byte(b.String()), 0644)
+}
+
+// writeSPDXJSON writes the document in the SPDX JSON format
+func writeSPDXJSON(filename string, doc spdxDocument) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// licenseExpression is a parsed SPDX license expression: either a single license,
+// optionally with an exception, or an AND / OR of operands
+type licenseExpression struct {
+	Op        string // "AND" or "OR"; empty for a single license
+	License   string
+	Exception string // set with WITH, e.g. Classpath-exception-2.0
+	Operands  []*licenseExpression
+}
+
+// licenseExpressionParser is a recursive descent parser over expression tokens. OR
+// binds loosest, then AND, then WITH, as the SPDX specification defines.
+type licenseExpressionParser struct {
+	tokens []string
+	pos    int
+}
+
+// tokenizeLicenseExpression splits an expression into identifiers, operators and parentheses
+func tokenizeLicenseExpression(expression string) []string {
+	return strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression))
+}
+
+// parseLicenseExpression parses an SPDX license expression such as
+// "MIT OR Apache-2.0" or "(GPL-2.0 WITH Classpath-exception-2.0) AND BSD-3-Clause".
+// Operators are accepted in any case.
+func parseLicenseExpression(expression string) (*licenseExpression, error) {
+	p := &licenseExpressionParser{tokens: tokenizeLicenseExpression(expression)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in license expression %q", p.tokens[p.pos], expression)
+	}
+	return expr, nil
+}
+
+// peekOperator reports whether the next token is the given operator
+func (p *licenseExpressionParser) peekOperator(op string) bool {
+	return p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], op)
+}
+
+// parseOr parses operands joined by OR
+func (p *licenseExpressionParser) parseOr() (*licenseExpression, error) {
+	return p.parseBinary("OR", p.parseAnd)
+}
+
+// parseAnd parses operands joined by AND
+func (p *licenseExpressionParser) parseAnd() (*licenseExpression, error) {
+	return p.parseBinary("AND", p.parseWith)
+}
+
+// parseBinary parses operands joined by op, flattening chains like A OR B OR C
+func (p *licenseExpressionParser) parseBinary(op string, operand func() (*licenseExpression, error)) (*licenseExpression, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	expr := first
+	for p.peekOperator(op) {
+		p.pos++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if expr == first {
+			expr = &licenseExpression{Op: op, Operands: []*licenseExpression{first}}
+		}
+		if next.Op == op {
+			// Parentheses around the same operator do not change the meaning
+			expr.Operands = append(expr.Operands, next.Operands...)
+		} else {
+			expr.Operands = append(expr.Operands, next)
+		}
+	}
+	return expr, nil
+}
+
+// parseWith parses a license, a license WITH an exception or a parenthesized expression
+func (p *licenseExpressionParser) parseWith() (*licenseExpression, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("license expression ends after an operator")
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+	if token == "(" {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, fmt.Errorf("missing ) in license expression")
+		}
+		p.pos++
+		return expr, nil
+	}
+	if !isLicenseIdentifier(token) {
+		return nil, fmt.Errorf("unexpected %q in license expression", token)
+	}
+
+	expr := &licenseExpression{License: token}
+	if p.peekOperator("WITH") {
+		p.pos++
+		if p.pos >= len(p.tokens) || !isLicenseIdentifier(p.tokens[p.pos]) {
+			return nil, fmt.Errorf("WITH needs an exception identifier")
+		}
+		expr.Exception = p.tokens[p.pos]
+		p.pos++
+	}
+	return expr, nil
+}
+
+// isLicenseIdentifier reports whether token can be a license or exception identifier
+// rather than an operator or parenthesis
+func isLicenseIdentifier(token string) bool {
+	switch strings.ToUpper(token) {
+	case "AND", "OR", "WITH":
+		return false
+	}
+	return spdxIdentifierPattern.MatchString(token)
+}
+
+// String renders the expression in canonical form, with upper case operators and
+// parentheses only where precedence requires them
+func (e *licenseExpression) String() string {
+	if e.Op == "" {
+		if e.Exception != "" {
+			return e.License + " WITH " + e.Exception
+		}
+		return e.License
+	}
+
+	parts := make([]string, len(e.Operands))
+	for i, operand := range e.Operands {
+		parts[i] = operand.String()
+		// An OR inside an AND must keep its parentheses
+		if e.Op == "AND" && operand.Op == "OR" {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " "+e.Op+" ")
+}
+
+// components returns the single licenses of the expression, each with its exception,
+// in the order they appear
+func (e *licenseExpression) components() []string {
+	if e.Op == "" {
+		return []string{e.String()}
+	}
+	var components []string
+	for _, operand := range e.Operands {
+		for _, component := range operand.components() {
+			if !slices.Contains(components, component) {
+				components = append(components, component)
+			}
+		}
+	}
+	return components
+}
+
+// mapLicenses returns a copy of the expression with every license identifier replaced
+// by fn; exceptions are kept
+func (e *licenseExpression) mapLicenses(fn func(string) string) *licenseExpression {
+	mapped := &licenseExpression{Op: e.Op, License: e.License, Exception: e.Exception}
+	if e.Op == "" {
+		mapped.License = fn(e.License)
+	}
+	for _, operand := range e.Operands {
+		mapped.Operands = append(mapped.Operands, operand.mapLicenses(fn))
+	}
+	return mapped
+}
+
+// licenseComponents lists the licenses of a compound SPDX expression, or nil when the
+// license is a single identifier or not an expression at all
+func licenseComponents(license string) []string {
+	expr, err := parseLicenseExpression(license)
+	if err != nil || expr.Op == "" {
+		return nil
+	}
+	return expr.components()
+}
+
+// gnuLicensePattern matches the GNU license identifiers whose deprecated forms, GPL-2.0
+// and GPL-2.0+, mean the same as GPL-2.0-only and GPL-2.0-or-later
+var gnuLicensePattern = regexp.MustCompile(`(?i)^((?:A|L)?GPL|GFDL)-(\d\.\d)(\+|-only|-or-later)?$`)
+
+// canonicalLicenseID returns the current SPDX form of a license identifier, in upper case
+// since identifiers are matched case-insensitively
+func canonicalLicenseID(id string) string {
+	m := gnuLicensePattern.FindStringSubmatch(id)
+	if m == nil {
+		return strings.ToUpper(id)
+	}
+	if m[3] == "+" || strings.EqualFold(m[3], "-or-later") {
+		return strings.ToUpper(m[1] + "-" + m[2] + "-or-later")
+	}
+	return strings.ToUpper(m[1] + "-" + m[2] + "-only")
+}
+
+// canonical returns a copy of the expression with canonical identifiers and the
+// operands of every AND and OR sorted, so equivalent expressions render the same
+func (e *licenseExpression) canonical() *licenseExpression {
+	canonical := e.mapLicenses(canonicalLicenseID)
+	canonical.Exception = strings.ToUpper(canonical.Exception)
+	for i, operand := range canonical.Operands {
+		canonical.Operands[i] = operand.canonical()
+	}
+	slices.SortFunc(canonical.Operands, func(a, b *licenseExpression) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return canonical
+}
+
+// sameLicenseExpression reports whether two license expressions mean the same, e.g.
+// "GPL-3.0+" and "GPL-3.0-or-later", or "MIT OR Apache-2.0" and "Apache-2.0 OR MIT".
+// Text that is not an expression is compared as it is, ignoring case.
+func sameLicenseExpression(a string, b string) bool {
+	exprA, errA := parseLicenseExpression(a)
+	exprB, errB := parseLicenseExpression(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+	return exprA.canonical().String() == exprB.canonical().String()
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestSameLicenseExpression(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"MIT", "mit", true},
+		{"GPL-2.0", "GPL-2.0-only", true},
+		{"GPL-3.0+", "GPL-3.0-or-later", true},
+		{"LGPL-2.1-or-later", "lgpl-2.1+", true},
+		{"MIT OR Apache-2.0", "Apache-2.0 OR MIT", true},
+		{"(MIT AND BSD-3-Clause) OR GPL-2.0", "GPL-2.0-only OR (BSD-3-Clause AND MIT)", true},
+		{"GPL-2.0 WITH Classpath-exception-2.0", "GPL-2.0-only WITH Classpath-exception-2.0", true},
+		{"GPL-2.0", "GPL-2.0-or-later", false},
+		{"MIT OR Apache-2.0", "MIT AND Apache-2.0", false},
+		{"GPL-2.0", "GPL-2.0 WITH Classpath-exception-2.0", false},
+		{"Apache-2.0", "MIT", false},
+	}
+	for _, tt := range tests {
+		if got := sameLicenseExpression(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameLicenseExpression(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// streamThreshold is the number of packages above which dependency sheets are
+// streamed to disk instead of built in memory
+const streamThreshold = 5000
+
+// maxHyperlinkURL is the longest URL the HYPERLINK function accepts
+const maxHyperlinkURL = 255
+
+// streamColumnWidths sizes the columns of streamed sheets, whose widths must be set
+// before their rows are known; other columns get defaultStreamWidth
+var streamColumnWidths = map[string]float64{
+	"Name":         30,
+	"Package Name": 30,
+	"Module Name":  30,
+	"License":      20,
+	"Description":  wrapWidth,
+	"Author":       25,
+	"Copyright":    30,
+	"Project":      30,
+}
+
+// defaultStreamWidth is the width of streamed columns not in streamColumnWidths
+const defaultStreamWidth = 18
+
+// rowStyleKey identifies the cell style of a streamed cell
+type rowStyleKey struct {
+	highlight string
+	wrap      bool
+	link      bool
+}
+
+// streamRows makes the writer stream its sheets: rows are written to disk as they come,
+// keeping memory flat for very large dependency sets. Rows are highlighted when written
+// and links use the HYPERLINK function, as streamed sheets cannot be changed afterwards.
+func (w *sheetWriter) streamRows() error {
+	headerStyle, err := newHeaderStyle(w.f)
+	if err != nil {
+		return err
+	}
+	w.stream = true
+	w.streams = make(map[string]*excelize.StreamWriter)
+	w.styles = map[rowStyleKey]int{{highlight: "header"}: headerStyle}
+	return nil
+}
+
+// rowStyle returns the style of a streamed cell, creating it on first use
+func (w *sheetWriter) rowStyle(key rowStyleKey) (int, error) {
+	if style, ok := w.styles[key]; ok {
+		return style, nil
+	}
+	style := &excelize.Style{Font: &excelize.Font{}}
+	switch key.highlight {
+	case highlightProblem:
+		style.Fill, style.Font.Color = problemFill, problemFont.Color
+	case highlightAttention:
+		style.Fill, style.Font.Color = attentionFill, attentionFont.Color
+	}
+	if key.link {
+		style.Font.Underline = "single"
+		if key.highlight == "" {
+			style.Font.Color = "1265BE"
+		}
+	}
+	if key.wrap {
+		style.Alignment = &excelize.Alignment{WrapText: true, Vertical: "top"}
+	}
+	id, err := w.f.NewStyle(style)
+	if err != nil {
+		return 0, err
+	}
+	w.styles[key] = id
+	return id, nil
+}
+
+// openStream starts streaming a sheet: sized columns, a frozen header row and the header
+func (w *sheetWriter) openStream(sheet string) error {
+	sw, err := w.f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	for i, name := range w.header {
+		width, ok := streamColumnWidths[name]
+		if !ok {
+			width = defaultStreamWidth
+		}
+		if err := sw.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	cells := make([]interface{}, len(w.header))
+	for i, name := range w.header {
+		cells[i] = excelize.Cell{StyleID: w.styles[rowStyleKey{highlight: "header"}], Value: headerLabel(name)}
+	}
+	if err := sw.SetRow("A1", cells); err != nil {
+		return err
+	}
+	w.streams[sheet] = sw
+	w.sheets = append(w.sheets, sheet)
+	w.lastRow[sheet] = 1
+	return nil
+}
+
+// hyperlinkFormula builds a HYPERLINK formula opening link and showing display
+func hyperlinkFormula(link string, display string) string {
+	quote := func(s string) string { return `"` + strings.ReplaceAll(s, `"`, `""`) + `"` }
+	return "HYPERLINK(" + quote(link) + "," + quote(display) + ")"
+}
+
+// streamRow writes a data row to a streamed sheet
+func (w *sheetWriter) streamRow(g *sheetGroup, values []interface{}) error {
+	license, verdict := "", ""
+	if i := slices.Index(w.header, "License"); i >= 0 && i < len(values) {
+		license = fmt.Sprint(values[i])
+	}
+	if i := slices.Index(w.header, "Compatibility"); i >= 0 && i < len(values) {
+		verdict = fmt.Sprint(values[i])
+	}
+	highlight := licenseHighlight(license, verdict)
+	wrapColumn := slices.Index(w.header, "Description")
+
+	cells := make([]interface{}, len(values))
+	for j, val := range values {
+		link := fmt.Sprint(val)
+		key := rowStyleKey{
+			highlight: highlight,
+			wrap:      j == wrapColumn,
+			link:      j < len(w.linkColumns) && w.linkColumns[j] && isWebURL(link) && len(link) <= maxHyperlinkURL,
+		}
+		style, err := w.rowStyle(key)
+		if err != nil {
+			return err
+		}
+		if key.link {
+			display := linkDisplayText(link)
+			cells[j] = excelize.Cell{StyleID: style, Formula: hyperlinkFormula(link, display), Value: display}
+		} else {
+			cells[j] = excelize.Cell{StyleID: style, Value: val}
+		}
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, g.row)
+	if err != nil {
+		return err
+	}
+	return w.streams[g.sheet].SetRow(cell, cells)
+}
+
+// finishStreams adds a filter table over the rows of every streamed sheet and writes
+// the sheets out
+func (w *sheetWriter) finishStreams() error {
+	lastColumn, err := excelize.ColumnNumberToName(len(w.header))
+	if err != nil {
+		return err
+	}
+	for i, sheet := range w.sheets {
+		sw, ok := w.streams[sheet]
+		if !ok {
+			continue
+		}
+		if w.lastRow[sheet] > 1 {
+			if err := sw.AddTable(&excelize.Table{
+				Range: fmt.Sprintf("A1:%s%d", lastColumn, w.lastRow[sheet]),
+				Name:  fmt.Sprintf("Dependencies%d", i+1),
+			}); err != nil {
+				return err
+			}
+		}
+		if err := sw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
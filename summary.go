@@ -0,0 +1,382 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// summarySheetName is the sheet holding the project-level overview
+const summarySheetName = "Summary"
+
+// License risk categories, from least to most restrictive
+const (
+	riskPermissive     = "permissive"
+	riskWeakCopyleft   = "weak copyleft"
+	riskStrongCopyleft = "strong copyleft"
+	riskUnknown        = "unknown"
+)
+
+// riskOrder lists the categories in the order they are reported
+var riskOrder = []string{riskPermissive, riskWeakCopyleft, riskStrongCopyleft, riskUnknown}
+
+// riskWeights is how much a package of each category counts towards the compliance score,
+// so that one unreviewed GPL dependency weighs more than one unreviewed MIT dependency
+var riskWeights = map[string]int{
+	riskPermissive:     1,
+	riskWeakCopyleft:   2,
+	riskStrongCopyleft: 3,
+	riskUnknown:        3,
+}
+
+// licensePrefixRisks maps SPDX identifier prefixes to their category; the first match wins
+// so LGPL and AGPL must come before GPL
+var licensePrefixRisks = []struct {
+	prefix string
+	risk   string
+}{
+	{"LGPL", riskWeakCopyleft},
+	{"MPL", riskWeakCopyleft},
+	{"EPL", riskWeakCopyleft},
+	{"CDDL", riskWeakCopyleft},
+	{"CPL", riskWeakCopyleft},
+	{"MS-RL", riskWeakCopyleft},
+	{"AGPL", riskStrongCopyleft},
+	{"GPL", riskStrongCopyleft},
+	{"SSPL", riskStrongCopyleft},
+	{"OSL", riskStrongCopyleft},
+	{"EUPL", riskStrongCopyleft},
+	{"CC-BY-SA", riskStrongCopyleft},
+	{"CC-BY-NC", riskStrongCopyleft},
+	{"MIT", riskPermissive},
+	{"BSD", riskPermissive},
+	{"0BSD", riskPermissive},
+	{"Apache", riskPermissive},
+	{"ISC", riskPermissive},
+	{"Zlib", riskPermissive},
+	{"Unlicense", riskPermissive},
+	{"CC0", riskPermissive},
+	{"CC-BY", riskPermissive},
+	{"BSL-1.0", riskPermissive},
+	{"PSF", riskPermissive},
+	{"Python", riskPermissive},
+	{"WTFPL", riskPermissive},
+	{"Artistic", riskPermissive},
+	{"PostgreSQL", riskPermissive},
+	{"MS-PL", riskPermissive},
+	{"Unity Companion License", riskWeakCopyleft},
+}
+
+// singleLicenseRisk categorizes one license identifier
+func singleLicenseRisk(license string) string {
+	license = strings.Trim(strings.TrimSpace(license), "()")
+	for _, entry := range licensePrefixRisks {
+		if strings.HasPrefix(strings.ToUpper(license), strings.ToUpper(entry.prefix)) {
+			return entry.risk
+		}
+	}
+	return riskUnknown
+}
+
+// licenseRisk categorizes a license expression: with OR the licensee picks the least
+// restrictive option, with AND every license applies so the most restrictive one counts
+func licenseRisk(license string) string {
+	if strings.TrimSpace(license) == "" {
+		return riskUnknown
+	}
+
+	best := len(riskOrder)
+	for _, choice := range strings.Split(license, " OR ") {
+		worst := 0
+		for _, part := range strings.Split(choice, " AND ") {
+			worst = max(worst, riskRank(singleLicenseRisk(part)))
+		}
+		best = min(best, worst)
+	}
+	return riskOrder[best]
+}
+
+// riskRank returns the position of a category in riskOrder
+func riskRank(risk string) int {
+	for i, r := range riskOrder {
+		if r == risk {
+			return i
+		}
+	}
+	return len(riskOrder) - 1
+}
+
+// isCompliant tells whether a package meets policy: explicitly approved packages always
+// do, otherwise only permissive licenses that were not rejected are acceptable
+func isCompliant(info PackageInfo, status string) bool {
+	if status == approvalApproved {
+		return true
+	}
+	return status != approvalRejected && licenseRisk(info.License) == riskPermissive
+}
+
+// complianceScore is the risk-weighted percentage of compliant packages. statuses holds
+// the approval status of each entry in infos.
+func complianceScore(infos []PackageInfo, statuses []string) float64 {
+	total, compliant := 0, 0
+	for i, info := range infos {
+		weight := riskWeights[licenseRisk(info.License)]
+		total += weight
+		if isCompliant(info, statuses[i]) {
+			compliant += weight
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+	return float64(compliant) * 100 / float64(total)
+}
+
+// maxPieSlices is the number of distinct licenses above which a bar chart is used,
+// as a pie with many thin slices is unreadable
+const maxPieSlices = 8
+
+// licenseDistribution counts packages per license, most common first
+func licenseDistribution(infos []PackageInfo) ([]string, map[string]int) {
+	counts := make(map[string]int)
+	for _, info := range infos {
+		license := info.License
+		if license == "" {
+			license = "Unknown"
+		}
+		counts[license]++
+	}
+
+	licenses := make([]string, 0, len(counts))
+	for license := range counts {
+		licenses = append(licenses, license)
+	}
+	sort.Slice(licenses, func(i, j int) bool {
+		if counts[licenses[i]] != counts[licenses[j]] {
+			return counts[licenses[i]] > counts[licenses[j]]
+		}
+		return licenses[i] < licenses[j]
+	})
+	return licenses, counts
+}
+
+// maxRiskyLicenses is how many licenses the summary lists as the riskiest
+const maxRiskyLicenses = 10
+
+// riskyLicense is a license of the Top Risky Licenses table
+type riskyLicense struct {
+	License  string
+	Risk     string
+	Packages int
+}
+
+// riskyLicenses lists the licenses that are not permissive, most restrictive and then
+// most common first
+func riskyLicenses(infos []PackageInfo) []riskyLicense {
+	licenses, counts := licenseDistribution(infos)
+	var risky []riskyLicense
+	for _, license := range licenses {
+		risk := riskUnknown
+		if license != "Unknown" {
+			risk = licenseRisk(license)
+		}
+		if risk != riskPermissive {
+			risky = append(risky, riskyLicense{license, risk, counts[license]})
+		}
+	}
+	// licenseDistribution already sorts by count, a stable sort keeps that per risk
+	sort.SliceStable(risky, func(i, j int) bool {
+		return riskRank(risky[i].Risk) > riskRank(risky[j].Risk)
+	})
+	if len(risky) > maxRiskyLicenses {
+		risky = risky[:maxRiskyLicenses]
+	}
+	return risky
+}
+
+// ecosystemTotals counts packages per repository type, most common first
+func ecosystemTotals(infos []PackageInfo) ([]string, map[string]int) {
+	counts := make(map[string]int)
+	for _, info := range infos {
+		counts[info.RepositoryType]++
+	}
+	ecosystems := make([]string, 0, len(counts))
+	for ecosystem := range counts {
+		ecosystems = append(ecosystems, ecosystem)
+	}
+	sort.Slice(ecosystems, func(i, j int) bool {
+		if counts[ecosystems[i]] != counts[ecosystems[j]] {
+			return counts[ecosystems[i]] > counts[ecosystems[j]]
+		}
+		return ecosystems[i] < ecosystems[j]
+	})
+	return ecosystems, counts
+}
+
+// addLicenseChart charts the license table spanning rows first to last of the summary sheet
+func addLicenseChart(f *excelize.File, first int, last int) error {
+	chartType := excelize.Pie
+	if last-first+1 > maxPieSlices {
+		chartType = excelize.Bar
+	}
+
+	return f.AddChart(summarySheetName, "G1", &excelize.Chart{
+		Type: chartType,
+		Series: []excelize.ChartSeries{{
+			Name:       summarySheetName + "!$B$" + fmt.Sprint(first-1),
+			Categories: fmt.Sprintf("%s!$A$%d:$A$%d", summarySheetName, first, last),
+			Values:     fmt.Sprintf("%s!$B$%d:$B$%d", summarySheetName, first, last),
+		}},
+		Title:     []excelize.RichTextRun{{Text: "License Distribution"}},
+		Legend:    excelize.ChartLegend{Position: "right"},
+		Dimension: excelize.ChartDimension{Width: 640, Height: 400},
+		PlotArea: excelize.ChartPlotArea{
+			ShowVal:     chartType == excelize.Bar,
+			ShowPercent: chartType == excelize.Pie,
+		},
+	})
+}
+
+// holderNoisePattern matches the parts of a copyright line that do not identify the
+// holder: years, e-mail addresses, links and boilerplate
+var holderNoisePattern = regexp.MustCompile(`(?i)^[\d\s,\-–]+|<[^>]*>|\([^)]*\)|https?://\S+|all rights reserved\.?|,?\s*(and|&)\s+(its\s+)?(contributors|authors)\.?$`)
+
+// packageOrganization returns the copyright holder or organization behind a package,
+// falling back to the GitHub owner when neither copyright nor author is known
+func packageOrganization(info PackageInfo) string {
+	holder := copyrightHolder(info)
+	if holder == "unknown" {
+		if repo := githubOwnerRepo(info.GitHubURL); repo != "" {
+			holder, _, _ = strings.Cut(repo, "/")
+		}
+	}
+
+	holder = holderNoisePattern.ReplaceAllString(holder, "")
+	holder = strings.Trim(strings.Join(strings.Fields(holder), " "), " .,;")
+	if holder == "" {
+		return "unknown"
+	}
+	return holder
+}
+
+// organizationGroup aggregates the packages of one copyright holder
+type organizationGroup struct {
+	Name     string
+	Packages int
+	Licenses map[string]bool
+}
+
+// groupByOrganization aggregates packages by holder, case-insensitively, largest first
+func groupByOrganization(infos []PackageInfo) []*organizationGroup {
+	groups := make(map[string]*organizationGroup)
+	var ordered []*organizationGroup
+	for _, info := range infos {
+		name := packageOrganization(info)
+		key := strings.ToLower(name)
+		group, ok := groups[key]
+		if !ok {
+			group = &organizationGroup{Name: name, Licenses: make(map[string]bool)}
+			groups[key] = group
+			ordered = append(ordered, group)
+		}
+		group.Packages++
+		if info.License != "" {
+			group.Licenses[info.License] = true
+		}
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Packages > ordered[j].Packages
+	})
+	return ordered
+}
+
+// writeSummarySheet (re)creates the summary sheet with the compliance score, a
+// breakdown by license risk, the riskiest licenses, the totals per ecosystem, the
+// license distribution chart and the packages per copyright holder. With a project license, verdicts holds the compatibility of each
+// entry in infos and is broken down as well.
+func writeSummarySheet(f *excelize.File, infos []PackageInfo, statuses []string, projectLicense string, verdicts []compatibilityVerdict) error {
+	// A summary left over from a previous run in annotate mode is rebuilt from scratch
+	if idx, _ := f.GetSheetIndex(summarySheetName); idx >= 0 {
+		if err := f.DeleteSheet(summarySheetName); err != nil {
+			return err
+		}
+	}
+	if _, err := f.NewSheet(summarySheetName); err != nil {
+		return err
+	}
+
+	packages := make(map[string]int)
+	compliant := make(map[string]int)
+	for i, info := range infos {
+		risk := licenseRisk(info.License)
+		packages[risk]++
+		if isCompliant(info, statuses[i]) {
+			compliant[risk]++
+		}
+	}
+
+	rows := [][]interface{}{
+		{"Compliance Score", fmt.Sprintf("%.1f%%", complianceScore(infos, statuses))},
+		{"Packages", len(infos)},
+		{},
+		{"License Risk", "Packages", "Compliant", "Weight"},
+	}
+	for _, risk := range riskOrder {
+		rows = append(rows, []interface{}{risk, packages[risk], compliant[risk], riskWeights[risk]})
+	}
+
+	if risky := riskyLicenses(infos); len(risky) > 0 {
+		rows = append(rows, []interface{}{}, []interface{}{"Top Risky Licenses", "Risk", "Packages"})
+		for _, license := range risky {
+			rows = append(rows, []interface{}{license.License, license.Risk, license.Packages})
+		}
+	}
+
+	ecosystems, ecosystemCounts := ecosystemTotals(infos)
+	rows = append(rows, []interface{}{}, []interface{}{"Ecosystem", "Packages"})
+	for _, ecosystem := range ecosystems {
+		rows = append(rows, []interface{}{ecosystemSheetName(ecosystem), ecosystemCounts[ecosystem]})
+	}
+
+	if projectLicense != "" {
+		counts := make(map[string]int)
+		for _, verdict := range verdicts {
+			counts[verdict.Verdict]++
+		}
+		rows = append(rows, []interface{}{}, []interface{}{"Compatibility with " + projectLicense, "Packages"})
+		for _, verdict := range compatibilityOrder {
+			rows = append(rows, []interface{}{verdict, counts[verdict]})
+		}
+	}
+
+	licenses, counts := licenseDistribution(infos)
+	rows = append(rows, []interface{}{}, []interface{}{"License", "Packages"})
+	licenseRow := len(rows) + 1
+	for _, license := range licenses {
+		rows = append(rows, []interface{}{license, counts[license]})
+	}
+
+	rows = append(rows, []interface{}{}, []interface{}{"Organization", "Packages", "Licenses"})
+	for _, group := range groupByOrganization(infos) {
+		rows = append(rows, []interface{}{group.Name, group.Packages, strings.Join(sortedKeys(group.Licenses), ", ")})
+	}
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(summarySheetName, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	if len(licenses) == 0 {
+		return nil
+	}
+	return addLicenseChart(f, licenseRow, licenseRow+len(licenses)-1)
+}
@@ -0,0 +1,11 @@
+package main
+
+// Get metadata for a Debian package from its dpkg status entry and copyright file
+func getDpkgMetadata(pkg *Package) PackageInfo {
+	return getDeclaredMetadata(pkg, "deb")
+}
+
+// Get metadata for an Alpine package from its apk database entry
+func getApkMetadata(pkg *Package) PackageInfo {
+	return getDeclaredMetadata(pkg, "apk")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// popularPackages are the most depended-upon packages per ecosystem, which are the
+// usual targets of typosquatting
+var popularPackages = map[string][]string{
+	"npm": {
+		"lodash", "react", "react-dom", "express", "axios", "chalk", "commander", "debug", "moment",
+		"request", "tslib", "typescript", "webpack", "babel-core", "@babel/core", "vue", "jquery",
+		"uuid", "async", "bluebird", "underscore", "classnames", "prop-types", "yargs", "glob",
+		"fs-extra", "mkdirp", "rimraf", "semver", "minimist", "colors", "dotenv", "body-parser",
+		"cross-env", "eslint", "prettier", "jest", "mocha", "chai", "rxjs", "redux", "react-redux",
+		"next", "socket.io", "mongoose", "mysql", "pg", "sqlite3", "nodemailer", "jsonwebtoken",
+		"bcrypt", "cors", "morgan", "ws", "inquirer", "ora", "node-fetch", "cheerio", "puppeteer",
+		"electron", "coffee-script", "core-js", "event-stream", "qs", "cookie-parser", "passport",
+	},
+	"pypi": {
+		"requests", "urllib3", "numpy", "pandas", "django", "flask", "boto3", "botocore", "six",
+		"setuptools", "pip", "wheel", "python-dateutil", "pyyaml", "certifi", "idna", "chardet",
+		"charset-normalizer", "cryptography", "jinja2", "markupsafe", "click", "colorama", "pytest",
+		"attrs", "pydantic", "sqlalchemy", "pillow", "scipy", "matplotlib", "scikit-learn",
+		"tensorflow", "torch", "beautifulsoup4", "lxml", "psycopg2", "pymongo", "redis", "celery",
+		"fastapi", "uvicorn", "aiohttp", "httpx", "paramiko", "jsonschema", "pyjwt", "openpyxl",
+		"selenium", "tqdm", "rich", "typing-extensions", "packaging", "protobuf", "grpcio",
+		"jellyfish", "dateutil", "tornado", "gunicorn", "werkzeug",
+	},
+	"go": {
+		"github.com/stretchr/testify", "github.com/sirupsen/logrus", "github.com/spf13/cobra",
+		"github.com/spf13/viper", "github.com/gin-gonic/gin", "github.com/gorilla/mux",
+		"github.com/google/uuid", "github.com/pkg/errors", "github.com/golang/protobuf",
+		"google.golang.org/grpc", "google.golang.org/protobuf", "github.com/go-sql-driver/mysql",
+		"github.com/lib/pq", "github.com/jackc/pgx", "go.uber.org/zap", "github.com/prometheus/client_golang",
+		"github.com/gorilla/websocket", "github.com/labstack/echo", "github.com/gofiber/fiber",
+		"github.com/urfave/cli", "github.com/BurntSushi/toml", "gopkg.in/yaml.v3", "gopkg.in/yaml.v2",
+		"github.com/redis/go-redis", "github.com/go-redis/redis", "github.com/aws/aws-sdk-go",
+		"golang.org/x/net", "golang.org/x/sys", "golang.org/x/crypto", "golang.org/x/text",
+		"github.com/mattn/go-sqlite3", "github.com/boltdb/bolt", "go.etcd.io/bbolt",
+	},
+}
+
+// knownSquats are package names that have been published as malware in the past
+var knownSquats = map[string][]string{
+	"npm": {
+		"crossenv", "cross-env.js", "d3.js", "fabric-js", "ffmepg", "gruntcli", "http-proxy.js",
+		"jquery.js", "mariadb", "mongose", "mssql-node", "mssql.js", "mysqljs", "node-fabric",
+		"node-opencv", "node-opensl", "node-openssl", "node-sqlite", "node-tkinter", "nodecaffe",
+		"nodefabric", "nodeffmpeg", "nodemailer-js", "nodemailer.js", "nodemssql", "noderequest",
+		"nodesass", "nodesqlite", "opencv.js", "openssl.js", "proxy.js", "shadowsock", "smb",
+		"sqlite.js", "sqliter", "sqlserver", "tkinter", "discord.dll", "electorn", "loadyaml",
+		"lodashs", "babelcli",
+	},
+	"pypi": {
+		"colourama", "python-sqlite", "urlib3", "urllib", "libpeshnx", "jeilyfish", "python3-dateutil",
+		"setup-tools", "bzip", "crypt", "django-server", "pwd", "smtp", "acqusition", "apidev-coop",
+		"easyinstall", "mybiubiubiu", "virtualnv", "diango", "djago", "dajngo", "reqeusts", "requesst",
+		"python-mysql", "python-openssl", "noblesse", "pytagora",
+	},
+}
+
+// normalizePackageName removes separators and case so that "python_dateutil" and
+// "python-dateutil" compare equal
+func normalizePackageName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(name))
+}
+
+// editDistance computes the optimal string alignment distance (Levenshtein distance
+// plus adjacent transpositions) between a and b
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
+
+// typosquatWarning returns a warning if name is a known malicious package or a close
+// lookalike of a popular package in its ecosystem, and "" otherwise
+func typosquatWarning(name string, repositoryType string) string {
+	lower := strings.ToLower(name)
+	for _, squat := range knownSquats[repositoryType] {
+		if lower == squat {
+			return "known malicious package name"
+		}
+	}
+
+	normalized := normalizePackageName(name)
+	for _, popular := range popularPackages[repositoryType] {
+		if strings.EqualFold(name, popular) {
+			return ""
+		}
+	}
+
+	for _, popular := range popularPackages[repositoryType] {
+		target := normalizePackageName(popular)
+		// Very short names produce too many false positives
+		if len(target) < 5 {
+			continue
+		}
+
+		// Same name except for separators, e.g. "python_dateutil" vs "python-dateutil" is
+		// legitimate on PyPI where names are normalized, but suspicious elsewhere
+		if normalized == target {
+			if repositoryType == "pypi" {
+				return ""
+			}
+			return "possible typosquat of " + popular
+		}
+
+		// Sibling modules of the same owner, e.g. golang.org/x/term and golang.org/x/text,
+		// are published by the same people and cannot be squats of each other
+		if repositoryType == "go" && strings.EqualFold(path.Dir(name), path.Dir(popular)) {
+			continue
+		}
+
+		allowed := 1
+		if len(target) >= 10 && repositoryType != "go" {
+			allowed = 2
+		}
+		if editDistance(normalized, target) <= allowed {
+			return "possible typosquat of " + popular
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ncruces/zenity"
+)
+
+// headless is set when running from the command line, e.g. in CI or over SSH: no
+// dialogs are shown, progress and messages go to stderr and failures exit non-zero
+var headless bool
+
+// stdinIsTerminal reports whether questions can be asked on the console
+func stdinIsTerminal() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// progressReporter is the part of zenity.ProgressDialog the tool uses, so progress can
+// also be reported on the console
+type progressReporter interface {
+	Text(text string) error
+	Value(value int) error
+	Complete() error
+	Close() error
+}
+
+// consoleProgress prints progress messages to stderr
+type consoleProgress struct {
+	value int
+}
+
+func (p *consoleProgress) Text(text string) error {
+	_, err := fmt.Fprintf(os.Stderr, "[%3d%%] %s\n", p.value, text)
+	return err
+}
+
+func (p *consoleProgress) Value(value int) error {
+	p.value = value
+	return nil
+}
+
+func (p *consoleProgress) Complete() error {
+	p.value = 100
+	return nil
+}
+
+func (p *consoleProgress) Close() error {
+	return nil
+}
+
+// newProgress opens a progress dialog, or a console reporter when headless. Pressing
+// Cancel on the dialog calls cancel; on the console Ctrl+C does the same.
+func newProgress(title string, cancel context.CancelFunc) (progressReporter, error) {
+	if headless {
+		return &consoleProgress{}, nil
+	}
+	dlg, err := zenity.Progress(zenity.Title(title))
+	if err != nil {
+		return nil, err
+	}
+	p := &dialogProgress{ProgressDialog: dlg, closed: make(chan struct{})}
+	go func() {
+		select {
+		case <-dlg.Done():
+			// The dialog also ends when the tool closes it
+			select {
+			case <-p.closed:
+			default:
+				cancel()
+			}
+		case <-p.closed:
+		}
+	}()
+	return p, nil
+}
+
+// dialogProgress is a progress dialog whose Cancel button aborts the run
+type dialogProgress struct {
+	zenity.ProgressDialog
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (p *dialogProgress) Close() error {
+	p.once.Do(func() { close(p.closed) })
+	return p.ProgressDialog.Close()
+}
+
+// exitCancelled ends a run the user cancelled, without the error dialog fatal shows;
+// note is appended to the console message
+func exitCancelled(note string) {
+	if headless {
+		fmt.Fprintln(os.Stderr, "cancelled"+note)
+	}
+	os.Exit(1)
+}
+
+// showError reports an error the run can continue after
+func showError(message string) {
+	if headless {
+		fmt.Fprintln(os.Stderr, "error: "+message)
+		return
+	}
+	zenity.Error(message, zenity.Title("Error"), zenity.ErrorIcon)
+}
+
+// fatal reports an error and exits with a non-zero status
+func fatal(message string) {
+	showError(message)
+	os.Exit(1)
+}
+
+// showInfo reports the successful end of a run
+func showInfo(title string, message string) {
+	if headless {
+		fmt.Fprintln(os.Stderr, message)
+		return
+	}
+	zenity.Info(message, zenity.Title(title), zenity.InfoIcon)
+}
+
+// showWarning reports a successful run whose results need attention
+func showWarning(title string, message string) {
+	if headless {
+		fmt.Fprintln(os.Stderr, "warning: "+message)
+		return
+	}
+	zenity.Warning(message, zenity.Title(title), zenity.WarningIcon)
+}
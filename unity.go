@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jsfaint/license_fetcher/pkg/parser"
+)
+
+// unityPackageJSON is the subset of a UPM package.json we report on
+type unityPackageJSON struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	License     string `json:"license"`
+	Author      any    `json:"author"`
+	Repository  any    `json:"repository"`
+}
+
+// unityGitPackageJSONURL builds a raw package.json URL for a GitHub hosted UPM package.
+// Git dependencies look like https://github.com/owner/repo.git?path=/Sub/Dir#v1.0.0
+func unityGitPackageJSONURL(gitURL string, revision string) (string, string) {
+	repo, _, _ := strings.Cut(gitURL, "#")
+	repo, query, _ := strings.Cut(repo, "?")
+	repo = strings.TrimPrefix(repo, "git+")
+	repo = strings.TrimSuffix(repo, ".git")
+	if strings.HasPrefix(repo, "git@github.com:") {
+		repo = "https://github.com/" + strings.TrimPrefix(repo, "git@github.com:")
+	}
+	if !strings.Contains(repo, "github.com/") {
+		return repo, ""
+	}
+
+	subPath := ""
+	if strings.HasPrefix(query, "path=") {
+		subPath = strings.Trim(strings.TrimPrefix(query, "path="), "/")
+	}
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	raw := strings.Replace(repo, "https://github.com/", "https://raw.githubusercontent.com/", 1) + "/" + revision
+	if subPath != "" {
+		raw += "/" + subPath
+	}
+	return repo, raw + "/package.json"
+}
+
+// Get metadata for a Unity package from its UPM registry or git repository
+func getUPMMetadata(ctx context.Context, pkg *Package) PackageInfo {
+	info := PackageInfo{
+		Name:            pkg.Path,
+		Version:         pkg.Version,
+		ModuleNameNoVer: pkg.Path,
+		RepositoryType:  "upm",
+	}
+
+	client := createHTTPClient()
+	var meta unityPackageJSON
+	found := false
+
+	if pkg.Registry != "" && !parser.IsUnityGitURL(pkg.Registry) {
+		// UPM registries speak the npm registry protocol
+		var doc struct {
+			Versions map[string]unityPackageJSON `json:"versions"`
+		}
+		if ok, err := getJSON(ctx, client, pkg.Registry+"/"+pkg.Path, &doc); err == nil && ok {
+			meta, found = doc.Versions[pkg.Version]
+		}
+		info.PackageURL = pkg.Registry + "/" + pkg.Path
+	} else if pkg.Registry != "" {
+		repo, raw := unityGitPackageJSONURL(pkg.Registry, pkg.Version)
+		info.Repository = repo
+		if strings.Contains(repo, "github.com") {
+			info.GitHubURL = repo
+		}
+		if raw != "" {
+			found, _ = getJSON(ctx, client, raw, &meta)
+		}
+	}
+
+	if meta.License != "" {
+		info.setLicenseSource("UPM package license field", declaredConfidence(meta.License))
+	}
+	// Unity's own packages are distributed under the Unity Companion License
+	if meta.License == "" && strings.HasPrefix(pkg.Path, "com.unity.") {
+		meta.License = "Unity Companion License"
+		info.setLicenseSource("com.unity package name", confidenceMedium)
+	}
+
+	if meta.License != "" {
+		info.License = meta.License
+		info.LicenseURL = licenseURL(meta.License)
+		if meta.License == "Unity Companion License" {
+			info.LicenseURL = "https://unity.com/legal/licenses/unity-companion-license"
+		}
+	}
+	info.Copyright = setCopyrightFromLicense(info.License)
+
+	if !found {
+		return info
+	}
+
+	switch author := meta.Author.(type) {
+	case string:
+		info.Author = author
+	case map[string]any:
+		if name, ok := author["name"].(string); ok {
+			info.Author = name
+		}
+	}
+
+	info.Description = meta.Description
+	if info.Description == "" {
+		info.Description = meta.DisplayName
+	}
+
+	switch repository := meta.Repository.(type) {
+	case string:
+		info.Repository = repository
+	case map[string]any:
+		if repoURL, ok := repository["url"].(string); ok {
+			info.Repository = repoURL
+		}
+	}
+	if info.GitHubURL == "" && strings.Contains(strings.ToLower(info.Repository), "github") {
+		info.GitHubURL = info.Repository
+	}
+
+	return info
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetUPMMetadataFromRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/com.example.tools" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"versions": {"1.2.0": {
+			"name": "com.example.tools",
+			"version": "1.2.0",
+			"description": "Editor tools",
+			"license": "MIT",
+			"author": {"name": "Example Studio"},
+			"repository": {"type": "git", "url": "https://github.com/example/tools"}
+		}}}`))
+	}))
+	defer server.Close()
+	defer takeFetchFailures()
+
+	pkg := Package{Path: "com.example.tools", Version: "1.2.0", Registry: server.URL}
+	info := getUPMMetadata(context.Background(), &pkg)
+	if info.License != "MIT" {
+		t.Errorf("License = %q, want MIT", info.License)
+	}
+	if info.Author != "Example Studio" {
+		t.Errorf("Author = %q, want Example Studio", info.Author)
+	}
+	if info.Description != "Editor tools" {
+		t.Errorf("Description = %q, want Editor tools", info.Description)
+	}
+	if info.Repository != "https://github.com/example/tools" {
+		t.Errorf("Repository = %q, want https://github.com/example/tools", info.Repository)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ncruces/zenity"
+	"github.com/xuri/excelize/v2"
+)
+
+// Verification outcomes for a report row
+const (
+	verifyUnchanged   = "unchanged"
+	verifyChanged     = "changed"
+	verifyUnconfirmed = "unconfirmed"
+)
+
+// verifyResult compares a reported row with freshly fetched metadata
+type verifyResult struct {
+	Reported PackageInfo
+	Current  PackageInfo
+	Status   string
+	Details  string
+}
+
+// verifyRow re-fetches metadata for a reported package and describes any differences
+func verifyRow(ctx context.Context, reported PackageInfo) verifyResult {
+	result := verifyResult{Reported: reported, Status: verifyUnconfirmed}
+
+	getMetadata := metadataGetter(reported.RepositoryType)
+	if getMetadata == nil {
+		result.Details = "no online source for repository type " + reported.RepositoryType
+		return result
+	}
+
+	pkg := Package{Path: reported.Name, Version: reported.Version, GoMod: reported.RepositoryType == "go"}
+	result.Current = getMetadata(ctx, &pkg)
+
+	if result.Current.License == "" {
+		result.Details = "license could not be confirmed"
+		return result
+	}
+
+	var changes []string
+	if !strings.EqualFold(result.Current.License, reported.License) {
+		changes = append(changes, fmt.Sprintf("license %q -> %q", reported.License, result.Current.License))
+	}
+	reportedRepo := primaryRepository(reported)
+	currentRepo := primaryRepository(result.Current)
+	if currentRepo == "" && reportedRepo != "" {
+		changes = append(changes, "repository could not be confirmed")
+	} else if reportedRepo != "" && normalizeRepositoryURL(reportedRepo) != normalizeRepositoryURL(currentRepo) {
+		changes = append(changes, fmt.Sprintf("repository %q -> %q", reportedRepo, currentRepo))
+	}
+
+	if len(changes) == 0 {
+		result.Status = verifyUnchanged
+	} else {
+		result.Status = verifyChanged
+		result.Details = strings.Join(changes, "; ")
+	}
+	return result
+}
+
+// runVerify implements the "verify" subcommand: re-check every row of an existing
+// report against current registry metadata and write the differences to a new workbook
+func runVerify(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.Parse(args)
+
+	// A report given on the command line runs without any dialog
+	inName := flags.Arg(0)
+	headless = inName != ""
+	if inName == "" {
+		wd, _ := os.Getwd()
+		selected, err := zenity.SelectFile(
+			zenity.Filename(wd),
+			zenity.Title("Select report to verify"),
+			zenity.FileFilters{{Name: "License Report", Patterns: []string{"*.xlsx"}, CaseFold: true}},
+		)
+		if err != nil {
+			// User cancelled
+			os.Exit(1)
+		}
+		inName = selected
+	}
+
+	sheet, err := readReport(inName)
+	if err != nil {
+		fatal("Failed to read report: " + err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	dlg, err := newProgress("Verifying...", cancel)
+	if err != nil {
+		fatal("Create progress dialog failed: " + err.Error())
+	}
+	defer dlg.Close()
+
+	f := excelize.NewFile()
+	sheetName := f.GetSheetName(0)
+
+	header := []string{"Name", "Version", "Repository Type", "Reported License", "Current License", "Reported Repository", "Current Repository", "Status", "Details"}
+	f.SetSheetRow(sheetName, "A1", &header)
+
+	counts := make(map[string]int)
+	total := len(sheet.Rows)
+	for i, row := range sheet.Rows {
+		dlg.Value(int(float64(i) / float64(total) * 100))
+		dlg.Text("Verifying " + row.Info.Name + "...")
+
+		result := verifyRow(ctx, row.Info)
+		if ctx.Err() != nil {
+			dlg.Close()
+			exitCancelled("")
+		}
+		counts[result.Status]++
+
+		values := []interface{}{
+			row.Info.Name,
+			row.Info.Version,
+			row.Info.RepositoryType,
+			row.Info.License,
+			result.Current.License,
+			primaryRepository(row.Info),
+			primaryRepository(result.Current),
+			result.Status,
+			result.Details,
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		f.SetSheetRow(sheetName, cell, &values)
+	}
+
+	outName := strings.TrimSuffix(filepath.Base(inName), filepath.Ext(inName)) + "_verify.xlsx"
+	outName, err = saveWorkbook(f, outName, false)
+	if err != nil {
+		fatal("Failed to save Excel file: " + err.Error())
+	}
+
+	dlg.Complete()
+	summary := fmt.Sprintf("Verification written to %s\n%d unchanged, %d changed, %d unconfirmed",
+		outName, counts[verifyUnchanged], counts[verifyChanged], counts[verifyUnconfirmed])
+	showInfo("Success", summary)
+}
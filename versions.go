@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// Values of PackageInfo.VersionStatus; empty means the version could not be checked
+const (
+	versionFound   = "found"
+	versionMissing = "missing on registry"
+)
+
+// exactVersion returns the version the metadata of a row describes: the version a
+// range resolved to, or else the version the manifest names
+func exactVersion(info PackageInfo) string {
+	if info.ResolvedVersion != "" {
+		return info.ResolvedVersion
+	}
+	return info.Version
+}
+
+// isPinnedVersion reports whether a version requirement names exactly one version,
+// so its absence from the registry is a real problem rather than a range mismatch
+func isPinnedVersion(version string) bool {
+	version = strings.TrimSpace(version)
+	version = strings.TrimPrefix(version, "==")
+	version = strings.TrimPrefix(version, "=")
+	if version == "" || version == "latest" {
+		return false
+	}
+	if strings.ContainsAny(version, "^~<>=|, ") {
+		return false
+	}
+	// x, X and * are wildcards only as a whole component, as in 1.x, 1.2.* or NuGet's
+	// 1.0.0-*; prerelease tags such as 1.0.0-next.3 merely contain the letter
+	for _, part := range strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '-' || r == '+' }) {
+		if part == "x" || part == "X" || part == "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkGoModuleVersion asks the Go module proxies whether a module version exists
+func checkGoModuleVersion(ctx context.Context, client *http.Client, modulePath string, version string) string {
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := goProxyGet(ctx, client, modulePath, "@v/"+escapedVersion+".info")
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case 200:
+		return versionFound
+	case 404, 410:
+		// The proxy answers 404/410 for versions it cannot find upstream
+		return versionMissing
+	}
+	return ""
+}
+
+// deprecationStatus sums up why a package needs replacing: the deprecation or yank
+// notice of its registry, and whether its GitHub repository is archived
+func deprecationStatus(info PackageInfo) string {
+	var reasons []string
+	if info.Deprecation != "" {
+		reasons = append(reasons, info.Deprecation)
+	}
+	if info.Archived {
+		reasons = append(reasons, "repository archived")
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestIsPinnedVersion(t *testing.T) {
+	tests := map[string]bool{
+		"1.2.3":                   true,
+		"==2.31.0":                true,
+		"v0.14.0":                 true,
+		"1.0.0-next.3":            true,
+		"0.0.0-experimental-abc":  true,
+		"2.0.0-canary.x1":         true,
+		"15.0.0-rc.0-exp.xyz":     true,
+		"1.0.0-beta+exp.sha.5114": true,
+		"":                        false,
+		"latest":                  false,
+		"^1.2.3":                  false,
+		"~1.2":                    false,
+		">=2.0":                   false,
+		"1.x":                     false,
+		"1.2.X":                   false,
+		"1.2.*":                   false,
+		"*":                       false,
+		"1.0.0-*":                 false,
+		"1.0 || 2.0":              false,
+		"[1.0,2.0)":               false,
+	}
+	for version, want := range tests {
+		if got := isPinnedVersion(version); got != want {
+			t.Errorf("isPinnedVersion(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
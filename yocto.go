@@ -0,0 +1,25 @@
+package main
+
+// Get metadata from the Yocto manifest itself, no network lookup is needed
+func getYoctoMetadata(pkg *Package) PackageInfo {
+	info := PackageInfo{
+		Name:            pkg.Path,
+		Version:         pkg.Version,
+		ModuleNameNoVer: pkg.Path,
+		RepositoryType:  "yocto",
+	}
+
+	if pkg.License != "" {
+		info.License = pkg.License
+		info.LicenseURL = licenseURL(info.License)
+		info.setLicenseSource("Yocto license manifest", declaredConfidence(pkg.License))
+	}
+
+	if pkg.Recipe != "" && pkg.Recipe != pkg.Path {
+		info.Description = "Built from recipe " + pkg.Recipe
+	}
+
+	info.Copyright = setCopyrightFromLicense(info.License)
+
+	return info
+}